@@ -0,0 +1,45 @@
+package pipes
+
+import (
+	"context"
+	"errors"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+)
+
+// Action tables create, change or delete resources in Pipes. Each is a list
+// table whose inputs are required key columns and whose rows are the objects
+// returned by the mutation, e.g.
+//
+//	select id, state from pipes_action_workspace_create where identity_handle = 'acme' and handle = 'dev'
+//
+// They only run for connections that set enable_write_actions = true, and are
+// never served from the query cache, so every scan performs the mutation.
+
+// errWriteActionsDisabled is returned by action tables for connections that
+// have not opted in to write actions.
+var errWriteActionsDisabled = errors.New("write actions are disabled for this connection: set enable_write_actions = true in the connection config to enable them")
+
+// actionTableCache disables the query cache for action tables, so that a
+// repeated query performs the mutation again rather than returning the
+// earlier result.
+var actionTableCache = &plugin.TableCacheOptions{Enabled: false}
+
+// actionIgnoreConfig overrides the plugin's default ignore config, so that a
+// failed mutation is always reported instead of returning no rows, e.g. when
+// deleting a snapshot that does not exist.
+var actionIgnoreConfig = &plugin.IgnoreConfig{
+	ShouldIgnoreErrorFunc: func(context.Context, *plugin.QueryData, *plugin.HydrateData, error) bool {
+		return false
+	},
+}
+
+// checkWriteActionsEnabled returns an error unless the connection has opted in
+// to write actions.
+func checkWriteActionsEnabled(d *plugin.QueryData) error {
+	config := GetConfig(d.Connection)
+	if config.EnableWriteActions == nil || !*config.EnableWriteActions {
+		return errWriteActionsDisabled
+	}
+	return nil
+}
@@ -0,0 +1,195 @@
+package pipes
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+)
+
+const enableWriteActions = "enable_write_actions = true\n"
+
+func TestWriteActionsDisabled(t *testing.T) {
+	api := newFakePipesAPI(t, "pipes_api.json")
+	p := newTestPlugin(t, api)
+
+	_, err := p.Query(testQuery{
+		Table:   "pipes_action_workspace_create",
+		Columns: []string{"id"},
+		Quals: []*proto.Qual{
+			qual("identity_handle", "=", stringQualValue("acme")),
+			qual("handle", "=", stringQualValue("staging")),
+		},
+	})
+	if err == nil || !strings.Contains(err.Error(), "enable_write_actions = true") {
+		t.Fatalf("err = %v, want write actions disabled error", err)
+	}
+	if got := len(api.Requests("POST /org/acme/workspace")); got != 0 {
+		t.Errorf("create requests = %d, want 0", got)
+	}
+}
+
+func TestActionWorkspaceCreate(t *testing.T) {
+	api := newFakePipesAPI(t, "pipes_api.json")
+	p := newTestPluginWithConfig(t, api, enableWriteActions)
+
+	rows := p.MustQuery(testQuery{
+		Table:   "pipes_action_workspace_create",
+		Columns: []string{"id", "handle", "identity_handle", "instance_type", "state"},
+		Quals: []*proto.Qual{
+			qual("identity_handle", "=", stringQualValue("acme")),
+			qual("handle", "=", stringQualValue("staging")),
+			qual("instance_type", "=", stringQualValue("db1.small")),
+		},
+	})
+
+	if got := rowStrings(rows, "id"); !reflect.DeepEqual(got, []string{"w_staging"}) {
+		t.Fatalf("ids = %v, want [w_staging]", got)
+	}
+	if got := rows[0].Columns["identity_handle"].GetStringValue(); got != "acme" {
+		t.Errorf("identity_handle = %q, want acme", got)
+	}
+
+	requests := api.Requests("POST /org/acme/workspace")
+	if len(requests) != 1 {
+		t.Fatalf("create requests = %d, want 1", len(requests))
+	}
+	var body map[string]interface{}
+	if err := json.Unmarshal(requests[0].Body, &body); err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]interface{}{"handle": "staging", "instance_type": "db1.small"}; !reflect.DeepEqual(body, want) {
+		t.Errorf("request body = %v, want %v", body, want)
+	}
+}
+
+func TestActionPipelineRun(t *testing.T) {
+	api := newFakePipesAPI(t, "pipes_api.json")
+	p := newTestPluginWithConfig(t, api, enableWriteActions)
+
+	rows := p.MustQuery(testQuery{
+		Table:   "pipes_action_pipeline_run",
+		Columns: []string{"pipeline_id", "workspace_handle", "args", "process_id", "workspace_id", "state"},
+		Quals: []*proto.Qual{
+			qual("identity_handle", "=", stringQualValue("alice")),
+			qual("workspace_handle", "=", stringQualValue("dev")),
+			qual("pipeline_id", "=", stringQualValue("p_dev_1")),
+			qual("args", "=", &proto.QualValue{Value: &proto.QualValue_JsonbValue{JsonbValue: `{"region": "us-east-1"}`}}),
+		},
+	})
+
+	if len(rows) != 1 {
+		t.Fatalf("rows = %d, want 1", len(rows))
+	}
+	row := rows[0].Columns
+	if got := row["process_id"].GetStringValue() + "/" + row["state"].GetStringValue(); got != "p_proc_2/running" {
+		t.Errorf("process_id/state = %s, want p_proc_2/running", got)
+	}
+	if got := row["pipeline_id"].GetStringValue() + "/" + row["workspace_handle"].GetStringValue() + "/" + row["workspace_id"].GetStringValue(); got != "p_dev_1/dev/w_dev" {
+		t.Errorf("pipeline_id/workspace_handle/workspace_id = %s, want p_dev_1/dev/w_dev", got)
+	}
+
+	requests := api.Requests("POST /user/alice/workspace/dev/pipeline/p_dev_1/command")
+	if len(requests) != 1 {
+		t.Fatalf("command requests = %d, want 1", len(requests))
+	}
+	var body map[string]interface{}
+	if err := json.Unmarshal(requests[0].Body, &body); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{"command": "run", "args": map[string]interface{}{"region": "us-east-1"}}
+	if !reflect.DeepEqual(body, want) {
+		t.Errorf("request body = %v, want %v", body, want)
+	}
+	// without wait the process is returned as soon as it has been created
+	if got := len(api.Requests("GET /user/alice/workspace/dev/process/p_proc_2")); got != 1 {
+		t.Errorf("process requests = %d, want 1", got)
+	}
+}
+
+func TestActionPipelineRunWait(t *testing.T) {
+	interval := pipelineRunPollInterval
+	pipelineRunPollInterval = 100 * time.Millisecond
+	t.Cleanup(func() { pipelineRunPollInterval = interval })
+
+	query := testQuery{
+		Table:   "pipes_action_pipeline_run",
+		Columns: []string{"process_id", "state"},
+		Quals: []*proto.Qual{
+			qual("identity_handle", "=", stringQualValue("alice")),
+			qual("workspace_handle", "=", stringQualValue("dev")),
+			qual("pipeline_id", "=", stringQualValue("p_dev_1")),
+			qual("wait", "=", &proto.QualValue{Value: &proto.QualValue_BoolValue{BoolValue: true}}),
+			qual("wait_timeout", "=", &proto.QualValue{Value: &proto.QualValue_Int64Value{Int64Value: 1}}),
+		},
+	}
+
+	t.Run("finished", func(t *testing.T) {
+		api := newFakePipesAPI(t, "pipes_api.json", "pipeline_run_completed.json")
+		p := newTestPluginWithConfig(t, api, enableWriteActions)
+
+		rows := p.MustQuery(query)
+		if got := rowStrings(rows, "state"); !reflect.DeepEqual(got, []string{"completed"}) {
+			t.Errorf("state = %v, want [completed]", got)
+		}
+		if got := len(api.Requests("GET /user/alice/workspace/dev/process/p_proc_2")); got != 1 {
+			t.Errorf("process requests = %d, want 1", got)
+		}
+	})
+
+	t.Run("timed out", func(t *testing.T) {
+		api := newFakePipesAPI(t, "pipes_api.json")
+		p := newTestPluginWithConfig(t, api, enableWriteActions)
+
+		start := time.Now()
+		rows := p.MustQuery(query)
+		if got := rowStrings(rows, "state"); !reflect.DeepEqual(got, []string{"running"}) {
+			t.Errorf("state = %v, want [running]", got)
+		}
+		if elapsed := time.Since(start); elapsed > 5*time.Second {
+			t.Errorf("query took %s, want about 1s", elapsed)
+		}
+		// the process is polled until the timeout, but the pipeline only run once
+		if got := len(api.Requests("GET /user/alice/workspace/dev/process/p_proc_2")); got < 2 {
+			t.Errorf("process requests = %d, want at least 2", got)
+		}
+		if got := len(api.Requests("POST /user/alice/workspace/dev/pipeline/p_dev_1/command")); got != 1 {
+			t.Errorf("command requests = %d, want 1", got)
+		}
+	})
+}
+
+func TestActionSnapshotDelete(t *testing.T) {
+	api := newFakePipesAPI(t, "pipes_api.json")
+	p := newTestPluginWithConfig(t, api, enableWriteActions)
+
+	query := testQuery{
+		Table:   "pipes_action_snapshot_delete",
+		Columns: []string{"id", "workspace_id", "workspace_handle"},
+		Quals: []*proto.Qual{
+			qual("identity_handle", "=", stringQualValue("alice")),
+			qual("workspace_handle", "=", stringQualValue("dev")),
+			qual("id", "=", stringQualValue("snap_dev_1")),
+		},
+	}
+	rows := p.MustQuery(query)
+
+	if got := rowStrings(rows, "workspace_id"); !reflect.DeepEqual(got, []string{"w_dev"}) {
+		t.Fatalf("workspace_id = %v, want [w_dev]", got)
+	}
+	if got := len(api.Requests("DELETE /user/alice/workspace/dev/snapshot/snap_dev_1")); got != 1 {
+		t.Errorf("delete requests = %d, want 1", got)
+	}
+
+	// the API rejects deleting the snapshot again, and the error is not retried
+	api.Fail("DELETE /user/alice/workspace/dev/snapshot/snap_dev_1", 404)
+	if _, err := p.Query(query); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("err = %v, want 404 error", err)
+	}
+	if got := len(api.Requests("DELETE /user/alice/workspace/dev/snapshot/snap_dev_1")); got != 2 {
+		t.Errorf("delete requests = %d, want 2", got)
+	}
+}
@@ -0,0 +1,109 @@
+package pipes
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+)
+
+func TestAuditLogAllIdentities(t *testing.T) {
+	api := newFakePipesAPI(t, "pipes_api.json")
+	p := newTestPlugin(t, api)
+
+	rows := p.MustQuery(testQuery{
+		Table:   "pipes_audit_log",
+		Columns: []string{"id", "identity_id"},
+	})
+
+	// the audit logs of the connection user and each of their orgs
+	want := []string{"a_1", "a_2", "a_3"}
+	if got := rowStrings(rows, "id"); !reflect.DeepEqual(got, want) {
+		t.Errorf("ids = %v, want %v", got, want)
+	}
+	if got := rowStrings(rows, "identity_id"); !reflect.DeepEqual(got, []string{"o_acme", "u_alice", "u_alice"}) {
+		t.Errorf("identity_ids = %v, want [o_acme u_alice u_alice]", got)
+	}
+}
+
+func TestAuditLogAllIdentitiesForbiddenOrg(t *testing.T) {
+	api := newFakePipesAPI(t, "pipes_api.json")
+	api.Fail("GET /org/acme/audit_log", http.StatusForbidden)
+	p := newTestPlugin(t, api)
+
+	rows, err := p.Query(testQuery{
+		Table:   "pipes_audit_log",
+		Columns: []string{"id"},
+	})
+	if err != nil {
+		t.Fatalf("error = %v, want nil", err)
+	}
+
+	// the org the connection user can't read is skipped
+	want := []string{"a_1", "a_2"}
+	if got := rowStrings(rows, "id"); !reflect.DeepEqual(got, want) {
+		t.Errorf("ids = %v, want %v", got, want)
+	}
+}
+
+func TestAuditLogForbiddenOrgQual(t *testing.T) {
+	api := newFakePipesAPI(t, "pipes_api.json")
+	api.Fail("GET /org/acme/audit_log", http.StatusForbidden)
+	p := newTestPlugin(t, api)
+
+	_, err := p.Query(testQuery{
+		Table:   "pipes_audit_log",
+		Columns: []string{"id"},
+		Quals:   []*proto.Qual{qual("identity_handle", "=", stringQualValue("acme"))},
+	})
+	if err == nil {
+		t.Fatal("error = nil, want forbidden error")
+	}
+}
+
+func TestAuditLogAllIdentitiesUserError(t *testing.T) {
+	api := newFakePipesAPI(t, "pipes_api.json")
+	api.Fail("GET /user/alice/audit_log", http.StatusForbidden)
+	p := newTestPlugin(t, api)
+
+	// only errors for orgs are skipped
+	if _, err := p.Query(testQuery{Table: "pipes_audit_log", Columns: []string{"id"}}); err == nil {
+		t.Fatal("error = nil, want forbidden error")
+	}
+}
+
+func TestTenantAuditLog(t *testing.T) {
+	api := newFakePipesAPI(t, "pipes_api.json")
+	p := newTestPlugin(t, api)
+
+	createdAt := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	rows := p.MustQuery(testQuery{
+		Table:   "pipes_tenant_audit_log",
+		Columns: []string{"id", "action_type", "tenant_id", "target_handle"},
+		Quals: []*proto.Qual{
+			qual("created_at", ">=", timestampQualValue(createdAt)),
+			qual("action_type", "~~", stringQualValue("tenant.%")),
+		},
+	})
+
+	want := []string{"ta_1", "ta_2"}
+	if got := rowStrings(rows, "id"); !reflect.DeepEqual(got, want) {
+		t.Errorf("ids = %v, want %v", got, want)
+	}
+	if got := rowStrings(rows, "tenant_id"); !reflect.DeepEqual(got, []string{"t_pipes", "t_pipes"}) {
+		t.Errorf("tenant_ids = %v, want [t_pipes t_pipes]", got)
+	}
+
+	// the filter is sent with every page, and the fake serves one audit log
+	// record per page
+	var got []string
+	for _, r := range api.Requests("GET /audit_log") {
+		got = append(got, r.Query.Get("where"))
+	}
+	filter := `created_at >= '2024-02-01 00:00:00.00000' and action_type like 'tenant.%'`
+	if want := []string{filter, filter}; !reflect.DeepEqual(got, want) {
+		t.Errorf("where = %q, want %q", got, want)
+	}
+}
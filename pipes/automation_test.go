@@ -0,0 +1,230 @@
+package pipes
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+)
+
+func TestIntegration(t *testing.T) {
+	api := newFakePipesAPI(t, "pipes_api.json")
+	p := newTestPlugin(t, api)
+
+	rows := p.MustQuery(testQuery{
+		Table:   "pipes_integration",
+		Columns: []string{"handle", "identity_handle", "state", "pipeline_id"},
+	})
+
+	// the integrations of the connection user and each of their orgs
+	if got := rowStrings(rows, "handle"); !reflect.DeepEqual(got, []string{"github", "slack"}) {
+		t.Fatalf("handles = %v, want [github slack]", got)
+	}
+	if got := rowStrings(rows, "identity_handle"); !reflect.DeepEqual(got, []string{"acme", "alice"}) {
+		t.Errorf("identity_handle = %v, want [acme alice]", got)
+	}
+	if got := rowStrings(rows, "pipeline_id"); !reflect.DeepEqual(got, []string{"", "p_prod_1"}) {
+		t.Errorf("pipeline_id = %v, want [ p_prod_1]", got)
+	}
+}
+
+func TestIntegrationForIdentity(t *testing.T) {
+	api := newFakePipesAPI(t, "pipes_api.json")
+	p := newTestPlugin(t, api)
+
+	rows := p.MustQuery(testQuery{
+		Table:   "pipes_integration",
+		Columns: []string{"handle"},
+		Quals:   []*proto.Qual{qual("identity_handle", "=", stringQualValue("acme"))},
+	})
+
+	if got := rowStrings(rows, "handle"); !reflect.DeepEqual(got, []string{"github"}) {
+		t.Errorf("handles = %v, want [github]", got)
+	}
+	for _, route := range []string{"GET /actor/org", "GET /user/alice/integration"} {
+		if got := len(api.Requests(route)); got != 0 {
+			t.Errorf("%s requests = %d, want 0", route, got)
+		}
+	}
+}
+
+func TestWorkspaceIntegration(t *testing.T) {
+	api := newFakePipesAPI(t, "pipes_api.json")
+	p := newTestPlugin(t, api)
+
+	rows := p.MustQuery(testQuery{
+		Table:   "pipes_workspace_integration",
+		Columns: []string{"id", "handle", "workspace_id", "workspace_handle", "identity_handle"},
+	})
+
+	if got := rowStrings(rows, "handle"); !reflect.DeepEqual(got, []string{"github", "slack"}) {
+		t.Fatalf("handles = %v, want [github slack]", got)
+	}
+	for _, row := range rows {
+		want := map[string]string{"i_github": "w_prod/prod/acme", "i_slack": "w_dev/dev/alice"}[row.Columns["id"].GetStringValue()]
+		got := row.Columns["workspace_id"].GetStringValue() + "/" + row.Columns["workspace_handle"].GetStringValue() + "/" + row.Columns["identity_handle"].GetStringValue()
+		if got != want {
+			t.Errorf("%s workspace_id/workspace_handle/identity_handle = %s, want %s", row.Columns["handle"].GetStringValue(), got, want)
+		}
+	}
+}
+
+func TestWorkspaceFlowpipeTrigger(t *testing.T) {
+	api := newFakePipesAPI(t, "pipes_api.json")
+	p := newTestPlugin(t, api)
+
+	rows := p.MustQuery(testQuery{
+		Table:   "pipes_workspace_flowpipe_trigger",
+		Columns: []string{"name", "state", "state_reason", "workspace_handle", "last_run_state", "last_run_at", "next_run_at"},
+		Quals:   []*proto.Qual{qual("workspace_id", "=", stringQualValue("w_dev"))},
+	})
+
+	want := []string{"aws_compliance.trigger.http.webhook", "aws_compliance.trigger.schedule.daily_cis"}
+	if got := rowStrings(rows, "name"); !reflect.DeepEqual(got, want) {
+		t.Fatalf("names = %v, want %v", got, want)
+	}
+	for _, row := range rows {
+		if got := row.Columns["workspace_handle"].GetStringValue(); got != "dev" {
+			t.Errorf("workspace_handle = %q, want dev", got)
+		}
+		switch row.Columns["name"].GetStringValue() {
+		case "aws_compliance.trigger.schedule.daily_cis":
+			if got := row.Columns["last_run_state"].GetStringValue(); got != "completed" {
+				t.Errorf("last_run_state = %q, want completed", got)
+			}
+			if got := row.Columns["last_run_at"].GetTimestampValue().AsTime().Format("2006-01-02"); got != "2024-02-03" {
+				t.Errorf("last_run_at = %s, want 2024-02-03", got)
+			}
+			if got := row.Columns["next_run_at"].GetTimestampValue().AsTime().Format("2006-01-02"); got != "2024-02-04" {
+				t.Errorf("next_run_at = %s, want 2024-02-04", got)
+			}
+		default:
+			if got := row.Columns["state"].GetStringValue(); got != "disabled" {
+				t.Errorf("state = %q, want disabled", got)
+			}
+			if got := row.Columns["state_reason"].GetStringValue(); got != "Disabled by alice." {
+				t.Errorf("state_reason = %q", got)
+			}
+		}
+	}
+}
+
+func TestWorkspaceFlowpipeTriggerListColumns(t *testing.T) {
+	api := newFakePipesAPI(t, "pipes_api.json")
+	p := newTestPlugin(t, api)
+
+	rows := p.MustQuery(testQuery{
+		Table:   "pipes_workspace_flowpipe_trigger",
+		Columns: []string{"name", "state", "schedule", "pipelines"},
+		Quals:   []*proto.Qual{qual("workspace_id", "=", stringQualValue("w_dev"))},
+	})
+
+	if len(rows) != 2 {
+		t.Fatalf("rows = %d, want 2", len(rows))
+	}
+	// columns returned by the list API need no get call per trigger
+	if got := len(api.Requests("GET /user/alice/workspace/w_dev/flowpipe/trigger/aws_compliance.trigger.schedule.daily_cis")); got != 0 {
+		t.Errorf("trigger get requests = %d, want 0", got)
+	}
+	for _, row := range rows {
+		if row.Columns["name"].GetStringValue() != "aws_compliance.trigger.schedule.daily_cis" {
+			continue
+		}
+		if got := row.Columns["schedule"].GetStringValue(); got != "daily" {
+			t.Errorf("schedule = %q, want daily", got)
+		}
+	}
+}
+
+func TestWorkspaceFlowpipeTriggerForWorkspace(t *testing.T) {
+	api := newFakePipesAPI(t, "pipes_api.json")
+	p := newTestPlugin(t, api)
+
+	rows := p.MustQuery(testQuery{
+		Table:   "pipes_workspace_flowpipe_trigger",
+		Columns: []string{"name"},
+		Quals: []*proto.Qual{
+			qual("identity_handle", "=", stringQualValue("alice")),
+			qual("workspace_handle", "=", stringQualValue("dev")),
+		},
+	})
+
+	if len(rows) != 2 {
+		t.Fatalf("rows = %d, want 2", len(rows))
+	}
+	// the workspace is fetched rather than listing every workspace
+	if got := len(api.Requests("GET /user/alice/workspace")); got != 0 {
+		t.Errorf("workspace list requests = %d, want 0", got)
+	}
+	if got := len(api.Requests("GET /user/alice/workspace/dev")); got != 1 {
+		t.Errorf("workspace get requests = %d, want 1", got)
+	}
+}
+
+func TestWorkspaceFlowpipeTriggerForWorkspaceOnly(t *testing.T) {
+	api := newFakePipesAPI(t, "pipes_api.json")
+	p := newTestPlugin(t, api)
+
+	rows := p.MustQuery(testQuery{
+		Table:   "pipes_workspace_flowpipe_trigger",
+		Columns: []string{"name", "workspace_handle"},
+		Quals:   []*proto.Qual{qual("workspace_handle", "=", stringQualValue("dev"))},
+	})
+
+	if got := rowStrings(rows, "workspace_handle"); !reflect.DeepEqual(got, []string{"dev", "dev"}) {
+		t.Fatalf("workspace_handle = %v, want [dev dev]", got)
+	}
+	// the connection user's workspaces are listed once, filtered to the
+	// workspace, rather than listing the workspaces of every identity
+	requests := api.Requests("GET /actor/workspace")
+	if len(requests) != 1 {
+		t.Fatalf("workspace list requests = %d, want 1", len(requests))
+	}
+	if got := requests[0].Query.Get("where"); got != "handle = 'dev'" {
+		t.Errorf("where = %q, want handle = 'dev'", got)
+	}
+	for _, route := range []string{"GET /user/alice/workspace", "GET /org/acme/workspace", "GET /org/acme/workspace/w_prod/trigger"} {
+		if got := len(api.Requests(route)); got != 0 {
+			t.Errorf("%s requests = %d, want 0", route, got)
+		}
+	}
+}
+
+func TestNotifier(t *testing.T) {
+	api := newFakePipesAPI(t, "pipes_api.json")
+	p := newTestPlugin(t, api)
+
+	rows := p.MustQuery(testQuery{
+		Table:   "pipes_notifier",
+		Columns: []string{"name", "precedence", "identity_handle"},
+	})
+
+	if got := rowStrings(rows, "name"); !reflect.DeepEqual(got, []string{"default"}) {
+		t.Fatalf("names = %v, want [default]", got)
+	}
+	if got := rows[0].Columns["precedence"].GetStringValue(); got != "default" {
+		t.Errorf("precedence = %q, want default", got)
+	}
+	if got := len(api.Requests("GET /org/acme/notifier")); got != 1 {
+		t.Errorf("org notifier list requests = %d, want 1", got)
+	}
+}
+
+func TestWorkspaceNotifier(t *testing.T) {
+	api := newFakePipesAPI(t, "pipes_api.json")
+	p := newTestPlugin(t, api)
+
+	rows := p.MustQuery(testQuery{
+		Table:   "pipes_workspace_notifier",
+		Columns: []string{"name", "workspace_id", "workspace_handle"},
+		Quals:   []*proto.Qual{qual("workspace_handle", "=", stringQualValue("dev"))},
+	})
+
+	if got := rowStrings(rows, "name"); !reflect.DeepEqual(got, []string{"default", "dev_alerts"}) {
+		t.Fatalf("names = %v, want [default dev_alerts]", got)
+	}
+	// notifiers inherited from the identity are attributed to the workspace
+	if got := rowStrings(rows, "workspace_id"); !reflect.DeepEqual(got, []string{"w_dev", "w_dev"}) {
+		t.Errorf("workspace_id = %v, want [w_dev w_dev]", got)
+	}
+}
@@ -2,18 +2,32 @@ package pipes
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"errors"
 	"fmt"
+	"net"
+	"net/http"
 	"net/url"
 	"os"
+	"time"
 
 	openapiclient "github.com/turbot/pipes-sdk-go"
 	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/rate_limiter"
 )
 
 type pipesConfig struct {
-	Token *string `hcl:"token"`
-	Host  *string `hcl:"host"`
+	Token              *string `hcl:"token"`
+	TokenFile          *string `hcl:"token_file"`
+	Host               *string `hcl:"host"`
+	CACertFile         *string `hcl:"ca_cert_file"`
+	MaxRetries         *int    `hcl:"max_retries"`
+	MinRetryDelay      *int    `hcl:"min_retry_delay"`
+	MaxRetryDelay      *int    `hcl:"max_retry_delay"`
+	RequestsPerSecond  *int    `hcl:"requests_per_second"`
+	EnableWriteActions *bool   `hcl:"enable_write_actions"`
+	SnapshotExportDir  *string `hcl:"snapshot_export_dir"`
 }
 
 func ConfigInstance() interface{} {
@@ -29,75 +43,200 @@ func GetConfig(connection *plugin.Connection) pipesConfig {
 	return config
 }
 
-func connect(_ context.Context, d *plugin.QueryData) (*openapiclient.APIClient, error) {
+// apiClientCacheKey is the connection cache key for the connection's API
+// client. The plugin SDK clears the connection cache whenever the connection
+// config changes, so the client is rebuilt with the new config.
+const apiClientCacheKey = "PipesAPIClient"
+
+// httpClient is shared by the API clients of all connections, so that they
+// reuse a single pool of keep-alive connections to the Pipes API. Each API
+// client wraps its transport with the connection's retry policy. Requests are
+// bounded by the query context rather than a client timeout, as snapshot
+// downloads can legitimately take a while.
+var httpClient = &http.Client{
+	Transport: &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   10 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          100,
+		MaxIdleConnsPerHost:   50,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ResponseHeaderTimeout: 60 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	},
+}
+
+// connect returns the API client for the connection, creating it on first use.
+func connect(ctx context.Context, d *plugin.QueryData) (*openapiclient.APIClient, error) {
 	config := GetConfig(d.Connection)
+	modTime := tokenFileModTime(config)
 
-	token := os.Getenv("STEAMPIPE_CLOUD_TOKEN")
-	// If `STEAMPIPE_CLOUD_TOKEN` is not set - we try to get the token from `PIPES_TOKEN`
-	if token == "" {
-		token = os.Getenv("PIPES_TOKEN")
+	// Load the client from the connection cache if it has already been created,
+	// unless token_file has changed since, e.g. the token was rotated
+	if cachedData, ok := d.ConnectionCache.Get(ctx, apiClientCacheKey); ok {
+		cached := cachedData.(*cachedAPIClient)
+		if cached.tokenFileModTime.Equal(modTime) {
+			return cached.client, nil
+		}
 	}
-	// token value present in the config takes precedence over environment variable
-	if config.Token != nil {
-		token = *config.Token
+
+	apiClient, err := newAPIClient(config)
+	if err != nil {
+		return nil, err
 	}
-	if token == "" {
-		return nil, errors.New("'token' must be set in the connection configuration. Edit your connection configuration file and then restart Steampipe")
+
+	cached := &cachedAPIClient{client: apiClient, tokenFileModTime: modTime}
+	if err := d.ConnectionCache.Set(ctx, apiClientCacheKey, cached); err != nil {
+		plugin.Logger(ctx).Warn("connect", "cache_set_error", err)
 	}
 
-	configuration := openapiclient.NewConfiguration()
-	configuration.AddDefaultHeader("Authorization", fmt.Sprintf("Bearer %s", token))
+	return apiClient, nil
+}
 
-	host := os.Getenv("STEAMPIPE_CLOUD_HOST")
-	// If `STEAMPIPE_CLOUD_HOST` is not set - we try to get the token from `PIPES_HOST`
-	if host == "" {
-		host = os.Getenv("PIPES_HOST")
+// cachedAPIClient is the connection's API client as held in the connection
+// cache, along with the modification time of token_file when it was created.
+type cachedAPIClient struct {
+	client           *openapiclient.APIClient
+	tokenFileModTime time.Time
+}
+
+// newAPIClient creates an API client from the connection config, falling back
+// to the environment for any values that are not set.
+func newAPIClient(config pipesConfig) (*openapiclient.APIClient, error) {
+	policy, err := newRetryPolicy(config)
+	if err != nil {
+		return nil, err
 	}
-	// host value present in the config takes precedence over environment variable
-	if config.Host != nil {
-		host = *config.Host
+
+	identityLimiter, err := newIdentityRateLimiter(config)
+	if err != nil {
+		return nil, err
 	}
 
-	// If there is no host in the config, we set default host to `pipes.turbot.com`
-	if host != "" {
-		parsedURL, parseErr := url.Parse(host)
-		if parseErr != nil {
-			return nil, fmt.Errorf(`invalid host: %v`, parseErr)
-		}
-		if parsedURL.Host == "" {
-			return nil, errors.New(`missing protocol or host`)
+	transport, err := newBaseTransport(config)
+	if err != nil {
+		return nil, err
+	}
+
+	// Retried requests wait for the identity rate limiter again
+	if identityLimiter != nil {
+		transport = &rateLimitTransport{base: transport, def: identityLimiter, limiters: rate_limiter.NewLimiterMap()}
+	}
+
+	configuration := openapiclient.NewConfiguration()
+	configuration.HTTPClient = &http.Client{
+		Transport: &queryFilterTransport{
+			base: &retryTransport{base: transport, policy: policy},
+		},
+	}
+
+	hostname, err := resolveHostname(config)
+	if err != nil {
+		return nil, err
+	}
+
+	if hostname != defaultHostname && hostname != "cloud.steampipe.io" {
+		// Parse and frame the Primary Servers
+		var primaryServers []openapiclient.ServerConfiguration
+		for _, server := range configuration.Servers {
+			serverURL, parseErr := url.Parse(server.URL)
+			if parseErr != nil {
+				return nil, fmt.Errorf(`invalid host: %v`, parseErr)
+			}
+			primaryServers = append(primaryServers, openapiclient.ServerConfiguration{URL: fmt.Sprintf("%s://%s%s", serverURL.Scheme, hostname, serverURL.Path), Description: server.Description})
 		}
+		configuration.Servers = primaryServers
 
-		if parsedURL.Host != "cloud.steampipe.io" && parsedURL.Host != "pipes.turbot.com" {
-			// Parse and frame the Primary Servers
-			var primaryServers []openapiclient.ServerConfiguration
-			for _, server := range configuration.Servers {
+		// Parse and frame the Operation Servers
+		operationServers := make(map[string]openapiclient.ServerConfigurations)
+		for service, servers := range configuration.OperationServers {
+			var serviceServers []openapiclient.ServerConfiguration
+			for _, server := range servers {
 				serverURL, parseErr := url.Parse(server.URL)
 				if parseErr != nil {
 					return nil, fmt.Errorf(`invalid host: %v`, parseErr)
 				}
-				primaryServers = append(primaryServers, openapiclient.ServerConfiguration{URL: fmt.Sprintf("%s://%s%s", serverURL.Scheme, parsedURL.Host, serverURL.Path), Description: server.Description})
+				serviceServers = append(serviceServers, openapiclient.ServerConfiguration{URL: fmt.Sprintf("%s://%s%s", serverURL.Scheme, hostname, serverURL.Path), Description: server.Description})
 			}
-			configuration.Servers = primaryServers
-
-			// Parse and frame the Operation Servers
-			operationServers := make(map[string]openapiclient.ServerConfigurations)
-			for service, servers := range configuration.OperationServers {
-				var serviceServers []openapiclient.ServerConfiguration
-				for _, server := range servers {
-					serverURL, parseErr := url.Parse(server.URL)
-					if parseErr != nil {
-						return nil, fmt.Errorf(`invalid host: %v`, parseErr)
-					}
-					serviceServers = append(serviceServers, openapiclient.ServerConfiguration{URL: fmt.Sprintf("%s://%s%s", serverURL.Scheme, parsedURL.Host, serverURL.Path), Description: server.Description})
-				}
-				operationServers[service] = serviceServers
-			}
-			configuration.OperationServers = operationServers
+			operationServers[service] = serviceServers
 		}
+		configuration.OperationServers = operationServers
 	}
 
+	token, err := resolveToken(config, hostname)
+	if err != nil {
+		return nil, err
+	}
+	if token == "" {
+		return nil, errors.New("'token' must be set in the connection configuration, or log in with 'steampipe login' or 'powerpipe login'. Edit your connection configuration file and then restart Steampipe")
+	}
+	configuration.AddDefaultHeader("Authorization", fmt.Sprintf("Bearer %s", token))
+
 	apiClient := openapiclient.NewAPIClient(configuration)
 
 	return apiClient, nil
 }
+
+// newBaseTransport returns the transport the connection's requests are sent
+// with: the shared transport, or a copy of it that also trusts the certificate
+// authorities in ca_cert_file if that is set, e.g. for a private tenant host
+// with a certificate issued by an internal CA.
+func newBaseTransport(config pipesConfig) (http.RoundTripper, error) {
+	if config.CACertFile == nil {
+		return httpClient.Transport, nil
+	}
+
+	path, err := expandHomeDir(*config.CACertFile)
+	if err != nil {
+		return nil, err
+	}
+	pem, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ca_cert_file: %v", err)
+	}
+	rootCAs, err := x509.SystemCertPool()
+	if err != nil {
+		rootCAs = x509.NewCertPool()
+	}
+	if !rootCAs.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("ca_cert_file %s contains no PEM certificates", *config.CACertFile)
+	}
+
+	transport := httpClient.Transport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{RootCAs: rootCAs}
+	return transport, nil
+}
+
+// resolveHostname returns the hostname of the Pipes API for the connection,
+// e.g. pipes.turbot.com. The host is taken from the `host` config argument,
+// then the STEAMPIPE_CLOUD_HOST or PIPES_HOST environment variables, and
+// defaults to pipes.turbot.com.
+func resolveHostname(config pipesConfig) (string, error) {
+	host := os.Getenv("STEAMPIPE_CLOUD_HOST")
+	// If `STEAMPIPE_CLOUD_HOST` is not set - we try to get the host from `PIPES_HOST`
+	if host == "" {
+		host = os.Getenv("PIPES_HOST")
+	}
+	// host value present in the config takes precedence over environment variable
+	if config.Host != nil {
+		host = *config.Host
+	}
+
+	// If there is no host in the config, we set default host to `pipes.turbot.com`
+	if host == "" {
+		return defaultHostname, nil
+	}
+
+	parsedURL, err := url.Parse(host)
+	if err != nil {
+		return "", fmt.Errorf(`invalid host: %v`, err)
+	}
+	if parsedURL.Host == "" {
+		return "", errors.New(`missing protocol or host`)
+	}
+	return parsedURL.Host, nil
+}
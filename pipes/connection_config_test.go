@@ -0,0 +1,95 @@
+package pipes
+
+import (
+	"net/http"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+)
+
+func TestAPIClientReused(t *testing.T) {
+	api := newFakePipesAPI(t, "pipes_api.json")
+	p := newTestPlugin(t, api)
+
+	for i := 0; i < 3; i++ {
+		p.MustQuery(testQuery{Table: "pipes_token", Columns: []string{"id"}})
+	}
+
+	if got := len(api.Requests("GET /user/alice/token")); got != 3 {
+		t.Fatalf("requests = %d, want 3", got)
+	}
+	// all queries share one client and so one keep-alive connection
+	if got := api.Connections(); got != 1 {
+		t.Errorf("connections = %d, want 1", got)
+	}
+}
+
+func TestAPIClientRebuiltOnConfigChange(t *testing.T) {
+	before := newFakePipesAPI(t, "pipes_api.json")
+	p := newTestPlugin(t, before)
+	p.MustQuery(testQuery{Table: "pipes_token", Columns: []string{"id"}})
+
+	after := newFakePipesAPI(t, "pipes_api.json")
+	p.SetAPI(after)
+	p.MustQuery(testQuery{Table: "pipes_token", Columns: []string{"id"}})
+
+	if got := len(before.Requests("GET /user/alice/token")); got != 1 {
+		t.Errorf("requests before config change = %d, want 1", got)
+	}
+	if got := len(after.Requests("GET /user/alice/token")); got != 1 {
+		t.Errorf("requests after config change = %d, want 1", got)
+	}
+}
+
+func TestConnectionHostColumns(t *testing.T) {
+	api := newFakePipesAPI(t, "pipes_api.json")
+	p := newTestPlugin(t, api)
+	hostname := strings.TrimPrefix(api.URL(), "https://")
+
+	rows := p.MustQuery(testQuery{Table: "pipes_token", Columns: []string{"id", "pipes_host", "pipes_tenant_id"}})
+	if got := rowStrings(rows, "pipes_host"); !reflect.DeepEqual(got, []string{hostname, hostname}) {
+		t.Errorf("pipes_host = %v, want %s", got, hostname)
+	}
+	if got := rowStrings(rows, "pipes_tenant_id"); !reflect.DeepEqual(got, []string{"t_pipes", "t_pipes"}) {
+		t.Errorf("pipes_tenant_id = %v, want t_pipes", got)
+	}
+
+	// a host qual that does not match the connection skips it without calling the API
+	rows = p.MustQuery(testQuery{
+		Table:   "pipes_token",
+		Columns: []string{"id"},
+		Quals:   []*proto.Qual{qual("pipes_host", "=", stringQualValue("pipes.other.com"))},
+	})
+	if len(rows) != 0 {
+		t.Errorf("rows = %d, want 0", len(rows))
+	}
+	if got := len(api.Requests("GET /user/alice/token")); got != 1 {
+		t.Errorf("requests = %d, want 1", got)
+	}
+}
+
+func TestCACertFile(t *testing.T) {
+	notPEM := filepath.Join(t.TempDir(), "ca.pem")
+	writeFile(t, notPEM, "not a certificate\n")
+
+	for _, path := range []string{filepath.Join(t.TempDir(), "missing.pem"), notPEM} {
+		if _, err := newBaseTransport(pipesConfig{CACertFile: &path}); err == nil {
+			t.Errorf("newBaseTransport(%s) error = nil, want error", path)
+		}
+	}
+
+	// the fake API's certificate is trusted by the connection's transport
+	api := newFakePipesAPI(t, "pipes_api.json")
+	transport, err := newBaseTransport(pipesConfig{CACertFile: &api.caCertFile})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := (&http.Client{Transport: transport}).Get(api.server.URL + fakeAPIBasePath + "/actor")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+}
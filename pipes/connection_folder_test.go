@@ -0,0 +1,113 @@
+package pipes
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+)
+
+func TestConnectionFolder(t *testing.T) {
+	api := newFakePipesAPI(t, "pipes_api.json")
+	p := newTestPlugin(t, api)
+
+	rows := p.MustQuery(testQuery{
+		Table:   "pipes_connection_folder",
+		Columns: []string{"id", "title", "parent_id", "path", "identity_handle"},
+	})
+
+	// users have no connection folders outside their workspaces
+	if got := rowStrings(rows, "path"); !reflect.DeepEqual(got, []string{"Production", "Production/AWS"}) {
+		t.Fatalf("paths = %v, want [Production Production/AWS]", got)
+	}
+	if got := rowStrings(rows, "identity_handle"); !reflect.DeepEqual(got, []string{"acme", "acme"}) {
+		t.Errorf("identity_handle = %v, want [acme acme]", got)
+	}
+	// the folders are listed once for the rows and their paths
+	if got := len(api.Requests("GET /org/acme/connection_folder")); got != 1 {
+		t.Errorf("folder list requests = %d, want 1", got)
+	}
+}
+
+func TestConnectionFolderRenamed(t *testing.T) {
+	api := newFakePipesAPI(t, "pipes_api.json")
+	p := newTestPlugin(t, api)
+
+	query := testQuery{
+		Table:   "pipes_connection",
+		Columns: []string{"handle", "folder_path"},
+		Quals:   []*proto.Qual{qual("identity_handle", "=", stringQualValue("acme"))},
+	}
+	folderPath := func(rows []*proto.Row) string {
+		for _, row := range rows {
+			if row.Columns["handle"].GetStringValue() == "aws_prod" {
+				return row.Columns["folder_path"].GetStringValue()
+			}
+		}
+		return ""
+	}
+
+	if got := folderPath(p.MustQuery(query)); got != "Production/AWS" {
+		t.Fatalf("folder_path = %q, want Production/AWS", got)
+	}
+
+	// the folders are listed again by the next query, so the new name is used
+	api.SetRoute("GET /org/acme/connection_folder", `{"items": [
+		{"id": "cf_prod", "title": "Prod", "identity_id": "o_acme", "parent_id": "o_acme"},
+		{"id": "cf_aws", "title": "AWS", "identity_id": "o_acme", "parent_id": "cf_prod"}
+	]}`)
+	if got := folderPath(p.MustQuery(query)); got != "Prod/AWS" {
+		t.Errorf("folder_path after rename = %q, want Prod/AWS", got)
+	}
+	if got := len(api.Requests("GET /org/acme/connection_folder")); got != 2 {
+		t.Errorf("folder list requests = %d, want 2", got)
+	}
+}
+
+func TestConnectionFolderPath(t *testing.T) {
+	api := newFakePipesAPI(t, "pipes_api.json")
+	p := newTestPlugin(t, api)
+
+	rows := p.MustQuery(testQuery{
+		Table:   "pipes_connection",
+		Columns: []string{"handle", "parent_id", "folder_id", "folder_path"},
+		Quals:   []*proto.Qual{qual("identity_handle", "=", stringQualValue("acme"))},
+	})
+
+	want := map[string][2]string{
+		"aws_prod": {"cf_aws", "Production/AWS"},
+		"gcp":      {"", ""},
+		// the parent folder no longer exists
+		"aws_old": {"", ""},
+	}
+	if len(rows) != len(want) {
+		t.Fatalf("rows = %d, want %d", len(rows), len(want))
+	}
+	for _, row := range rows {
+		handle := row.Columns["handle"].GetStringValue()
+		got := [2]string{row.Columns["folder_id"].GetStringValue(), row.Columns["folder_path"].GetStringValue()}
+		if got != want[handle] {
+			t.Errorf("%s folder_id, folder_path = %v, want %v", handle, got, want[handle])
+		}
+	}
+}
+
+func TestWorkspaceConnectionFolderPath(t *testing.T) {
+	api := newFakePipesAPI(t, "pipes_api.json")
+	p := newTestPlugin(t, api)
+
+	rows := p.MustQuery(testQuery{
+		Table:   "pipes_workspace_connection",
+		Columns: []string{"connection_handle", "folder_path"},
+	})
+
+	got := map[string]string{}
+	for _, row := range rows {
+		got[row.Columns["connection_handle"].GetStringValue()] = row.Columns["folder_path"].GetStringValue()
+	}
+	// workspace connections can be in the workspace's folders or the org's
+	want := map[string]string{"aws_prod": "Production/AWS", "team": "Team"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("folder paths = %v, want %v", got, want)
+	}
+}
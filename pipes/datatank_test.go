@@ -0,0 +1,95 @@
+package pipes
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+)
+
+func TestWorkspaceDatatank(t *testing.T) {
+	api := newFakePipesAPI(t, "pipes_api.json")
+	p := newTestPlugin(t, api)
+
+	rows := p.MustQuery(testQuery{
+		Table:   "pipes_workspace_datatank",
+		Columns: []string{"handle", "state", "state_reason", "workspace_handle", "table_count", "part_count", "error_part_count"},
+		Quals:   []*proto.Qual{qual("workspace_id", "=", stringQualValue("w_dev"))},
+	})
+
+	if got := rowStrings(rows, "handle"); !reflect.DeepEqual(got, []string{"aws", "gcp"}) {
+		t.Fatalf("handles = %v, want [aws gcp]", got)
+	}
+	for _, row := range rows {
+		var wantTables, wantParts, wantErrors int64
+		if row.Columns["handle"].GetStringValue() == "aws" {
+			wantTables, wantParts, wantErrors = 2, 4, 1
+		} else if got := row.Columns["state_reason"].GetStringValue(); got != "Connection gcp has invalid credentials." {
+			t.Errorf("gcp state_reason = %q", got)
+		}
+		if got := row.Columns["workspace_handle"].GetStringValue(); got != "dev" {
+			t.Errorf("workspace_handle = %q, want dev", got)
+		}
+		got := []int64{row.Columns["table_count"].GetIntValue(), row.Columns["part_count"].GetIntValue(), row.Columns["error_part_count"].GetIntValue()}
+		if want := []int64{wantTables, wantParts, wantErrors}; !reflect.DeepEqual(got, want) {
+			t.Errorf("%s table, part and error part counts = %v, want %v", row.Columns["handle"].GetStringValue(), got, want)
+		}
+	}
+}
+
+func TestWorkspaceDatatankTable(t *testing.T) {
+	api := newFakePipesAPI(t, "pipes_api.json")
+	p := newTestPlugin(t, api)
+
+	rows := p.MustQuery(testQuery{
+		Table:   "pipes_workspace_datatank_table",
+		Columns: []string{"name", "datatank_handle", "identity_handle", "workspace_id", "part_count", "last_updated_at"},
+		Quals:   []*proto.Qual{qual("workspace_id", "=", stringQualValue("w_dev"))},
+	})
+
+	if got := rowStrings(rows, "name"); !reflect.DeepEqual(got, []string{"aws_s3_bucket", "iam_users"}) {
+		t.Fatalf("names = %v, want [aws_s3_bucket iam_users]", got)
+	}
+	if got := rowStrings(rows, "datatank_handle"); !reflect.DeepEqual(got, []string{"aws", "aws"}) {
+		t.Errorf("datatank_handle = %v, want [aws aws]", got)
+	}
+	if got := rowStrings(rows, "identity_handle"); !reflect.DeepEqual(got, []string{"alice", "alice"}) {
+		t.Errorf("identity_handle = %v, want [alice alice]", got)
+	}
+	for _, row := range rows {
+		if row.Columns["name"].GetStringValue() != "aws_s3_bucket" {
+			continue
+		}
+		if got := row.Columns["part_count"].GetIntValue(); got != 3 {
+			t.Errorf("part_count = %d, want 3", got)
+		}
+		if got := row.Columns["last_updated_at"].GetTimestampValue().AsTime().Format("2006-01-02"); got != "2024-02-02" {
+			t.Errorf("last_updated_at = %s, want 2024-02-02", got)
+		}
+	}
+}
+
+func TestWorkspaceDatatankTableForDatatank(t *testing.T) {
+	api := newFakePipesAPI(t, "pipes_api.json")
+	p := newTestPlugin(t, api)
+
+	rows := p.MustQuery(testQuery{
+		Table:   "pipes_workspace_datatank_table",
+		Columns: []string{"name"},
+		Quals: []*proto.Qual{
+			qual("workspace_id", "=", stringQualValue("w_dev")),
+			qual("datatank_handle", "=", stringQualValue("aws")),
+		},
+	})
+
+	if len(rows) != 2 {
+		t.Errorf("rows = %d, want 2", len(rows))
+	}
+	// the datatank is fetched directly rather than listing them all
+	if got := len(api.Requests("GET /user/alice/workspace/w_dev/datatank")); got != 0 {
+		t.Errorf("datatank list requests = %d, want 0", got)
+	}
+	if got := len(api.Requests("GET /user/alice/workspace/w_dev/datatank/gcp/table")); got != 0 {
+		t.Errorf("gcp table list requests = %d, want 0", got)
+	}
+}
@@ -0,0 +1,89 @@
+package pipes
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+)
+
+func TestWorkspaceDBLog(t *testing.T) {
+	api := newFakePipesAPI(t, "pipes_api.json")
+	p := newTestPlugin(t, api)
+
+	start := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	rows := p.MustQuery(testQuery{
+		Table:   "pipes_workspace_db_log",
+		Columns: []string{"id", "identity_id", "identity_handle", "workspace_handle", "created_at", "statement_type", "referenced_tables", "query_source"},
+		Quals: []*proto.Qual{
+			qual("identity_handle", "=", stringQualValue("alice")),
+			qual("workspace_handle", "=", stringQualValue("dev")),
+			qual("log_timestamp", ">=", timestampQualValue(start)),
+			qual("log_timestamp", "<", timestampQualValue(start.AddDate(0, 0, 7))),
+			qual("actor_handle", "=", stringQualValue("alice")),
+			qual("duration", ">", &proto.QualValue{Value: &proto.QualValue_DoubleValue{DoubleValue: 1000}}),
+		},
+	})
+
+	// the fake ignores the filter, so returns every db log of the workspace
+	if got := rowStrings(rows, "id"); !reflect.DeepEqual(got, []string{"dbl_1", "dbl_2"}) {
+		t.Fatalf("ids = %v, want [dbl_1 dbl_2]", got)
+	}
+	for _, row := range rows {
+		if got := row.Columns["identity_id"].GetStringValue() + "/" + row.Columns["identity_handle"].GetStringValue(); got != "u_alice/alice" {
+			t.Errorf("identity_id/identity_handle = %s, want u_alice/alice", got)
+		}
+		if got := row.Columns["created_at"].GetTimestampValue(); got == nil {
+			t.Errorf("created_at = null, want a timestamp")
+		}
+		if got := row.Columns["statement_type"].GetStringValue(); got != "select" {
+			t.Errorf("statement_type = %q, want select", got)
+		}
+		if got := row.Columns["query_source"].GetStringValue(); got != querySourceInteractive {
+			t.Errorf("query_source = %q, want %s", got, querySourceInteractive)
+		}
+		if row.Columns["id"].GetStringValue() == "dbl_1" {
+			if got := string(row.Columns["referenced_tables"].GetJsonValue()); got != `["aws_s3_bucket"]` {
+				t.Errorf("referenced_tables = %s, want [\"aws_s3_bucket\"]", got)
+			}
+		}
+	}
+
+	// the workspace is fetched rather than listing every workspace
+	if got := len(api.Requests("GET /user/alice/workspace")); got != 0 {
+		t.Errorf("workspace list requests = %d, want 0", got)
+	}
+	if got := len(api.Requests("GET /user/alice/workspace/dev")); got != 1 {
+		t.Errorf("workspace get requests = %d, want 1", got)
+	}
+
+	var got []string
+	for _, r := range api.Requests("GET /user/alice/workspace/w_dev/db_log") {
+		got = append(got, r.Query.Get("where"))
+	}
+	want := []string{`log_timestamp >= '2024-02-01 00:00:00.00000' and log_timestamp < '2024-02-08 00:00:00.00000' and actor_handle = 'alice' and duration > 1000`}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("where = %q, want %q", got, want)
+	}
+}
+
+func TestWorkspaceDBLogWorkspaceQual(t *testing.T) {
+	api := newFakePipesAPI(t, "pipes_api.json")
+	p := newTestPlugin(t, api)
+
+	// without an identity the workspaces are listed, and only the db logs of
+	// the workspace asked for are fetched
+	rows := p.MustQuery(testQuery{
+		Table:   "pipes_workspace_db_log",
+		Columns: []string{"id"},
+		Quals:   []*proto.Qual{qual("workspace_id", "=", stringQualValue("w_dev"))},
+	})
+
+	if got := rowStrings(rows, "id"); !reflect.DeepEqual(got, []string{"dbl_1", "dbl_2"}) {
+		t.Errorf("ids = %v, want [dbl_1 dbl_2]", got)
+	}
+	if got := len(api.Requests("GET /org/acme/workspace/w_prod/db_log")); got != 0 {
+		t.Errorf("w_prod db log requests = %d, want 0", got)
+	}
+}
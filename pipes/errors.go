@@ -2,33 +2,162 @@ package pipes
 
 import (
 	"context"
-	"log"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
 	"strings"
 
+	openapi "github.com/turbot/pipes-sdk-go"
 	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
 )
 
-func shouldIgnoreErrors(notFoundErrors []string) plugin.ErrorPredicateWithContext {
-	return func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData, err error) bool {
-		for _, pattern := range notFoundErrors {
-			// handle not found error
-			if strings.Contains(err.Error(), pattern) {
-				return true
-			}
+// apiError is an error returned by the Pipes API, classified from the HTTP
+// status code and the error payload in the response body.
+type apiError struct {
+	StatusCode int
+	Title      string
+	Detail     string
+	// ValidationErrors are the "<location>: <message>" details of a request
+	// rejected by the API's validation.
+	ValidationErrors []string
+
+	err error
+}
+
+// Error returns a message that explains the error, led by the status code and
+// followed by the detail returned by the API.
+func (e *apiError) Error() string {
+	var message string
+	switch {
+	case e.StatusCode == http.StatusUnauthorized:
+		message = "the token is invalid, has expired or has been revoked"
+	case e.StatusCode == http.StatusPaymentRequired:
+		message = "the plan for this identity does not include this feature"
+	case e.StatusCode == http.StatusForbidden:
+		message = "the token's user does not have a role that allows this request"
+	case e.StatusCode == http.StatusNotFound:
+		message = "the resource was not found"
+	case e.StatusCode == http.StatusTooManyRequests:
+		message = "rate limited by the Pipes API"
+	case e.StatusCode == http.StatusBadRequest || e.StatusCode == http.StatusUnprocessableEntity:
+		message = "the request was rejected by the Pipes API"
+	case e.StatusCode >= 500:
+		message = "the Pipes API failed to handle the request"
+	default:
+		message = strings.ToLower(http.StatusText(e.StatusCode))
+	}
+
+	msg := fmt.Sprintf("%d %s: %s", e.StatusCode, http.StatusText(e.StatusCode), message)
+	if detail := e.detail(); detail != "" {
+		msg += ": " + detail
+	}
+	if len(e.ValidationErrors) > 0 {
+		msg += " (" + strings.Join(e.ValidationErrors, "; ") + ")"
+	}
+	return msg
+}
+
+// detail returns the most specific description of the error in the payload,
+// skipping those that only repeat the status text.
+func (e *apiError) detail() string {
+	for _, s := range []string{e.Detail, e.Title} {
+		if s != "" && !strings.EqualFold(s, http.StatusText(e.StatusCode)) {
+			return s
 		}
-		return false
 	}
+	return ""
 }
 
-// Legacy function - kept for backward compatibility
-func shouldRetryError(err error) bool {
-	if strings.Contains(err.Error(), "429") {
-		log.Printf("[WARN] Received Rate Limit Error")
-		return true
+func (e *apiError) Unwrap() error {
+	return e.err
+}
+
+// classifyError returns an *apiError for an error returned by the Pipes API,
+// or the error unchanged if it did not come from an API response.
+func classifyError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var classified *apiError
+	if errors.As(err, &classified) {
+		return err
+	}
+
+	var openAPIErr openapi.GenericOpenAPIError
+	if !errors.As(err, &openAPIErr) {
+		return err
 	}
-	return false
+
+	// The client only decodes the error payload for some status codes, so fall
+	// back to decoding the body, then to the status text of the response
+	model, ok := openAPIErr.Model().(openapi.ErrorModel)
+	if !ok || model.Status == 0 {
+		if jsonErr := json.Unmarshal(openAPIErr.Body(), &model); jsonErr != nil {
+			model = openapi.ErrorModel{}
+		}
+	}
+	statusCode := int(model.Status)
+	if statusCode == 0 {
+		statusCode = statusFromText(openAPIErr.Error())
+	}
+	if statusCode == 0 {
+		return err
+	}
+
+	classified = &apiError{
+		StatusCode: statusCode,
+		Title:      model.Title,
+		Detail:     model.GetDetail(),
+		err:        err,
+	}
+	for _, v := range model.GetValidationErrors() {
+		switch {
+		case v.GetLocation() != "" && v.GetMessage() != "":
+			classified.ValidationErrors = append(classified.ValidationErrors, v.GetLocation()+": "+v.GetMessage())
+		case v.GetMessage() != "":
+			classified.ValidationErrors = append(classified.ValidationErrors, v.GetMessage())
+		}
+	}
+	return classified
+}
+
+// statusFromText returns the status code from an HTTP status text such as
+// "404 Not Found", or 0 if the text does not start with a status code.
+func statusFromText(text string) int {
+	code, _, found := strings.Cut(text, " ")
+	if !found || len(code) != 3 {
+		return 0
+	}
+	statusCode, err := strconv.Atoi(code)
+	if err != nil || statusCode < 100 || statusCode > 599 {
+		return 0
+	}
+	return statusCode
 }
 
-func shouldRetryErrorFunc(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData, err error) bool {
-	return shouldRetryError(err)
+// errorStatusCode returns the HTTP status code of an error returned by the
+// Pipes API, or 0 if it did not come from an API response.
+func errorStatusCode(err error) int {
+	var classified *apiError
+	if errors.As(classifyError(err), &classified) {
+		return classified.StatusCode
+	}
+	return 0
+}
+
+// shouldIgnoreErrors returns an ignore predicate matching API errors with any
+// of the given HTTP status codes.
+func shouldIgnoreErrors(statusCodes []int) plugin.ErrorPredicateWithContext {
+	return func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData, err error) bool {
+		statusCode := errorStatusCode(err)
+		for _, code := range statusCodes {
+			if statusCode == code {
+				return true
+			}
+		}
+		return false
+	}
 }
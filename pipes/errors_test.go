@@ -0,0 +1,238 @@
+package pipes
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+)
+
+func TestNotFoundIgnoredOnGet(t *testing.T) {
+	api := newFakePipesAPI(t, "pipes_api.json")
+	p := newTestPlugin(t, api)
+
+	tests := []testQuery{
+		{
+			Table:   "pipes_workspace",
+			Columns: []string{"id", "handle"},
+			Quals: []*proto.Qual{
+				qual("identity_handle", "=", stringQualValue("alice")),
+				qual("handle", "=", stringQualValue("missing")),
+			},
+		},
+		{
+			Table:   "pipes_token",
+			Columns: []string{"id"},
+			Quals:   []*proto.Qual{qual("id", "=", stringQualValue("tok_missing"))},
+		},
+	}
+	for _, q := range tests {
+		t.Run(q.Table, func(t *testing.T) {
+			rows, err := p.Query(q)
+			if err != nil {
+				t.Fatalf("error = %v, want nil", err)
+			}
+			if len(rows) != 0 {
+				t.Errorf("rows = %d, want 0", len(rows))
+			}
+		})
+	}
+}
+
+func TestNotFoundIgnoredOnList(t *testing.T) {
+	api := newFakePipesAPI(t, "pipes_api.json")
+	api.Fail("GET /user/alice/audit_log", http.StatusNotFound)
+	p := newTestPlugin(t, api)
+
+	rows, err := p.Query(testQuery{
+		Table:   "pipes_audit_log",
+		Columns: []string{"id"},
+		Quals:   []*proto.Qual{qual("identity_handle", "=", stringQualValue("alice"))},
+	})
+	if err != nil {
+		t.Fatalf("error = %v, want nil", err)
+	}
+	if len(rows) != 0 {
+		t.Errorf("rows = %d, want 0", len(rows))
+	}
+}
+
+func TestRateLimitRetriedOnList(t *testing.T) {
+	api := newFakePipesAPI(t, "pipes_api.json")
+	api.Fail("GET /user/alice/token", http.StatusTooManyRequests, http.StatusTooManyRequests)
+	p := newTestPlugin(t, api)
+
+	rows := p.MustQuery(testQuery{
+		Table:   "pipes_token",
+		Columns: []string{"id"},
+	})
+
+	want := []string{"tok_1", "tok_2"}
+	if got := rowStrings(rows, "id"); !reflect.DeepEqual(got, want) {
+		t.Errorf("ids = %v, want %v", got, want)
+	}
+	if got := len(api.Requests("GET /user/alice/token")); got != 3 {
+		t.Errorf("requests = %d, want 3", got)
+	}
+}
+
+func TestRateLimitRetriedOnLaterPage(t *testing.T) {
+	api := newFakePipesAPI(t, "pipes_api.json")
+	// the fake serves one audit log record per page, fail the second page
+	api.Fail("GET /user/alice/audit_log?next_token=1", http.StatusTooManyRequests)
+	p := newTestPlugin(t, api)
+
+	rows := p.MustQuery(testQuery{
+		Table:   "pipes_audit_log",
+		Columns: []string{"id"},
+		Quals:   []*proto.Qual{qual("identity_handle", "=", stringQualValue("alice"))},
+	})
+
+	want := []string{"a_1", "a_2"}
+	if got := rowStrings(rows, "id"); !reflect.DeepEqual(got, want) {
+		t.Errorf("ids = %v, want %v", got, want)
+	}
+
+	// only the failed page is fetched again
+	var tokens []string
+	for _, r := range api.Requests("GET /user/alice/audit_log") {
+		tokens = append(tokens, r.Query.Get("next_token"))
+	}
+	if want := []string{"", "1", "1"}; !reflect.DeepEqual(tokens, want) {
+		t.Errorf("next_tokens = %q, want %q", tokens, want)
+	}
+}
+
+func TestRateLimitRetriedOnGet(t *testing.T) {
+	api := newFakePipesAPI(t, "pipes_api.json")
+	api.Fail("GET /user/alice/workspace/dev", http.StatusTooManyRequests)
+	p := newTestPlugin(t, api)
+
+	rows := p.MustQuery(testQuery{
+		Table:   "pipes_workspace",
+		Columns: []string{"id", "handle"},
+		Quals: []*proto.Qual{
+			qual("identity_handle", "=", stringQualValue("alice")),
+			qual("handle", "=", stringQualValue("dev")),
+		},
+	})
+
+	if got := rowStrings(rows, "id"); !reflect.DeepEqual(got, []string{"w_dev"}) {
+		t.Errorf("ids = %v, want [w_dev]", got)
+	}
+	if got := len(api.Requests("GET /user/alice/workspace/dev")); got != 2 {
+		t.Errorf("requests = %d, want 2", got)
+	}
+}
+
+func TestServerErrorRetried(t *testing.T) {
+	api := newFakePipesAPI(t, "pipes_api.json")
+	api.Fail("GET /user/alice/token", http.StatusBadGateway, http.StatusServiceUnavailable)
+	p := newTestPlugin(t, api)
+
+	rows := p.MustQuery(testQuery{
+		Table:   "pipes_token",
+		Columns: []string{"id"},
+	})
+
+	if got := rowStrings(rows, "id"); !reflect.DeepEqual(got, []string{"tok_1", "tok_2"}) {
+		t.Errorf("ids = %v, want [tok_1 tok_2]", got)
+	}
+	if got := len(api.Requests("GET /user/alice/token")); got != 3 {
+		t.Errorf("requests = %d, want 3", got)
+	}
+}
+
+func TestClientErrorReturned(t *testing.T) {
+	api := newFakePipesAPI(t, "pipes_api.json")
+	api.Fail("GET /user/alice/token", http.StatusForbidden)
+	p := newTestPlugin(t, api)
+
+	_, err := p.Query(testQuery{
+		Table:   "pipes_token",
+		Columns: []string{"id"},
+	})
+	if err == nil || !strings.Contains(err.Error(), "403") {
+		t.Fatalf("error = %v, want 403 error", err)
+	}
+	if got := len(api.Requests("GET /user/alice/token")); got != 1 {
+		t.Errorf("requests = %d, want 1 (no retries)", got)
+	}
+}
+
+func TestErrorClassification(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		wantErr string
+	}{
+		{
+			name:    "expired token",
+			status:  http.StatusUnauthorized,
+			body:    `{"status": 401, "title": "Unauthorized", "detail": "Token has expired."}`,
+			wantErr: "401 Unauthorized: the token is invalid, has expired or has been revoked: Token has expired.",
+		},
+		{
+			name:    "insufficient role",
+			status:  http.StatusForbidden,
+			body:    `{"status": 403, "title": "Forbidden", "detail": "Insufficient org role, owner required."}`,
+			wantErr: "403 Forbidden: the token's user does not have a role that allows this request: Insufficient org role, owner required.",
+		},
+		{
+			name:    "validation errors",
+			status:  http.StatusBadRequest,
+			body:    `{"status": 400, "title": "Bad Request", "validation_errors": [{"location": "query.where", "message": "invalid filter"}]}`,
+			wantErr: "400 Bad Request: the request was rejected by the Pipes API (query.where: invalid filter)",
+		},
+		{
+			name:    "payload without status",
+			status:  http.StatusConflict,
+			body:    `conflict`,
+			wantErr: "409 Conflict: conflict",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newFakePipesAPI(t, "pipes_api.json")
+			api.FailWithBody("GET /user/alice/token", tt.status, tt.body)
+			p := newTestPlugin(t, api)
+
+			_, err := p.Query(testQuery{Table: "pipes_token", Columns: []string{"id"}})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNotFoundMatchedByStatus(t *testing.T) {
+	api := newFakePipesAPI(t, "pipes_api.json")
+	// the detail mentions 404, but the status is a client error that must not be ignored
+	api.FailWithBody("GET /user/alice/token", http.StatusConflict, `{"status": 409, "title": "Conflict", "detail": "Token tok_404 conflicts."}`)
+	p := newTestPlugin(t, api)
+
+	_, err := p.Query(testQuery{Table: "pipes_token", Columns: []string{"id"}})
+	if err == nil || !strings.Contains(err.Error(), "409 Conflict") {
+		t.Fatalf("error = %v, want 409 error", err)
+	}
+}
+
+func TestStatusFromText(t *testing.T) {
+	tests := map[string]int{
+		"404 Not Found":                   404,
+		"503 Service Unavailable":         503,
+		"workspace w_404 not found":       0,
+		"invalid character 'u' in 404":    0,
+		"4040 Not Found":                  0,
+		"":                                0,
+		"json: cannot unmarshal 404 Body": 0,
+	}
+	for text, want := range tests {
+		if got := statusFromText(text); got != want {
+			t.Errorf("statusFromText(%q) = %d, want %d", text, got, want)
+		}
+	}
+}
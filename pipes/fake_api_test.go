@@ -0,0 +1,266 @@
+package pipes
+
+import (
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeAPIBasePath is the path prefix of the Pipes REST API. Routes in the fake
+// are registered relative to it, e.g. "GET /actor".
+const fakeAPIBasePath = "/api/v0"
+
+// fakePipesAPI is an in-memory fake of the Pipes REST API, served over TLS by
+// httptest. Routes are loaded from JSON fixtures in testdata; list routes are
+// paginated with the limit and next_token parameters just like the real API.
+// Every request is recorded so that tests can assert on what was sent.
+type fakePipesAPI struct {
+	t      *testing.T
+	server *httptest.Server
+	// caCertFile is the path to the PEM certificate of the fake's self-signed
+	// TLS certificate, to trust in the connection config.
+	caCertFile string
+
+	mu          sync.Mutex
+	routes      map[string]*fakeRoute
+	failures    map[string][]fakeFailure
+	requests    []fakeRequest
+	connections int
+}
+
+// fakeRoute is the canned response for a single route. A route with Items is a
+// list route and is returned one page at a time as {"items": [...],
+// "next_token": "..."}, with pages capped at PageSize items if it is set; a
+// route with Text returns it as is with the given ContentType, e.g. a snapshot
+// downloaded as HTML; otherwise Body is returned as is.
+type fakeRoute struct {
+	Status      int               `json:"status"`
+	Body        json.RawMessage   `json:"body"`
+	Items       []json.RawMessage `json:"items"`
+	PageSize    int               `json:"page_size"`
+	Text        string            `json:"text"`
+	ContentType string            `json:"content_type"`
+}
+
+// fakeFailure is a failed response queued for a route. Body is the error
+// payload, or a generic payload for the status if it is nil.
+type fakeFailure struct {
+	Status int
+	Body   json.RawMessage
+}
+
+// fakeRequest is a request received by the fake.
+type fakeRequest struct {
+	Method string
+	Path   string
+	Query  url.Values
+	Body   []byte
+}
+
+// newFakePipesAPI starts a fake API serving the routes in the given fixture
+// files. The server is shut down when the test completes.
+func newFakePipesAPI(t *testing.T, fixtures ...string) *fakePipesAPI {
+	t.Helper()
+	api := &fakePipesAPI{
+		t:        t,
+		routes:   map[string]*fakeRoute{},
+		failures: map[string][]fakeFailure{},
+	}
+	for _, fixture := range fixtures {
+		api.loadFixture(fixture)
+	}
+	api.server = httptest.NewUnstartedServer(http.HandlerFunc(api.serveHTTP))
+	api.server.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			api.mu.Lock()
+			api.connections++
+			api.mu.Unlock()
+		}
+	}
+	api.server.StartTLS()
+	t.Cleanup(api.server.Close)
+
+	api.caCertFile = filepath.Join(t.TempDir(), "ca.pem")
+	certificate := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: api.server.Certificate().Raw})
+	if err := os.WriteFile(api.caCertFile, certificate, 0600); err != nil {
+		t.Fatalf("newFakePipesAPI: %v", err)
+	}
+	return api
+}
+
+// loadFixture registers the routes in testdata/<name>. A fixture is a JSON
+// object keyed by "<METHOD> <path>"; later fixtures override earlier ones.
+func (a *fakePipesAPI) loadFixture(name string) {
+	a.t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		a.t.Fatalf("loadFixture: %v", err)
+	}
+	routes := map[string]*fakeRoute{}
+	if err := json.Unmarshal(data, &routes); err != nil {
+		a.t.Fatalf("loadFixture %s: %v", name, err)
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for key, route := range routes {
+		a.routes[key] = route
+	}
+}
+
+// URL returns the host to use in the connection config.
+func (a *fakePipesAPI) URL() string {
+	return a.server.URL
+}
+
+// SetRoute replaces the canned response for the route, given as JSON in the
+// fixture format, e.g. to change the data the API returns between queries.
+func (a *fakePipesAPI) SetRoute(key string, route string) {
+	a.t.Helper()
+	r := &fakeRoute{}
+	if err := json.Unmarshal([]byte(route), r); err != nil {
+		a.t.Fatalf("SetRoute %s: %v", key, err)
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.routes[key] = r
+}
+
+// Fail makes the next len(statuses) requests to the route fail with the given
+// status codes, in order, before the route is served normally again. A single
+// page of a list route can be failed with a "?next_token=<token>" suffix.
+func (a *fakePipesAPI) Fail(route string, statuses ...int) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for _, status := range statuses {
+		a.failures[route] = append(a.failures[route], fakeFailure{Status: status})
+	}
+}
+
+// FailWithBody makes the next request to the route fail with the given status
+// code and error payload.
+func (a *fakePipesAPI) FailWithBody(route string, status int, body string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.failures[route] = append(a.failures[route], fakeFailure{Status: status, Body: json.RawMessage(body)})
+}
+
+// Connections returns the number of connections opened to the fake.
+func (a *fakePipesAPI) Connections() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.connections
+}
+
+// Requests returns the requests received for the route.
+func (a *fakePipesAPI) Requests(route string) []fakeRequest {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	var requests []fakeRequest
+	for _, r := range a.requests {
+		if r.Method+" "+r.Path == route {
+			requests = append(requests, r)
+		}
+	}
+	return requests
+}
+
+func (a *fakePipesAPI) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, fakeAPIBasePath)
+	key := r.Method + " " + path
+	body, _ := io.ReadAll(r.Body)
+
+	a.mu.Lock()
+	a.requests = append(a.requests, fakeRequest{Method: r.Method, Path: path, Query: r.URL.Query(), Body: body})
+	var failure *fakeFailure
+	for _, failKey := range []string{key + "?next_token=" + r.URL.Query().Get("next_token"), key} {
+		if pending := a.failures[failKey]; len(pending) > 0 {
+			failure, a.failures[failKey] = &pending[0], pending[1:]
+			break
+		}
+	}
+	route := a.routes[key]
+	a.mu.Unlock()
+
+	if r.Header.Get("Authorization") != "Bearer "+fakeAPIToken {
+		writeFakeError(w, http.StatusUnauthorized)
+		return
+	}
+	if failure != nil && failure.Body != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(failure.Status)
+		_, _ = w.Write(failure.Body)
+		return
+	}
+	if failure != nil {
+		writeFakeError(w, failure.Status)
+		return
+	}
+	if route == nil {
+		writeFakeError(w, http.StatusNotFound)
+		return
+	}
+	if route.Status >= http.StatusBadRequest {
+		writeFakeError(w, route.Status)
+		return
+	}
+
+	if route.Text != "" {
+		w.Header().Set("Content-Type", route.ContentType)
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(route.Text))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if route.Items == nil {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(route.Body)
+		return
+	}
+
+	page := struct {
+		Items     []json.RawMessage `json:"items"`
+		NextToken *string           `json:"next_token,omitempty"`
+	}{Items: []json.RawMessage{}}
+
+	start, _ := strconv.Atoi(r.URL.Query().Get("next_token"))
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit < 1 {
+		limit = len(route.Items)
+	}
+	if route.PageSize > 0 && limit > route.PageSize {
+		limit = route.PageSize
+	}
+	end := start + limit
+	if end > len(route.Items) {
+		end = len(route.Items)
+	}
+	if start < end {
+		page.Items = route.Items[start:end]
+	}
+	if end < len(route.Items) {
+		next := strconv.Itoa(end)
+		page.NextToken = &next
+	}
+	_ = json.NewEncoder(w).Encode(page)
+}
+
+func writeFakeError(w http.ResponseWriter, status int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"status": status,
+		"title":  http.StatusText(status),
+		"detail": http.StatusText(status),
+	})
+}
@@ -0,0 +1,263 @@
+package pipes
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/quals"
+)
+
+// filterTimestampFormat is the layout used for timestamp literals in filters.
+// Timestamps are always rendered in UTC.
+const filterTimestampFormat = "2006-01-02 15:04:05.00000"
+
+// filterSkipColumns are list key columns that are never pushed into the
+// `where` filter: the identity and workspace columns choose which API is
+// called, and query_where is appended as an expression of its own.
+var filterSkipColumns = map[string]bool{
+	"identity_handle":  true,
+	"identity_id":      true,
+	"workspace_handle": true,
+	"workspace_id":     true,
+	"query_where":      true,
+}
+
+// queryFilter builds the `where` expression accepted by the Pipes list APIs,
+// see https://turbot.com/pipes/docs/reference/query-filter. Values are always
+// rendered as quoted and escaped literals, so they can never change the shape
+// of the expression. Column names are not escaped and must come from the
+// table definition, never from user input.
+type queryFilter struct {
+	clauses []string
+}
+
+// Compare adds a `column <operator> value` clause. operator must be one of
+// =, <>, <, <=, > or >=.
+func (f *queryFilter) Compare(column, operator string, value interface{}) *queryFilter {
+	return f.add("%s %s %s", column, operator, filterLiteral(value))
+}
+
+// In adds a `column in (...)` clause.
+func (f *queryFilter) In(column string, values ...interface{}) *queryFilter {
+	return f.add("%s in (%s)", column, filterLiteralList(values))
+}
+
+// NotIn adds a `column not in (...)` clause.
+func (f *queryFilter) NotIn(column string, values ...interface{}) *queryFilter {
+	return f.add("%s not in (%s)", column, filterLiteralList(values))
+}
+
+// Like adds a case sensitive `column like pattern` clause.
+func (f *queryFilter) Like(column, pattern string) *queryFilter {
+	return f.add("%s like %s", column, filterLiteral(pattern))
+}
+
+// NotLike adds a case sensitive `column not like pattern` clause.
+func (f *queryFilter) NotLike(column, pattern string) *queryFilter {
+	return f.add("%s not like %s", column, filterLiteral(pattern))
+}
+
+// ILike adds a case insensitive `column ilike pattern` clause.
+func (f *queryFilter) ILike(column, pattern string) *queryFilter {
+	return f.add("%s ilike %s", column, filterLiteral(pattern))
+}
+
+// NotILike adds a case insensitive `column not ilike pattern` clause.
+func (f *queryFilter) NotILike(column, pattern string) *queryFilter {
+	return f.add("%s not ilike %s", column, filterLiteral(pattern))
+}
+
+// IsNull adds a `column is null` clause.
+func (f *queryFilter) IsNull(column string) *queryFilter {
+	return f.add("%s is null", column)
+}
+
+// IsNotNull adds a `column is not null` clause.
+func (f *queryFilter) IsNotNull(column string) *queryFilter {
+	return f.add("%s is not null", column)
+}
+
+// Expression adds a raw filter expression, e.g. the value of a query_where
+// qual. It is parenthesised so that an `or` inside it cannot escape the
+// conjunction with the other clauses.
+func (f *queryFilter) Expression(expression string) *queryFilter {
+	if strings.TrimSpace(expression) == "" {
+		return f
+	}
+	return f.add("(%s)", expression)
+}
+
+// Qual adds the clause for a Steampipe qual on the given column. Operators the
+// API cannot filter on are skipped; Postgres still applies them to the rows
+// that are returned.
+func (f *queryFilter) Qual(column string, qual *quals.Qual) *queryFilter {
+	switch qual.Operator {
+	case quals.QualOperatorIsNull:
+		return f.IsNull(column)
+	case quals.QualOperatorIsNotNull:
+		return f.IsNotNull(column)
+	}
+
+	if qual.Value == nil {
+		return f
+	}
+
+	switch qual.Operator {
+	case quals.QualOperatorEqual, quals.QualOperatorNotEqual:
+		// `column in (...)` and `column not in (...)` arrive as list values
+		if list := qual.Value.GetListValue(); list != nil {
+			values := make([]interface{}, 0, len(list.Values))
+			for _, v := range list.Values {
+				values = append(values, qualValue(v))
+			}
+			if qual.Operator == quals.QualOperatorEqual {
+				return f.In(column, values...)
+			}
+			return f.NotIn(column, values...)
+		}
+		return f.Compare(column, qual.Operator, qualValue(qual.Value))
+	case quals.QualOperatorLess, quals.QualOperatorLessOrEqual, quals.QualOperatorGreater, quals.QualOperatorGreaterOrEqual:
+		return f.Compare(column, qual.Operator, qualValue(qual.Value))
+	case quals.QualOperatorLike:
+		return f.Like(column, qual.Value.GetStringValue())
+	case quals.QualOperatorNotLike:
+		return f.NotLike(column, qual.Value.GetStringValue())
+	case quals.QualOperatorILike:
+		return f.ILike(column, qual.Value.GetStringValue())
+	case quals.QualOperatorNotILike:
+		return f.NotILike(column, qual.Value.GetStringValue())
+	}
+	return f
+}
+
+// String joins the clauses with `and`.
+func (f *queryFilter) String() string {
+	return strings.Join(f.clauses, " and ")
+}
+
+func (f *queryFilter) add(format string, args ...interface{}) *queryFilter {
+	f.clauses = append(f.clauses, fmt.Sprintf(format, args...))
+	return f
+}
+
+// buildListFilter returns the `where` filter for the quals passed on the
+// table's list key columns, combined with the query_where qual if set.
+func buildListFilter(d *plugin.QueryData) string {
+	filter := &queryFilter{}
+	for _, keyColumn := range d.Table.List.KeyColumns {
+		if filterSkipColumns[keyColumn.Name] {
+			continue
+		}
+		keyColumnQuals := d.Quals[keyColumn.Name]
+		if keyColumnQuals == nil {
+			continue
+		}
+		for _, qual := range keyColumnQuals.Quals {
+			filter.Qual(keyColumn.Name, qual)
+		}
+	}
+	filter.Expression(d.EqualsQualString("query_where"))
+	return filter.String()
+}
+
+// filterLiteral renders a value as a filter literal. Strings are single quoted
+// with embedded quotes doubled, and timestamps are rendered in UTC.
+func filterLiteral(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "'" + strings.ReplaceAll(v, "'", "''") + "'"
+	case time.Time:
+		return "'" + v.UTC().Format(filterTimestampFormat) + "'"
+	case *time.Time:
+		if v == nil {
+			return "null"
+		}
+		return filterLiteral(*v)
+	case bool:
+		return strconv.FormatBool(v)
+	case int:
+		return strconv.Itoa(v)
+	case int32:
+		return strconv.FormatInt(int64(v), 10)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return filterLiteral(fmt.Sprint(v))
+	}
+}
+
+func filterLiteralList(values []interface{}) string {
+	literals := make([]string, 0, len(values))
+	for _, v := range values {
+		literals = append(literals, filterLiteral(v))
+	}
+	return strings.Join(literals, ", ")
+}
+
+// qualValue returns the Go value held by a qual value.
+func qualValue(v *proto.QualValue) interface{} {
+	switch v.GetValue().(type) {
+	case *proto.QualValue_StringValue:
+		return v.GetStringValue()
+	case *proto.QualValue_Int64Value:
+		return v.GetInt64Value()
+	case *proto.QualValue_DoubleValue:
+		return v.GetDoubleValue()
+	case *proto.QualValue_BoolValue:
+		return v.GetBoolValue()
+	case *proto.QualValue_TimestampValue:
+		return v.GetTimestampValue().AsTime()
+	case *proto.QualValue_InetValue:
+		return v.GetInetValue().GetAddr()
+	case *proto.QualValue_JsonbValue:
+		return v.GetJsonbValue()
+	case *proto.QualValue_LtreeValue:
+		return v.GetLtreeValue()
+	}
+	return nil
+}
+
+// queryFilterContextKey is the context key for a filter to send with a list
+// request, see withQueryFilter.
+type queryFilterContextKey struct{}
+
+// withQueryFilter returns a context whose API requests are sent with filter as
+// the `where` query parameter. Some list APIs accept a filter but the API
+// client has no option to set it, e.g. the audit log APIs; requests for those
+// are made with this context instead. A blank filter is not sent.
+func withQueryFilter(ctx context.Context, filter string) context.Context {
+	if filter == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, queryFilterContextKey{}, filter)
+}
+
+// queryFilterTransport sets the `where` query parameter of requests made with
+// a context from withQueryFilter, unless the API client has already set it.
+type queryFilterTransport struct {
+	base http.RoundTripper
+}
+
+func (t *queryFilterTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	filter, ok := req.Context().Value(queryFilterContextKey{}).(string)
+	if !ok || req.URL.Query().Has("where") {
+		return t.base.RoundTrip(req)
+	}
+
+	// A RoundTripper must not modify the request it is given
+	req = req.Clone(req.Context())
+	query := req.URL.Query()
+	query.Set("where", filter)
+	req.URL.RawQuery = query.Encode()
+	return t.base.RoundTrip(req)
+}
@@ -0,0 +1,182 @@
+package pipes
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+)
+
+func TestQueryFilter(t *testing.T) {
+	createdAt := time.Date(2024, 2, 1, 10, 30, 0, 0, time.FixedZone("IST", 5*60*60+30*60))
+
+	tests := []struct {
+		name   string
+		filter *queryFilter
+		want   string
+	}{
+		{
+			name:   "empty",
+			filter: &queryFilter{},
+			want:   "",
+		},
+		{
+			name:   "escapes quotes",
+			filter: (&queryFilter{}).Compare("dashboard_title", "=", "Alice's dashboard' or 1=1 or title = '"),
+			want:   `dashboard_title = 'Alice''s dashboard'' or 1=1 or title = '''`,
+		},
+		{
+			name:   "timestamp in utc",
+			filter: (&queryFilter{}).Compare("created_at", ">=", createdAt),
+			want:   `created_at >= '2024-02-01 05:00:00.00000'`,
+		},
+		{
+			name:   "in and not in",
+			filter: (&queryFilter{}).In("state", "finished", "failed").NotIn("id", "p_1"),
+			want:   `state in ('finished', 'failed') and id not in ('p_1')`,
+		},
+		{
+			name:   "like and ilike",
+			filter: (&queryFilter{}).Like("dashboard_name", "aws_%").NotILike("dashboard_title", "%cis%"),
+			want:   `dashboard_name like 'aws_%' and dashboard_title not ilike '%cis%'`,
+		},
+		{
+			name:   "null checks",
+			filter: (&queryFilter{}).IsNull("expires_at").IsNotNull("updated_at"),
+			want:   `expires_at is null and updated_at is not null`,
+		},
+		{
+			name:   "expression is parenthesised",
+			filter: (&queryFilter{}).Compare("visibility", "=", "workspace").Expression("state = 'failed' or state = 'errored'"),
+			want:   `visibility = 'workspace' and (state = 'failed' or state = 'errored')`,
+		},
+		{
+			name:   "blank expression is skipped",
+			filter: (&queryFilter{}).Expression("  "),
+			want:   "",
+		},
+		{
+			name:   "literals",
+			filter: (&queryFilter{}).Compare("version_id", ">", int64(3)).Compare("enabled", "=", true).Compare("ratio", "<", 0.5),
+			want:   `version_id > 3 and enabled = true and ratio < 0.5`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.String(); got != tt.want {
+				t.Errorf("String() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestListFilterPushdown(t *testing.T) {
+	createdAt := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		query testQuery
+		route string
+		want  []string
+	}{
+		{
+			name: "snapshot",
+			query: testQuery{
+				Table:   "pipes_workspace_snapshot",
+				Columns: []string{"id"},
+				Quals: []*proto.Qual{
+					qual("dashboard_title", "=", stringQualValue("Alice's benchmark")),
+					qual("dashboard_name", "~~", stringQualValue("aws_compliance.%")),
+					qual("created_at", ">=", timestampQualValue(createdAt)),
+					qual("query_where", "=", stringQualValue("state = 'available' or state = 'failed'")),
+				},
+			},
+			route: "GET /user/alice/workspace/dev/snapshot",
+			want:  []string{`created_at >= '2024-02-01 00:00:00.00000' and dashboard_name like 'aws_compliance.%' and dashboard_title = 'Alice''s benchmark' and (state = 'available' or state = 'failed')`},
+		},
+		{
+			name: "snapshot control result",
+			query: testQuery{
+				Table:   "pipes_workspace_snapshot_control_result",
+				Columns: []string{"status"},
+				Quals: []*proto.Qual{
+					qual("workspace_handle", "=", stringQualValue("dev")),
+					qual("snapshot_id", "=", stringQualValue("snap_dev_1")),
+					qual("dashboard_name", "=", stringQualValue("aws_compliance.benchmark.cis_v300")),
+					qual("snapshot_created_at", ">=", timestampQualValue(createdAt)),
+				},
+			},
+			route: "GET /user/alice/workspace/dev/snapshot",
+			want:  []string{`id = 'snap_dev_1' and dashboard_name = 'aws_compliance.benchmark.cis_v300' and created_at >= '2024-02-01 00:00:00.00000'`},
+		},
+		{
+			name: "audit log",
+			query: testQuery{
+				Table:   "pipes_audit_log",
+				Columns: []string{"id"},
+				Quals: []*proto.Qual{
+					qual("identity_handle", "=", stringQualValue("alice")),
+					qual("created_at", ">", timestampQualValue(createdAt)),
+					qual("action_type", "~~", stringQualValue("workspace.%")),
+					qual("actor_id", "=", stringQualValue("u_alice")),
+					qual("target_id", "<>", stringQualValue("w_dev")),
+				},
+			},
+			route: "GET /user/alice/audit_log",
+			// the filter is sent with every page, and the fake serves one
+			// audit log record per page
+			want: []string{
+				`created_at > '2024-02-01 00:00:00.00000' and action_type like 'workspace.%' and actor_id = 'u_alice' and target_id <> 'w_dev'`,
+				`created_at > '2024-02-01 00:00:00.00000' and action_type like 'workspace.%' and actor_id = 'u_alice' and target_id <> 'w_dev'`,
+			},
+		},
+		{
+			name: "pipeline",
+			query: testQuery{
+				Table:   "pipes_workspace_pipeline",
+				Columns: []string{"id"},
+				Quals: []*proto.Qual{
+					qual("identity_handle", "=", stringQualValue("alice")),
+					qual("title", "~~*", stringQualValue("%daily%")),
+					qual("id", "<>", stringQualValue("p_dev_2")),
+					qual("workspace_id", "=", stringQualValue("w_dev")),
+				},
+			},
+			route: "GET /user/alice/workspace/w_dev/pipeline",
+			want:  []string{`id <> 'p_dev_2' and title ilike '%daily%'`},
+		},
+		{
+			name: "process",
+			query: testQuery{
+				Table:   "pipes_workspace_process",
+				Columns: []string{"id"},
+				Quals: []*proto.Qual{
+					qual("state", "=", listQualValue(stringQualValue("completed"), stringQualValue("failed"))),
+					qual("workspace_id", "=", stringQualValue("w_dev")),
+				},
+			},
+			route: "GET /user/alice/workspace/w_dev/process",
+			// the plugin SDK calls the list once for each value of an `in` qual
+			want: []string{`state = 'completed'`, `state = 'failed'`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newFakePipesAPI(t, "pipes_api.json")
+			p := newTestPlugin(t, api)
+
+			p.MustQuery(tt.query)
+
+			var got []string
+			for _, r := range api.Requests(tt.route) {
+				got = append(got, r.Query.Get("where"))
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("where = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,214 @@
+package pipes
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc"
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// fakeAPIToken is the token configured for test connections; the fake API
+// rejects any other bearer token.
+const fakeAPIToken = "tpt_fake_api_token"
+
+const testConnectionName = "pipes_test"
+
+var testCallId atomic.Int64
+
+// testPlugin is an instance of the plugin with a single connection pointing at
+// a fake Pipes API. Queries are executed in-process through the plugin SDK,
+// exactly as Steampipe would execute them over GRPC, so list and get configs,
+// key columns, hydrate dependencies, ignore and retry configs are all honored.
+type testPlugin struct {
+	t      *testing.T
+	server *grpc.PluginServer
+	config string
+}
+
+// testQuery describes a scan of a table.
+type testQuery struct {
+	Table   string
+	Columns []string
+	Quals   []*proto.Qual
+	Limit   int64
+}
+
+// newTestPlugin starts the plugin with a connection to the given fake API.
+func newTestPlugin(t *testing.T, api *fakePipesAPI) *testPlugin {
+	t.Helper()
+	return newTestPluginWithConfig(t, api, "")
+}
+
+// testServer is the plugin server shared by every test. The plugin SDK has no
+// way to shut a server down, so a server per test would leak its caches and
+// their goroutines. Each test points the connection at its own fake API
+// instead, which clears the connection cache just like editing the connection
+// config does.
+var (
+	testServer          = plugin.Server(&plugin.ServeOpts{PluginName: pluginName, PluginFunc: Plugin})
+	testServerConnected bool
+)
+
+// newTestPluginWithConfig starts the plugin with a connection to the given
+// fake API, adding the given HCL arguments to the connection config.
+func newTestPluginWithConfig(t *testing.T, api *fakePipesAPI, config string) *testPlugin {
+	t.Helper()
+
+	p := &testPlugin{t: t, server: testServer, config: config}
+	if testServerConnected {
+		p.SetAPI(api)
+		return p
+	}
+
+	res, err := testServer.SetAllConnectionConfigs(&proto.SetAllConnectionConfigsRequest{
+		Configs:        []*proto.ConnectionConfig{testConnectionConfig(api, config)},
+		MaxCacheSizeMb: 16,
+	})
+	if err != nil {
+		t.Fatalf("newTestPlugin: %v", err)
+	}
+	if failed := res.FailedConnections[testConnectionName]; failed != "" {
+		t.Fatalf("newTestPlugin: connection failed: %s", failed)
+	}
+	testServerConnected = true
+	return p
+}
+
+// SetAPI changes the connection config to point at another fake API, as if
+// the connection config file had been edited.
+func (p *testPlugin) SetAPI(api *fakePipesAPI) {
+	p.t.Helper()
+	res, err := p.server.UpdateConnectionConfigs(&proto.UpdateConnectionConfigsRequest{
+		Changed: []*proto.ConnectionConfig{testConnectionConfig(api, p.config)},
+	})
+	if err != nil {
+		p.t.Fatalf("SetAPI: %v", err)
+	}
+	if failed := res.FailedConnections[testConnectionName]; failed != "" {
+		p.t.Fatalf("SetAPI: connection failed: %s", failed)
+	}
+}
+
+func testConnectionConfig(api *fakePipesAPI, config string) *proto.ConnectionConfig {
+	return &proto.ConnectionConfig{
+		Connection:      testConnectionName,
+		Plugin:          pluginName,
+		PluginShortName: "pipes",
+		Config:          fmt.Sprintf("token = %q\nhost = %q\nca_cert_file = %q\n", fakeAPIToken, api.URL(), api.caCertFile) + config,
+	}
+}
+
+// Query executes the query and returns the rows streamed by the plugin.
+func (p *testPlugin) Query(q testQuery) ([]*proto.Row, error) {
+	p.t.Helper()
+
+	quals := map[string]*proto.Quals{}
+	for _, qual := range q.Quals {
+		if quals[qual.FieldName] == nil {
+			quals[qual.FieldName] = &proto.Quals{}
+		}
+		quals[qual.FieldName].Quals = append(quals[qual.FieldName].Quals, qual)
+	}
+
+	var limit *proto.NullableInt
+	if q.Limit > 0 {
+		limit = &proto.NullableInt{Value: q.Limit}
+	}
+
+	req := &proto.ExecuteRequest{
+		Table:      q.Table,
+		Connection: testConnectionName,
+		CallId:     fmt.Sprintf("test-%d", testCallId.Add(1)),
+		QueryContext: &proto.QueryContext{
+			Columns: q.Columns,
+			Quals:   quals,
+			Limit:   limit,
+		},
+		ExecuteConnectionData: map[string]*proto.ExecuteConnectionData{
+			testConnectionName: {Limit: limit, CacheEnabled: false},
+		},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	stream := &testRowStream{ctx: ctx}
+	err := p.server.Execute(req, stream)
+	return stream.rows, err
+}
+
+// MustQuery executes the query and fails the test on error.
+func (p *testPlugin) MustQuery(q testQuery) []*proto.Row {
+	p.t.Helper()
+	rows, err := p.Query(q)
+	if err != nil {
+		p.t.Fatalf("query %s: %v", q.Table, err)
+	}
+	return rows
+}
+
+// testRowStream collects the rows sent by Execute. Only Send and Context are
+// used by the plugin SDK; the embedded interface is nil.
+type testRowStream struct {
+	proto.WrapperPlugin_ExecuteServer
+	ctx context.Context
+
+	mu   sync.Mutex
+	rows []*proto.Row
+}
+
+func (s *testRowStream) Send(r *proto.ExecuteResponse) error {
+	if r == nil || r.Row == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rows = append(s.rows, r.Row)
+	return nil
+}
+
+func (s *testRowStream) Context() context.Context {
+	return s.ctx
+}
+
+//// QUALS
+
+func qual(column, operator string, value *proto.QualValue) *proto.Qual {
+	return &proto.Qual{
+		FieldName: column,
+		Operator:  &proto.Qual_StringValue{StringValue: operator},
+		Value:     value,
+	}
+}
+
+func stringQualValue(value string) *proto.QualValue {
+	return &proto.QualValue{Value: &proto.QualValue_StringValue{StringValue: value}}
+}
+
+func timestampQualValue(value time.Time) *proto.QualValue {
+	return &proto.QualValue{Value: &proto.QualValue_TimestampValue{TimestampValue: timestamppb.New(value)}}
+}
+
+func listQualValue(values ...*proto.QualValue) *proto.QualValue {
+	return &proto.QualValue{Value: &proto.QualValue_ListValue{ListValue: &proto.QualValueList{Values: values}}}
+}
+
+//// ROWS
+
+// rowStrings returns the value of a string column for each row, sorted, as
+// the plugin SDK does not guarantee the order rows are streamed in.
+func rowStrings(rows []*proto.Row, column string) []string {
+	values := make([]string, 0, len(rows))
+	for _, row := range rows {
+		values = append(values, row.Columns[column].GetStringValue())
+	}
+	sort.Strings(values)
+	return values
+}
@@ -0,0 +1,1162 @@
+package pipes
+
+import (
+	"context"
+	"fmt"
+
+	openapi "github.com/turbot/pipes-sdk-go"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+)
+
+const (
+	identityTypeUser = "user"
+	identityTypeOrg  = "org"
+)
+
+// pipesIdentity is a user or org, resolved from either its handle or its id.
+type pipesIdentity struct {
+	Id     string
+	Handle string
+	Type   string
+}
+
+func (i *pipesIdentity) isUser() bool {
+	return i.Type == identityTypeUser
+}
+
+// IdentityDetails holds the identity columns hydrated for identity-scoped tables.
+type IdentityDetails struct {
+	IdentityHandle string `json:"identity_handle"`
+	IdentityType   string `json:"identity_type"`
+}
+
+// IdentityWorkspaceDetails holds the identity and workspace columns hydrated
+// for workspace-scoped tables.
+type IdentityWorkspaceDetails struct {
+	IdentityHandle  string `json:"identity_handle"`
+	IdentityType    string `json:"identity_type"`
+	WorkspaceHandle string `json:"workspace_handle"`
+}
+
+//// IDENTITY RESOLUTION
+
+// resolveIdentity resolves a user or org handle or id to a typed identity. The
+// result is cached for the query, so each identity is looked up once no
+// matter how many rows or hydrate calls need it, while a renamed or reused
+// handle is picked up by the next query.
+func resolveIdentity(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData, handleOrId string) (*pipesIdentity, error) {
+	identity, err := getQueryCached(d, "ResolveIdentity-"+handleOrId, func() (interface{}, error) {
+		return resolveIdentityUncached(ctx, d, h, handleOrId)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return identity.(*pipesIdentity), nil
+}
+
+func resolveIdentityUncached(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData, handleOrId string) (*pipesIdentity, error) {
+	commonData, err := getUserIdentity(ctx, d, h)
+	if err != nil {
+		plugin.Logger(ctx).Error("resolveIdentity", "getUserIdentity", err)
+		return nil, err
+	}
+
+	// The connection user needs no lookup
+	user := commonData.(openapi.User)
+	if handleOrId == user.Id || handleOrId == user.Handle {
+		return &pipesIdentity{Id: user.Id, Handle: user.Handle, Type: identityTypeUser}, nil
+	}
+
+	// Create Session
+	svc, err := connect(ctx, d)
+	if err != nil {
+		plugin.Logger(ctx).Error("resolveIdentity", "connection_error", err)
+		return nil, err
+	}
+
+	getDetails := func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+		resp, _, err := svc.Identities.Get(ctx, handleOrId).Execute()
+		return resp, classifyError(err)
+	}
+
+	response, err := plugin.RetryHydrate(ctx, d, h, getDetails, &plugin.RetryConfig{})
+	if err != nil {
+		plugin.Logger(ctx).Error("resolveIdentity", "get", err, "identity", handleOrId)
+		return nil, err
+	}
+
+	identity := response.(openapi.Identity)
+	if identity.Type != identityTypeUser && identity.Type != identityTypeOrg {
+		return nil, fmt.Errorf("identity %s has unsupported type %q", handleOrId, identity.Type)
+	}
+
+	return &pipesIdentity{Id: identity.Id, Handle: identity.Handle, Type: identity.Type}, nil
+}
+
+// identityFromQuals resolves the identity named by the identity_id and/or
+// identity_handle quals. It returns nil if neither qual is set, or if both are
+// set but refer to different identities.
+func identityFromQuals(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (*pipesIdentity, error) {
+	identityId := d.EqualsQualString("identity_id")
+	identityHandle := d.EqualsQualString("identity_handle")
+
+	handleOrId := identityId
+	if handleOrId == "" {
+		handleOrId = identityHandle
+	}
+	if handleOrId == "" {
+		return nil, nil
+	}
+
+	identity, err := resolveIdentity(ctx, d, h, handleOrId)
+	if err != nil {
+		return nil, err
+	}
+	if identityHandle != "" && identityHandle != identity.Handle {
+		return nil, nil
+	}
+	return identity, nil
+}
+
+// identitiesFromQuals returns the identities to list for tables that span
+// every identity the connection user can access. If the identity_id or
+// identity_handle qual is set only that identity is returned, otherwise the
+// connection user and each org they are a member of.
+func identitiesFromQuals(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) ([]*pipesIdentity, error) {
+	if d.EqualsQualString("identity_id") != "" || d.EqualsQualString("identity_handle") != "" {
+		identity, err := identityFromQuals(ctx, d, h)
+		if err != nil || identity == nil {
+			return nil, err
+		}
+		return []*pipesIdentity{identity}, nil
+	}
+
+	commonData, err := getUserIdentity(ctx, d, h)
+	if err != nil {
+		plugin.Logger(ctx).Error("identitiesFromQuals", "getUserIdentity", err)
+		return nil, err
+	}
+	user := commonData.(openapi.User)
+	identities := []*pipesIdentity{{Id: user.Id, Handle: user.Handle, Type: identityTypeUser}}
+
+	// Create Session
+	svc, err := connect(ctx, d)
+	if err != nil {
+		plugin.Logger(ctx).Error("identitiesFromQuals", "connection_error", err)
+		return nil, err
+	}
+
+	userOrgs, err := listAll(ctx, d, func(ctx context.Context, nextToken *string, limit int32) ([]openapi.UserOrg, *string, error) {
+		req := svc.Actors.ListOrgs(ctx).Limit(limit)
+		if nextToken != nil {
+			req = req.NextToken(*nextToken)
+		}
+		resp, _, err := req.Execute()
+		return resp.GetItems(), resp.NextToken, err
+	})
+	if err != nil {
+		plugin.Logger(ctx).Error("identitiesFromQuals", "list_orgs", err)
+		return nil, err
+	}
+	for _, userOrg := range userOrgs {
+		if userOrg.Org == nil {
+			continue
+		}
+		identities = append(identities, &pipesIdentity{Id: userOrg.Org.Id, Handle: userOrg.Org.Handle, Type: identityTypeOrg})
+	}
+	return identities, nil
+}
+
+// getIdentityDetailsForId returns the identity columns for the identity with
+// the given id.
+func getIdentityDetailsForId(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData, identityId string) (*IdentityDetails, error) {
+	identity, err := resolveIdentity(ctx, d, h, identityId)
+	if err != nil {
+		return nil, err
+	}
+	return &IdentityDetails{IdentityHandle: identity.Handle, IdentityType: identity.Type}, nil
+}
+
+//// WORKSPACE HELPERS
+
+// parentWorkspace returns the workspace streamed by the listWorkspaces parent
+// hydrate. The actor list streams pointers while the user and org lists stream
+// values, so both are accepted. It returns nil for anything else, e.g. when a
+// get call was made and no parent hydrate ran.
+func parentWorkspace(item interface{}) *openapi.Workspace {
+	switch w := item.(type) {
+	case openapi.Workspace:
+		return &w
+	case *openapi.Workspace:
+		return w
+	}
+	return nil
+}
+
+// workspaceMatchesQuals reports whether the workspace matches the
+// workspace_id and workspace_handle quals, so child list calls can skip the
+// workspaces of the parent list that were not asked for.
+func workspaceMatchesQuals(d *plugin.QueryData, workspace *openapi.Workspace) bool {
+	if workspaceId := d.EqualsQualString("workspace_id"); workspaceId != "" && workspaceId != workspace.Id {
+		return false
+	}
+	if workspaceHandle := d.EqualsQualString("workspace_handle"); workspaceHandle != "" && workspaceHandle != workspace.Handle {
+		return false
+	}
+	return true
+}
+
+// getIdentityWorkspaceDetailsForIds returns the identity and workspace columns
+// for a row of a workspace-scoped table. The parent workspace is used when the
+// row came from a list call; for get calls the workspace is looked up by id.
+func getIdentityWorkspaceDetailsForIds(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData, identityId, workspaceId string) (*IdentityWorkspaceDetails, error) {
+	workspace := parentWorkspace(h.ParentItem)
+	if workspace != nil {
+		identityId = workspace.IdentityId
+	}
+
+	identity, err := resolveIdentity(ctx, d, h, identityId)
+	if err != nil {
+		return nil, err
+	}
+
+	details := &IdentityWorkspaceDetails{IdentityHandle: identity.Handle, IdentityType: identity.Type}
+	if workspace != nil {
+		details.WorkspaceHandle = workspace.Handle
+		return details, nil
+	}
+
+	api, err := newIdentityService(ctx, d, identity)
+	if err != nil {
+		return nil, err
+	}
+
+	getDetails := func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+		return api.GetWorkspace(ctx, workspaceId)
+	}
+
+	response, err := plugin.RetryHydrate(ctx, d, h, getDetails, &plugin.RetryConfig{})
+	if err != nil {
+		plugin.Logger(ctx).Error("getIdentityWorkspaceDetailsForIds", "get", err)
+		return nil, err
+	}
+
+	details.WorkspaceHandle = response.(openapi.Workspace).Handle
+	return details, nil
+}
+
+//// IDENTITY SERVICE
+
+// identityService hides the split between the User* and Org* services of the
+// Pipes API. Each method routes to the service matching the identity the
+// service was created for, so tables need a single implementation for both.
+type identityService interface {
+	Identity() *pipesIdentity
+
+	ListWorkspaces() listPageFunc[openapi.Workspace]
+	GetWorkspace(ctx context.Context, workspaceHandle string) (openapi.Workspace, error)
+	CreateWorkspace(ctx context.Context, request openapi.CreateWorkspaceRequest) (openapi.Workspace, error)
+
+	ListAuditLogs(filter string) listPageFunc[openapi.AuditRecord]
+
+	ListBillingSubscriptions() listPageFunc[openapi.BillingSubscription]
+
+	ListConnections() listPageFunc[openapi.Connection]
+	GetConnection(ctx context.Context, connectionHandle string) (openapi.Connection, error)
+
+	ListConnectionFolders() listPageFunc[openapi.Connection]
+
+	ListIntegrations() listPageFunc[openapi.Integration]
+	GetIntegration(ctx context.Context, integrationHandle string) (openapi.Integration, error)
+
+	ListNotifiers() listPageFunc[openapi.Notifier]
+	GetNotifier(ctx context.Context, notifierName string) (openapi.Notifier, error)
+
+	ListProcesses() listPageFunc[openapi.SpProcess]
+	GetProcess(ctx context.Context, processId string) (openapi.SpProcess, error)
+
+	ListUsage(filter string) listPageFunc[openapi.UsageMetric]
+
+	ListWorkspaceAggregators(workspaceHandle string) listPageFunc[openapi.WorkspaceAggregator]
+	GetWorkspaceAggregator(ctx context.Context, workspaceHandle, aggregatorHandle string) (openapi.Aggregator, error)
+
+	ListWorkspaceConnectionAssociations(workspaceHandle string) listPageFunc[openapi.WorkspaceConn]
+	ListWorkspaceConnectionFolders(workspaceHandle string) listPageFunc[openapi.Connection]
+
+	ListWorkspaceDatatanks(workspaceHandle string) listPageFunc[openapi.Datatank]
+	GetWorkspaceDatatank(ctx context.Context, workspaceHandle, datatankHandle string) (openapi.Datatank, error)
+
+	ListWorkspaceDatatankTables(workspaceHandle, datatankHandle string) listPageFunc[openapi.DatatankTable]
+	GetWorkspaceDatatankTable(ctx context.Context, workspaceHandle, datatankHandle, tableName string) (openapi.DatatankTable, error)
+
+	ListWorkspaceDBLogs(workspaceHandle, filter string) listPageFunc[openapi.LogRecord]
+
+	ListWorkspaceFlowpipeTriggers(workspaceHandle string) listPageFunc[openapi.ModTriggerInfo]
+	GetWorkspaceFlowpipeTrigger(ctx context.Context, workspaceHandle, triggerName string) (openapi.WorkspaceModTrigger, error)
+
+	ListWorkspaceIntegrations(workspaceHandle string) listPageFunc[openapi.Integration]
+	GetWorkspaceIntegration(ctx context.Context, workspaceHandle, integrationHandle string) (openapi.Integration, error)
+
+	ListWorkspaceMods(workspaceHandle string) listPageFunc[openapi.WorkspaceMod]
+	GetWorkspaceMod(ctx context.Context, workspaceHandle, modAlias string) (openapi.WorkspaceMod, error)
+
+	ListWorkspaceModVariables(workspaceHandle, modAlias string) listPageFunc[openapi.WorkspaceModVariable]
+
+	ListWorkspaceNotifiers(workspaceHandle string) listPageFunc[openapi.Notifier]
+	GetWorkspaceNotifier(ctx context.Context, workspaceHandle, notifierName string) (openapi.Notifier, error)
+
+	ListWorkspacePipelines(workspaceHandle, filter string) listPageFunc[openapi.Pipeline]
+	GetWorkspacePipeline(ctx context.Context, workspaceHandle, pipelineId string) (openapi.Pipeline, error)
+	RunWorkspacePipeline(ctx context.Context, workspaceHandle, pipelineId string, args map[string]interface{}) (openapi.PipelineCommandResponse, error)
+
+	ListWorkspaceProcesses(workspaceHandle, filter string) listPageFunc[openapi.SpProcess]
+	GetWorkspaceProcess(ctx context.Context, workspaceHandle, processId string) (openapi.SpProcess, error)
+
+	ListWorkspaceSchemas(workspaceHandle string) listPageFunc[openapi.WorkspaceSchema]
+	GetWorkspaceSchema(ctx context.Context, workspaceHandle, schemaName string) (openapi.WorkspaceSchema, error)
+
+	ListWorkspaceSchemaTables(workspaceHandle, schemaName string) listPageFunc[openapi.WorkspaceSchemaTable]
+	GetWorkspaceSchemaTable(ctx context.Context, workspaceHandle, schemaName, tableName string) (openapi.WorkspaceSchemaTable, error)
+
+	ListWorkspaceSnapshots(workspaceHandle, filter string) listPageFunc[openapi.WorkspaceSnapshot]
+	GetWorkspaceSnapshot(ctx context.Context, workspaceHandle, snapshotId string) (openapi.WorkspaceSnapshot, error)
+	DownloadWorkspaceSnapshot(ctx context.Context, workspaceHandle, snapshotId, contentType string) (openapi.WorkspaceSnapshotData, error)
+	DownloadWorkspaceSnapshotFile(ctx context.Context, workspaceHandle, snapshotId, format string) ([]byte, error)
+	DeleteWorkspaceSnapshot(ctx context.Context, workspaceHandle, snapshotId string) (openapi.WorkspaceSnapshot, error)
+
+	ListWorkspaceUsage(workspaceHandle, filter string) listPageFunc[openapi.UsageMetric]
+}
+
+// getIdentityService resolves a user or org handle or id and returns the
+// identityService for it.
+func getIdentityService(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData, handleOrId string) (identityService, error) {
+	identity, err := resolveIdentity(ctx, d, h, handleOrId)
+	if err != nil {
+		return nil, err
+	}
+	return newIdentityService(ctx, d, identity)
+}
+
+func newIdentityService(ctx context.Context, d *plugin.QueryData, identity *pipesIdentity) (identityService, error) {
+	// Create Session
+	svc, err := connect(ctx, d)
+	if err != nil {
+		plugin.Logger(ctx).Error("newIdentityService", "connection_error", err)
+		return nil, err
+	}
+
+	if identity.isUser() {
+		return &userIdentityService{svc: svc, identity: identity}, nil
+	}
+	return &orgIdentityService{svc: svc, identity: identity}, nil
+}
+
+//// USER IDENTITY SERVICE
+
+type userIdentityService struct {
+	svc      *openapi.APIClient
+	identity *pipesIdentity
+}
+
+func (s *userIdentityService) Identity() *pipesIdentity {
+	return s.identity
+}
+
+func (s *userIdentityService) ListWorkspaces() listPageFunc[openapi.Workspace] {
+	return func(ctx context.Context, nextToken *string, limit int32) ([]openapi.Workspace, *string, error) {
+		req := s.svc.UserWorkspaces.List(ctx, s.identity.Handle).Limit(limit)
+		if nextToken != nil {
+			req = req.NextToken(*nextToken)
+		}
+		resp, _, err := req.Execute()
+		return resp.GetItems(), resp.NextToken, err
+	}
+}
+
+func (s *userIdentityService) GetWorkspace(ctx context.Context, workspaceHandle string) (openapi.Workspace, error) {
+	resp, _, err := s.svc.UserWorkspaces.Get(ctx, s.identity.Handle, workspaceHandle).Execute()
+	return resp, classifyError(err)
+}
+
+func (s *userIdentityService) CreateWorkspace(ctx context.Context, request openapi.CreateWorkspaceRequest) (openapi.Workspace, error) {
+	resp, _, err := s.svc.UserWorkspaces.Create(ctx, s.identity.Handle).Request(request).Execute()
+	return resp, classifyError(err)
+}
+
+func (s *userIdentityService) ListAuditLogs(filter string) listPageFunc[openapi.AuditRecord] {
+	return func(ctx context.Context, nextToken *string, limit int32) ([]openapi.AuditRecord, *string, error) {
+		// The API client has no option for the audit log filter
+		req := s.svc.Users.ListAuditLogs(withQueryFilter(ctx, filter), s.identity.Handle).Limit(limit)
+		if nextToken != nil {
+			req = req.NextToken(*nextToken)
+		}
+		resp, _, err := req.Execute()
+		return resp.GetItems(), resp.NextToken, err
+	}
+}
+
+func (s *userIdentityService) ListBillingSubscriptions() listPageFunc[openapi.BillingSubscription] {
+	// The subscriptions are returned in a single page
+	return func(ctx context.Context, nextToken *string, limit int32) ([]openapi.BillingSubscription, *string, error) {
+		resp, _, err := s.svc.UserBilling.ListUserSubscription(ctx, s.identity.Handle).Execute()
+		return resp.GetItems(), nil, err
+	}
+}
+
+func (s *userIdentityService) ListConnections() listPageFunc[openapi.Connection] {
+	return func(ctx context.Context, nextToken *string, limit int32) ([]openapi.Connection, *string, error) {
+		req := s.svc.UserConnections.List(ctx, s.identity.Handle).Limit(limit)
+		if nextToken != nil {
+			req = req.NextToken(*nextToken)
+		}
+		resp, _, err := req.Execute()
+		return resp.GetItems(), resp.NextToken, err
+	}
+}
+
+func (s *userIdentityService) GetConnection(ctx context.Context, connectionHandle string) (openapi.Connection, error) {
+	resp, _, err := s.svc.UserConnections.Get(ctx, s.identity.Handle, connectionHandle).Execute()
+	return resp, classifyError(err)
+}
+
+func (s *userIdentityService) ListConnectionFolders() listPageFunc[openapi.Connection] {
+	// Connection folders can only be created in orgs and workspaces
+	return func(ctx context.Context, nextToken *string, limit int32) ([]openapi.Connection, *string, error) {
+		return nil, nil, nil
+	}
+}
+
+func (s *userIdentityService) ListIntegrations() listPageFunc[openapi.Integration] {
+	return func(ctx context.Context, nextToken *string, limit int32) ([]openapi.Integration, *string, error) {
+		req := s.svc.UserIntegrations.List(ctx, s.identity.Handle).Limit(limit)
+		if nextToken != nil {
+			req = req.NextToken(*nextToken)
+		}
+		resp, _, err := req.Execute()
+		return resp.GetItems(), resp.NextToken, err
+	}
+}
+
+func (s *userIdentityService) GetIntegration(ctx context.Context, integrationHandle string) (openapi.Integration, error) {
+	resp, _, err := s.svc.UserIntegrations.Get(ctx, s.identity.Handle, integrationHandle).Execute()
+	return resp, classifyError(err)
+}
+
+func (s *userIdentityService) ListNotifiers() listPageFunc[openapi.Notifier] {
+	return func(ctx context.Context, nextToken *string, limit int32) ([]openapi.Notifier, *string, error) {
+		req := s.svc.UserNotifiers.List(ctx, s.identity.Handle).Limit(limit)
+		if nextToken != nil {
+			req = req.NextToken(*nextToken)
+		}
+		resp, _, err := req.Execute()
+		return resp.GetItems(), resp.NextToken, err
+	}
+}
+
+func (s *userIdentityService) GetNotifier(ctx context.Context, notifierName string) (openapi.Notifier, error) {
+	resp, _, err := s.svc.UserNotifiers.Get(ctx, s.identity.Handle, notifierName).Execute()
+	return resp, classifyError(err)
+}
+
+func (s *userIdentityService) ListProcesses() listPageFunc[openapi.SpProcess] {
+	return func(ctx context.Context, nextToken *string, limit int32) ([]openapi.SpProcess, *string, error) {
+		req := s.svc.UserProcesses.List(ctx, s.identity.Handle).Limit(limit)
+		if nextToken != nil {
+			req = req.NextToken(*nextToken)
+		}
+		resp, _, err := req.Execute()
+		return resp.GetItems(), resp.NextToken, err
+	}
+}
+
+func (s *userIdentityService) GetProcess(ctx context.Context, processId string) (openapi.SpProcess, error) {
+	resp, _, err := s.svc.UserProcesses.Get(ctx, s.identity.Handle, processId).Execute()
+	return resp, classifyError(err)
+}
+
+func (s *userIdentityService) ListUsage(filter string) listPageFunc[openapi.UsageMetric] {
+	return func(ctx context.Context, nextToken *string, limit int32) ([]openapi.UsageMetric, *string, error) {
+		req := s.svc.Users.ListUsage(ctx, s.identity.Handle).Where(filter).Limit(limit)
+		if nextToken != nil {
+			req = req.NextToken(*nextToken)
+		}
+		resp, _, err := req.Execute()
+		return resp.GetItems(), resp.NextToken, err
+	}
+}
+
+func (s *userIdentityService) ListWorkspaceAggregators(workspaceHandle string) listPageFunc[openapi.WorkspaceAggregator] {
+	return func(ctx context.Context, nextToken *string, limit int32) ([]openapi.WorkspaceAggregator, *string, error) {
+		req := s.svc.UserWorkspaceAggregators.List(ctx, s.identity.Handle, workspaceHandle).Limit(limit)
+		if nextToken != nil {
+			req = req.NextToken(*nextToken)
+		}
+		resp, _, err := req.Execute()
+		return resp.GetItems(), resp.NextToken, err
+	}
+}
+
+func (s *userIdentityService) GetWorkspaceAggregator(ctx context.Context, workspaceHandle, aggregatorHandle string) (openapi.Aggregator, error) {
+	resp, _, err := s.svc.UserWorkspaceAggregators.Get(ctx, s.identity.Handle, workspaceHandle, aggregatorHandle).Execute()
+	return resp, classifyError(err)
+}
+
+func (s *userIdentityService) ListWorkspaceConnectionAssociations(workspaceHandle string) listPageFunc[openapi.WorkspaceConn] {
+	return func(ctx context.Context, nextToken *string, limit int32) ([]openapi.WorkspaceConn, *string, error) {
+		req := s.svc.UserWorkspaceConnectionAssociations.List(ctx, s.identity.Handle, workspaceHandle).Limit(limit)
+		if nextToken != nil {
+			req = req.NextToken(*nextToken)
+		}
+		resp, _, err := req.Execute()
+		return resp.GetItems(), resp.NextToken, err
+	}
+}
+
+func (s *userIdentityService) ListWorkspaceConnectionFolders(workspaceHandle string) listPageFunc[openapi.Connection] {
+	return func(ctx context.Context, nextToken *string, limit int32) ([]openapi.Connection, *string, error) {
+		req := s.svc.UserWorkspaceConnectionFolders.List(ctx, s.identity.Handle, workspaceHandle).Limit(limit)
+		if nextToken != nil {
+			req = req.NextToken(*nextToken)
+		}
+		resp, _, err := req.Execute()
+		return resp.GetItems(), resp.NextToken, err
+	}
+}
+
+func (s *userIdentityService) ListWorkspaceDatatanks(workspaceHandle string) listPageFunc[openapi.Datatank] {
+	return func(ctx context.Context, nextToken *string, limit int32) ([]openapi.Datatank, *string, error) {
+		req := s.svc.UserWorkspaceDatatanks.List(ctx, s.identity.Handle, workspaceHandle).Limit(limit)
+		if nextToken != nil {
+			req = req.NextToken(*nextToken)
+		}
+		resp, _, err := req.Execute()
+		return resp.GetItems(), resp.NextToken, err
+	}
+}
+
+func (s *userIdentityService) GetWorkspaceDatatank(ctx context.Context, workspaceHandle, datatankHandle string) (openapi.Datatank, error) {
+	resp, _, err := s.svc.UserWorkspaceDatatanks.Get(ctx, s.identity.Handle, workspaceHandle, datatankHandle).Execute()
+	return resp, classifyError(err)
+}
+
+func (s *userIdentityService) ListWorkspaceDatatankTables(workspaceHandle, datatankHandle string) listPageFunc[openapi.DatatankTable] {
+	return func(ctx context.Context, nextToken *string, limit int32) ([]openapi.DatatankTable, *string, error) {
+		req := s.svc.UserWorkspaceDatatankTables.List(ctx, s.identity.Handle, workspaceHandle, datatankHandle).Limit(limit)
+		if nextToken != nil {
+			req = req.NextToken(*nextToken)
+		}
+		resp, _, err := req.Execute()
+		return resp.GetItems(), resp.NextToken, err
+	}
+}
+
+func (s *userIdentityService) GetWorkspaceDatatankTable(ctx context.Context, workspaceHandle, datatankHandle, tableName string) (openapi.DatatankTable, error) {
+	resp, _, err := s.svc.UserWorkspaceDatatankTables.Get(ctx, s.identity.Handle, workspaceHandle, datatankHandle, tableName).Execute()
+	return resp, classifyError(err)
+}
+
+func (s *userIdentityService) ListWorkspaceDBLogs(workspaceHandle, filter string) listPageFunc[openapi.LogRecord] {
+	return func(ctx context.Context, nextToken *string, limit int32) ([]openapi.LogRecord, *string, error) {
+		// The API client has no option for the db log filter
+		req := s.svc.UserWorkspaces.ListDBLogs(withQueryFilter(ctx, filter), s.identity.Handle, workspaceHandle).Limit(limit)
+		if nextToken != nil {
+			req = req.NextToken(*nextToken)
+		}
+		resp, _, err := req.Execute()
+		return resp.GetItems(), resp.NextToken, err
+	}
+}
+
+func (s *userIdentityService) ListWorkspaceFlowpipeTriggers(workspaceHandle string) listPageFunc[openapi.ModTriggerInfo] {
+	return func(ctx context.Context, nextToken *string, limit int32) ([]openapi.ModTriggerInfo, *string, error) {
+		req := s.svc.UserWorkspaceFlowpipeTriggers.List(ctx, s.identity.Handle, workspaceHandle).Limit(limit)
+		if nextToken != nil {
+			req = req.NextToken(*nextToken)
+		}
+		resp, _, err := req.Execute()
+		return resp.GetItems(), resp.NextToken, err
+	}
+}
+
+func (s *userIdentityService) GetWorkspaceFlowpipeTrigger(ctx context.Context, workspaceHandle, triggerName string) (openapi.WorkspaceModTrigger, error) {
+	resp, _, err := s.svc.UserWorkspaceFlowpipeTriggers.Get(ctx, s.identity.Handle, workspaceHandle, triggerName).Execute()
+	return resp, classifyError(err)
+}
+
+func (s *userIdentityService) ListWorkspaceIntegrations(workspaceHandle string) listPageFunc[openapi.Integration] {
+	return func(ctx context.Context, nextToken *string, limit int32) ([]openapi.Integration, *string, error) {
+		req := s.svc.UserWorkspaceIntegrations.List(ctx, s.identity.Handle, workspaceHandle).Limit(limit)
+		if nextToken != nil {
+			req = req.NextToken(*nextToken)
+		}
+		resp, _, err := req.Execute()
+		return resp.GetItems(), resp.NextToken, err
+	}
+}
+
+func (s *userIdentityService) GetWorkspaceIntegration(ctx context.Context, workspaceHandle, integrationHandle string) (openapi.Integration, error) {
+	resp, _, err := s.svc.UserWorkspaceIntegrations.Get(ctx, s.identity.Handle, workspaceHandle, integrationHandle).Execute()
+	return resp, classifyError(err)
+}
+
+func (s *userIdentityService) ListWorkspaceMods(workspaceHandle string) listPageFunc[openapi.WorkspaceMod] {
+	return func(ctx context.Context, nextToken *string, limit int32) ([]openapi.WorkspaceMod, *string, error) {
+		req := s.svc.UserWorkspaceMods.List(ctx, s.identity.Handle, workspaceHandle).Limit(limit)
+		if nextToken != nil {
+			req = req.NextToken(*nextToken)
+		}
+		resp, _, err := req.Execute()
+		return resp.GetItems(), resp.NextToken, err
+	}
+}
+
+func (s *userIdentityService) GetWorkspaceMod(ctx context.Context, workspaceHandle, modAlias string) (openapi.WorkspaceMod, error) {
+	resp, _, err := s.svc.UserWorkspaceMods.Get(ctx, s.identity.Handle, workspaceHandle, modAlias).Execute()
+	return resp, classifyError(err)
+}
+
+func (s *userIdentityService) ListWorkspaceModVariables(workspaceHandle, modAlias string) listPageFunc[openapi.WorkspaceModVariable] {
+	return func(ctx context.Context, nextToken *string, limit int32) ([]openapi.WorkspaceModVariable, *string, error) {
+		req := s.svc.UserWorkspaceModVariables.List(ctx, s.identity.Handle, workspaceHandle, modAlias).Limit(limit)
+		if nextToken != nil {
+			req = req.NextToken(*nextToken)
+		}
+		resp, _, err := req.Execute()
+		return resp.GetItems(), resp.NextToken, err
+	}
+}
+
+func (s *userIdentityService) ListWorkspaceNotifiers(workspaceHandle string) listPageFunc[openapi.Notifier] {
+	return func(ctx context.Context, nextToken *string, limit int32) ([]openapi.Notifier, *string, error) {
+		req := s.svc.UserWorkspaceNotifiers.List(ctx, s.identity.Handle, workspaceHandle).Limit(limit)
+		if nextToken != nil {
+			req = req.NextToken(*nextToken)
+		}
+		resp, _, err := req.Execute()
+		return resp.GetItems(), resp.NextToken, err
+	}
+}
+
+func (s *userIdentityService) GetWorkspaceNotifier(ctx context.Context, workspaceHandle, notifierName string) (openapi.Notifier, error) {
+	resp, _, err := s.svc.UserWorkspaceNotifiers.Get(ctx, s.identity.Handle, workspaceHandle, notifierName).Execute()
+	return resp, classifyError(err)
+}
+
+func (s *userIdentityService) ListWorkspacePipelines(workspaceHandle, filter string) listPageFunc[openapi.Pipeline] {
+	return func(ctx context.Context, nextToken *string, limit int32) ([]openapi.Pipeline, *string, error) {
+		req := s.svc.UserWorkspacePipelines.List(ctx, s.identity.Handle, workspaceHandle).Where(filter).Limit(limit)
+		if nextToken != nil {
+			req = req.NextToken(*nextToken)
+		}
+		resp, _, err := req.Execute()
+		return resp.GetItems(), resp.NextToken, err
+	}
+}
+
+func (s *userIdentityService) GetWorkspacePipeline(ctx context.Context, workspaceHandle, pipelineId string) (openapi.Pipeline, error) {
+	resp, _, err := s.svc.UserWorkspacePipelines.Get(ctx, s.identity.Handle, workspaceHandle, pipelineId).Execute()
+	return resp, classifyError(err)
+}
+
+func (s *userIdentityService) RunWorkspacePipeline(ctx context.Context, workspaceHandle, pipelineId string, args map[string]interface{}) (openapi.PipelineCommandResponse, error) {
+	request := openapi.PipelineCommandRequest{Command: openapi.PipelineCommandRun}
+	if args != nil {
+		request.Args = &args
+	}
+	resp, _, err := s.svc.UserWorkspacePipelines.Command(ctx, s.identity.Handle, workspaceHandle, pipelineId).Request(request).Execute()
+	return resp, classifyError(err)
+}
+
+func (s *userIdentityService) ListWorkspaceProcesses(workspaceHandle, filter string) listPageFunc[openapi.SpProcess] {
+	return func(ctx context.Context, nextToken *string, limit int32) ([]openapi.SpProcess, *string, error) {
+		req := s.svc.UserWorkspaceProcesses.List(ctx, s.identity.Handle, workspaceHandle).Where(filter).Limit(limit)
+		if nextToken != nil {
+			req = req.NextToken(*nextToken)
+		}
+		resp, _, err := req.Execute()
+		return resp.GetItems(), resp.NextToken, err
+	}
+}
+
+func (s *userIdentityService) GetWorkspaceProcess(ctx context.Context, workspaceHandle, processId string) (openapi.SpProcess, error) {
+	resp, _, err := s.svc.UserWorkspaceProcesses.Get(ctx, s.identity.Handle, workspaceHandle, processId).Execute()
+	return resp, classifyError(err)
+}
+
+func (s *userIdentityService) ListWorkspaceSchemas(workspaceHandle string) listPageFunc[openapi.WorkspaceSchema] {
+	return func(ctx context.Context, nextToken *string, limit int32) ([]openapi.WorkspaceSchema, *string, error) {
+		req := s.svc.UserWorkspaceSchemas.List(ctx, s.identity.Handle, workspaceHandle).Limit(limit)
+		if nextToken != nil {
+			req = req.NextToken(*nextToken)
+		}
+		resp, _, err := req.Execute()
+		return resp.GetItems(), resp.NextToken, err
+	}
+}
+
+func (s *userIdentityService) GetWorkspaceSchema(ctx context.Context, workspaceHandle, schemaName string) (openapi.WorkspaceSchema, error) {
+	resp, _, err := s.svc.UserWorkspaceSchemas.Get(ctx, s.identity.Handle, workspaceHandle, schemaName).Execute()
+	return resp, classifyError(err)
+}
+
+func (s *userIdentityService) ListWorkspaceSchemaTables(workspaceHandle, schemaName string) listPageFunc[openapi.WorkspaceSchemaTable] {
+	// The tables of a schema are returned in a single page
+	return func(ctx context.Context, nextToken *string, limit int32) ([]openapi.WorkspaceSchemaTable, *string, error) {
+		resp, _, err := s.svc.UserWorkspaceSchemas.ListTables(ctx, s.identity.Handle, workspaceHandle, schemaName).Execute()
+		return resp.GetItems(), nil, err
+	}
+}
+
+func (s *userIdentityService) GetWorkspaceSchemaTable(ctx context.Context, workspaceHandle, schemaName, tableName string) (openapi.WorkspaceSchemaTable, error) {
+	resp, _, err := s.svc.UserWorkspaceSchemas.GetTable(ctx, s.identity.Handle, workspaceHandle, schemaName, tableName).Execute()
+	return resp, classifyError(err)
+}
+
+func (s *userIdentityService) ListWorkspaceSnapshots(workspaceHandle, filter string) listPageFunc[openapi.WorkspaceSnapshot] {
+	return func(ctx context.Context, nextToken *string, limit int32) ([]openapi.WorkspaceSnapshot, *string, error) {
+		req := s.svc.UserWorkspaceSnapshots.List(ctx, s.identity.Handle, workspaceHandle).Where(filter).Limit(limit)
+		if nextToken != nil {
+			req = req.NextToken(*nextToken)
+		}
+		resp, _, err := req.Execute()
+		return resp.GetItems(), resp.NextToken, err
+	}
+}
+
+func (s *userIdentityService) GetWorkspaceSnapshot(ctx context.Context, workspaceHandle, snapshotId string) (openapi.WorkspaceSnapshot, error) {
+	resp, _, err := s.svc.UserWorkspaceSnapshots.Get(ctx, s.identity.Handle, workspaceHandle, snapshotId).Execute()
+	return resp, classifyError(err)
+}
+
+func (s *userIdentityService) DownloadWorkspaceSnapshot(ctx context.Context, workspaceHandle, snapshotId, contentType string) (openapi.WorkspaceSnapshotData, error) {
+	resp, _, err := s.svc.UserWorkspaceSnapshots.Download(ctx, s.identity.Handle, workspaceHandle, snapshotId, contentType).Execute()
+	return resp, classifyError(err)
+}
+
+func (s *userIdentityService) DownloadWorkspaceSnapshotFile(ctx context.Context, workspaceHandle, snapshotId, format string) ([]byte, error) {
+	_, resp, err := s.svc.UserWorkspaceSnapshots.Download(ctx, s.identity.Handle, workspaceHandle, snapshotId, format).Execute()
+	return snapshotFileBody(resp, err)
+}
+
+func (s *userIdentityService) DeleteWorkspaceSnapshot(ctx context.Context, workspaceHandle, snapshotId string) (openapi.WorkspaceSnapshot, error) {
+	resp, _, err := s.svc.UserWorkspaceSnapshots.Delete(ctx, s.identity.Handle, workspaceHandle, snapshotId).Execute()
+	return resp, classifyError(err)
+}
+
+func (s *userIdentityService) ListWorkspaceUsage(workspaceHandle, filter string) listPageFunc[openapi.UsageMetric] {
+	return func(ctx context.Context, nextToken *string, limit int32) ([]openapi.UsageMetric, *string, error) {
+		req := s.svc.UserWorkspaceUsages.List(ctx, s.identity.Handle, workspaceHandle).Where(filter).Limit(limit)
+		if nextToken != nil {
+			req = req.NextToken(*nextToken)
+		}
+		resp, _, err := req.Execute()
+		return resp.GetItems(), resp.NextToken, err
+	}
+}
+
+//// ORG IDENTITY SERVICE
+
+type orgIdentityService struct {
+	svc      *openapi.APIClient
+	identity *pipesIdentity
+}
+
+func (s *orgIdentityService) Identity() *pipesIdentity {
+	return s.identity
+}
+
+func (s *orgIdentityService) ListWorkspaces() listPageFunc[openapi.Workspace] {
+	return func(ctx context.Context, nextToken *string, limit int32) ([]openapi.Workspace, *string, error) {
+		req := s.svc.OrgWorkspaces.List(ctx, s.identity.Handle).Limit(limit)
+		if nextToken != nil {
+			req = req.NextToken(*nextToken)
+		}
+		resp, _, err := req.Execute()
+		return resp.GetItems(), resp.NextToken, err
+	}
+}
+
+func (s *orgIdentityService) GetWorkspace(ctx context.Context, workspaceHandle string) (openapi.Workspace, error) {
+	resp, _, err := s.svc.OrgWorkspaces.Get(ctx, s.identity.Handle, workspaceHandle).Execute()
+	return resp, classifyError(err)
+}
+
+func (s *orgIdentityService) CreateWorkspace(ctx context.Context, request openapi.CreateWorkspaceRequest) (openapi.Workspace, error) {
+	resp, _, err := s.svc.OrgWorkspaces.Create(ctx, s.identity.Handle).Request(request).Execute()
+	return resp, classifyError(err)
+}
+
+func (s *orgIdentityService) ListAuditLogs(filter string) listPageFunc[openapi.AuditRecord] {
+	return func(ctx context.Context, nextToken *string, limit int32) ([]openapi.AuditRecord, *string, error) {
+		// The API client has no option for the audit log filter
+		req := s.svc.Orgs.ListAuditLogs(withQueryFilter(ctx, filter), s.identity.Handle).Limit(limit)
+		if nextToken != nil {
+			req = req.NextToken(*nextToken)
+		}
+		resp, _, err := req.Execute()
+		return resp.GetItems(), resp.NextToken, err
+	}
+}
+
+func (s *orgIdentityService) ListBillingSubscriptions() listPageFunc[openapi.BillingSubscription] {
+	// The subscriptions are returned in a single page
+	return func(ctx context.Context, nextToken *string, limit int32) ([]openapi.BillingSubscription, *string, error) {
+		resp, _, err := s.svc.OrgBilling.ListOrgSubscription(ctx, s.identity.Handle).Execute()
+		return resp.GetItems(), nil, err
+	}
+}
+
+func (s *orgIdentityService) ListConnections() listPageFunc[openapi.Connection] {
+	return func(ctx context.Context, nextToken *string, limit int32) ([]openapi.Connection, *string, error) {
+		req := s.svc.OrgConnections.List(ctx, s.identity.Handle).Limit(limit)
+		if nextToken != nil {
+			req = req.NextToken(*nextToken)
+		}
+		resp, _, err := req.Execute()
+		return resp.GetItems(), resp.NextToken, err
+	}
+}
+
+func (s *orgIdentityService) GetConnection(ctx context.Context, connectionHandle string) (openapi.Connection, error) {
+	resp, _, err := s.svc.OrgConnections.Get(ctx, s.identity.Handle, connectionHandle).Execute()
+	return resp, classifyError(err)
+}
+
+func (s *orgIdentityService) ListConnectionFolders() listPageFunc[openapi.Connection] {
+	return func(ctx context.Context, nextToken *string, limit int32) ([]openapi.Connection, *string, error) {
+		req := s.svc.OrgConnectionFolders.List(ctx, s.identity.Handle).Limit(limit)
+		if nextToken != nil {
+			req = req.NextToken(*nextToken)
+		}
+		resp, _, err := req.Execute()
+		return resp.GetItems(), resp.NextToken, err
+	}
+}
+
+func (s *orgIdentityService) ListIntegrations() listPageFunc[openapi.Integration] {
+	return func(ctx context.Context, nextToken *string, limit int32) ([]openapi.Integration, *string, error) {
+		req := s.svc.OrgIntegrations.List(ctx, s.identity.Handle).Limit(limit)
+		if nextToken != nil {
+			req = req.NextToken(*nextToken)
+		}
+		resp, _, err := req.Execute()
+		return resp.GetItems(), resp.NextToken, err
+	}
+}
+
+func (s *orgIdentityService) GetIntegration(ctx context.Context, integrationHandle string) (openapi.Integration, error) {
+	resp, _, err := s.svc.OrgIntegrations.Get(ctx, s.identity.Handle, integrationHandle).Execute()
+	return resp, classifyError(err)
+}
+
+func (s *orgIdentityService) ListNotifiers() listPageFunc[openapi.Notifier] {
+	return func(ctx context.Context, nextToken *string, limit int32) ([]openapi.Notifier, *string, error) {
+		req := s.svc.OrgNotifiers.List(ctx, s.identity.Handle).Limit(limit)
+		if nextToken != nil {
+			req = req.NextToken(*nextToken)
+		}
+		resp, _, err := req.Execute()
+		return resp.GetItems(), resp.NextToken, err
+	}
+}
+
+func (s *orgIdentityService) GetNotifier(ctx context.Context, notifierName string) (openapi.Notifier, error) {
+	resp, _, err := s.svc.OrgNotifiers.Get(ctx, s.identity.Handle, notifierName).Execute()
+	return resp, classifyError(err)
+}
+
+func (s *orgIdentityService) ListProcesses() listPageFunc[openapi.SpProcess] {
+	return func(ctx context.Context, nextToken *string, limit int32) ([]openapi.SpProcess, *string, error) {
+		req := s.svc.OrgProcesses.List(ctx, s.identity.Handle).Limit(limit)
+		if nextToken != nil {
+			req = req.NextToken(*nextToken)
+		}
+		resp, _, err := req.Execute()
+		return resp.GetItems(), resp.NextToken, err
+	}
+}
+
+func (s *orgIdentityService) GetProcess(ctx context.Context, processId string) (openapi.SpProcess, error) {
+	resp, _, err := s.svc.OrgProcesses.Get(ctx, s.identity.Handle, processId).Execute()
+	return resp, classifyError(err)
+}
+
+func (s *orgIdentityService) ListUsage(filter string) listPageFunc[openapi.UsageMetric] {
+	return func(ctx context.Context, nextToken *string, limit int32) ([]openapi.UsageMetric, *string, error) {
+		req := s.svc.Orgs.ListUsage(ctx, s.identity.Handle).Where(filter).Limit(limit)
+		if nextToken != nil {
+			req = req.NextToken(*nextToken)
+		}
+		resp, _, err := req.Execute()
+		return resp.GetItems(), resp.NextToken, err
+	}
+}
+
+func (s *orgIdentityService) ListWorkspaceAggregators(workspaceHandle string) listPageFunc[openapi.WorkspaceAggregator] {
+	return func(ctx context.Context, nextToken *string, limit int32) ([]openapi.WorkspaceAggregator, *string, error) {
+		req := s.svc.OrgWorkspaceAggregators.List(ctx, s.identity.Handle, workspaceHandle).Limit(limit)
+		if nextToken != nil {
+			req = req.NextToken(*nextToken)
+		}
+		resp, _, err := req.Execute()
+		return resp.GetItems(), resp.NextToken, err
+	}
+}
+
+func (s *orgIdentityService) GetWorkspaceAggregator(ctx context.Context, workspaceHandle, aggregatorHandle string) (openapi.Aggregator, error) {
+	resp, _, err := s.svc.OrgWorkspaceAggregators.Get(ctx, s.identity.Handle, workspaceHandle, aggregatorHandle).Execute()
+	return resp, classifyError(err)
+}
+
+func (s *orgIdentityService) ListWorkspaceConnectionAssociations(workspaceHandle string) listPageFunc[openapi.WorkspaceConn] {
+	return func(ctx context.Context, nextToken *string, limit int32) ([]openapi.WorkspaceConn, *string, error) {
+		req := s.svc.OrgWorkspaceConnectionAssociations.List(ctx, s.identity.Handle, workspaceHandle).Limit(limit)
+		if nextToken != nil {
+			req = req.NextToken(*nextToken)
+		}
+		resp, _, err := req.Execute()
+		return resp.GetItems(), resp.NextToken, err
+	}
+}
+
+func (s *orgIdentityService) ListWorkspaceConnectionFolders(workspaceHandle string) listPageFunc[openapi.Connection] {
+	return func(ctx context.Context, nextToken *string, limit int32) ([]openapi.Connection, *string, error) {
+		req := s.svc.OrgWorkspaceConnectionFolders.List(ctx, s.identity.Handle, workspaceHandle).Limit(limit)
+		if nextToken != nil {
+			req = req.NextToken(*nextToken)
+		}
+		resp, _, err := req.Execute()
+		return resp.GetItems(), resp.NextToken, err
+	}
+}
+
+func (s *orgIdentityService) ListWorkspaceDatatanks(workspaceHandle string) listPageFunc[openapi.Datatank] {
+	return func(ctx context.Context, nextToken *string, limit int32) ([]openapi.Datatank, *string, error) {
+		req := s.svc.OrgWorkspaceDatatanks.List(ctx, s.identity.Handle, workspaceHandle).Limit(limit)
+		if nextToken != nil {
+			req = req.NextToken(*nextToken)
+		}
+		resp, _, err := req.Execute()
+		return resp.GetItems(), resp.NextToken, err
+	}
+}
+
+func (s *orgIdentityService) GetWorkspaceDatatank(ctx context.Context, workspaceHandle, datatankHandle string) (openapi.Datatank, error) {
+	resp, _, err := s.svc.OrgWorkspaceDatatanks.Get(ctx, s.identity.Handle, workspaceHandle, datatankHandle).Execute()
+	return resp, classifyError(err)
+}
+
+func (s *orgIdentityService) ListWorkspaceDatatankTables(workspaceHandle, datatankHandle string) listPageFunc[openapi.DatatankTable] {
+	return func(ctx context.Context, nextToken *string, limit int32) ([]openapi.DatatankTable, *string, error) {
+		req := s.svc.OrgWorkspaceDatatankTables.List(ctx, s.identity.Handle, workspaceHandle, datatankHandle).Limit(limit)
+		if nextToken != nil {
+			req = req.NextToken(*nextToken)
+		}
+		resp, _, err := req.Execute()
+		return resp.GetItems(), resp.NextToken, err
+	}
+}
+
+func (s *orgIdentityService) GetWorkspaceDatatankTable(ctx context.Context, workspaceHandle, datatankHandle, tableName string) (openapi.DatatankTable, error) {
+	resp, _, err := s.svc.OrgWorkspaceDatatankTables.Get(ctx, s.identity.Handle, workspaceHandle, datatankHandle, tableName).Execute()
+	return resp, classifyError(err)
+}
+
+func (s *orgIdentityService) ListWorkspaceDBLogs(workspaceHandle, filter string) listPageFunc[openapi.LogRecord] {
+	return func(ctx context.Context, nextToken *string, limit int32) ([]openapi.LogRecord, *string, error) {
+		// The API client has no option for the db log filter
+		req := s.svc.OrgWorkspaces.ListDBLogs(withQueryFilter(ctx, filter), s.identity.Handle, workspaceHandle).Limit(limit)
+		if nextToken != nil {
+			req = req.NextToken(*nextToken)
+		}
+		resp, _, err := req.Execute()
+		return resp.GetItems(), resp.NextToken, err
+	}
+}
+
+func (s *orgIdentityService) ListWorkspaceFlowpipeTriggers(workspaceHandle string) listPageFunc[openapi.ModTriggerInfo] {
+	return func(ctx context.Context, nextToken *string, limit int32) ([]openapi.ModTriggerInfo, *string, error) {
+		req := s.svc.OrgWorkspaceFlowpipeTriggers.List(ctx, s.identity.Handle, workspaceHandle).Limit(limit)
+		if nextToken != nil {
+			req = req.NextToken(*nextToken)
+		}
+		resp, _, err := req.Execute()
+		return resp.GetItems(), resp.NextToken, err
+	}
+}
+
+func (s *orgIdentityService) GetWorkspaceFlowpipeTrigger(ctx context.Context, workspaceHandle, triggerName string) (openapi.WorkspaceModTrigger, error) {
+	resp, _, err := s.svc.OrgWorkspaceFlowpipeTriggers.Get(ctx, s.identity.Handle, workspaceHandle, triggerName).Execute()
+	return resp, classifyError(err)
+}
+
+func (s *orgIdentityService) ListWorkspaceIntegrations(workspaceHandle string) listPageFunc[openapi.Integration] {
+	return func(ctx context.Context, nextToken *string, limit int32) ([]openapi.Integration, *string, error) {
+		req := s.svc.OrgWorkspaceIntegrations.List(ctx, s.identity.Handle, workspaceHandle).Limit(limit)
+		if nextToken != nil {
+			req = req.NextToken(*nextToken)
+		}
+		resp, _, err := req.Execute()
+		return resp.GetItems(), resp.NextToken, err
+	}
+}
+
+func (s *orgIdentityService) GetWorkspaceIntegration(ctx context.Context, workspaceHandle, integrationHandle string) (openapi.Integration, error) {
+	resp, _, err := s.svc.OrgWorkspaceIntegrations.Get(ctx, s.identity.Handle, workspaceHandle, integrationHandle).Execute()
+	return resp, classifyError(err)
+}
+
+func (s *orgIdentityService) ListWorkspaceMods(workspaceHandle string) listPageFunc[openapi.WorkspaceMod] {
+	return func(ctx context.Context, nextToken *string, limit int32) ([]openapi.WorkspaceMod, *string, error) {
+		req := s.svc.OrgWorkspaceMods.List(ctx, s.identity.Handle, workspaceHandle).Limit(limit)
+		if nextToken != nil {
+			req = req.NextToken(*nextToken)
+		}
+		resp, _, err := req.Execute()
+		return resp.GetItems(), resp.NextToken, err
+	}
+}
+
+func (s *orgIdentityService) GetWorkspaceMod(ctx context.Context, workspaceHandle, modAlias string) (openapi.WorkspaceMod, error) {
+	resp, _, err := s.svc.OrgWorkspaceMods.Get(ctx, s.identity.Handle, workspaceHandle, modAlias).Execute()
+	return resp, classifyError(err)
+}
+
+func (s *orgIdentityService) ListWorkspaceModVariables(workspaceHandle, modAlias string) listPageFunc[openapi.WorkspaceModVariable] {
+	return func(ctx context.Context, nextToken *string, limit int32) ([]openapi.WorkspaceModVariable, *string, error) {
+		req := s.svc.OrgWorkspaceModVariables.List(ctx, s.identity.Handle, workspaceHandle, modAlias).Limit(limit)
+		if nextToken != nil {
+			req = req.NextToken(*nextToken)
+		}
+		resp, _, err := req.Execute()
+		return resp.GetItems(), resp.NextToken, err
+	}
+}
+
+func (s *orgIdentityService) ListWorkspaceNotifiers(workspaceHandle string) listPageFunc[openapi.Notifier] {
+	return func(ctx context.Context, nextToken *string, limit int32) ([]openapi.Notifier, *string, error) {
+		req := s.svc.OrgWorkspaceNotifiers.List(ctx, s.identity.Handle, workspaceHandle).Limit(limit)
+		if nextToken != nil {
+			req = req.NextToken(*nextToken)
+		}
+		resp, _, err := req.Execute()
+		return resp.GetItems(), resp.NextToken, err
+	}
+}
+
+func (s *orgIdentityService) GetWorkspaceNotifier(ctx context.Context, workspaceHandle, notifierName string) (openapi.Notifier, error) {
+	resp, _, err := s.svc.OrgWorkspaceNotifiers.Get(ctx, s.identity.Handle, workspaceHandle, notifierName).Execute()
+	return resp, classifyError(err)
+}
+
+func (s *orgIdentityService) ListWorkspacePipelines(workspaceHandle, filter string) listPageFunc[openapi.Pipeline] {
+	return func(ctx context.Context, nextToken *string, limit int32) ([]openapi.Pipeline, *string, error) {
+		req := s.svc.OrgWorkspacePipelines.List(ctx, s.identity.Handle, workspaceHandle).Where(filter).Limit(limit)
+		if nextToken != nil {
+			req = req.NextToken(*nextToken)
+		}
+		resp, _, err := req.Execute()
+		return resp.GetItems(), resp.NextToken, err
+	}
+}
+
+func (s *orgIdentityService) GetWorkspacePipeline(ctx context.Context, workspaceHandle, pipelineId string) (openapi.Pipeline, error) {
+	resp, _, err := s.svc.OrgWorkspacePipelines.Get(ctx, s.identity.Handle, workspaceHandle, pipelineId).Execute()
+	return resp, classifyError(err)
+}
+
+func (s *orgIdentityService) RunWorkspacePipeline(ctx context.Context, workspaceHandle, pipelineId string, args map[string]interface{}) (openapi.PipelineCommandResponse, error) {
+	request := openapi.PipelineCommandRequest{Command: openapi.PipelineCommandRun}
+	if args != nil {
+		request.Args = &args
+	}
+	resp, _, err := s.svc.OrgWorkspacePipelines.Command(ctx, s.identity.Handle, workspaceHandle, pipelineId).Request(request).Execute()
+	return resp, classifyError(err)
+}
+
+func (s *orgIdentityService) ListWorkspaceProcesses(workspaceHandle, filter string) listPageFunc[openapi.SpProcess] {
+	return func(ctx context.Context, nextToken *string, limit int32) ([]openapi.SpProcess, *string, error) {
+		req := s.svc.OrgWorkspaceProcesses.List(ctx, s.identity.Handle, workspaceHandle).Where(filter).Limit(limit)
+		if nextToken != nil {
+			req = req.NextToken(*nextToken)
+		}
+		resp, _, err := req.Execute()
+		return resp.GetItems(), resp.NextToken, err
+	}
+}
+
+func (s *orgIdentityService) GetWorkspaceProcess(ctx context.Context, workspaceHandle, processId string) (openapi.SpProcess, error) {
+	resp, _, err := s.svc.OrgWorkspaceProcesses.Get(ctx, s.identity.Handle, workspaceHandle, processId).Execute()
+	return resp, classifyError(err)
+}
+
+func (s *orgIdentityService) ListWorkspaceSchemas(workspaceHandle string) listPageFunc[openapi.WorkspaceSchema] {
+	return func(ctx context.Context, nextToken *string, limit int32) ([]openapi.WorkspaceSchema, *string, error) {
+		req := s.svc.OrgWorkspaceSchemas.List(ctx, s.identity.Handle, workspaceHandle).Limit(limit)
+		if nextToken != nil {
+			req = req.NextToken(*nextToken)
+		}
+		resp, _, err := req.Execute()
+		return resp.GetItems(), resp.NextToken, err
+	}
+}
+
+func (s *orgIdentityService) GetWorkspaceSchema(ctx context.Context, workspaceHandle, schemaName string) (openapi.WorkspaceSchema, error) {
+	resp, _, err := s.svc.OrgWorkspaceSchemas.Get(ctx, s.identity.Handle, workspaceHandle, schemaName).Execute()
+	return resp, classifyError(err)
+}
+
+func (s *orgIdentityService) ListWorkspaceSchemaTables(workspaceHandle, schemaName string) listPageFunc[openapi.WorkspaceSchemaTable] {
+	// The tables of a schema are returned in a single page
+	return func(ctx context.Context, nextToken *string, limit int32) ([]openapi.WorkspaceSchemaTable, *string, error) {
+		resp, _, err := s.svc.OrgWorkspaceSchemas.ListTables(ctx, s.identity.Handle, workspaceHandle, schemaName).Execute()
+		return resp.GetItems(), nil, err
+	}
+}
+
+func (s *orgIdentityService) GetWorkspaceSchemaTable(ctx context.Context, workspaceHandle, schemaName, tableName string) (openapi.WorkspaceSchemaTable, error) {
+	resp, _, err := s.svc.OrgWorkspaceSchemas.GetTable(ctx, s.identity.Handle, workspaceHandle, schemaName, tableName).Execute()
+	return resp, classifyError(err)
+}
+
+func (s *orgIdentityService) ListWorkspaceSnapshots(workspaceHandle, filter string) listPageFunc[openapi.WorkspaceSnapshot] {
+	return func(ctx context.Context, nextToken *string, limit int32) ([]openapi.WorkspaceSnapshot, *string, error) {
+		req := s.svc.OrgWorkspaceSnapshots.List(ctx, s.identity.Handle, workspaceHandle).Where(filter).Limit(limit)
+		if nextToken != nil {
+			req = req.NextToken(*nextToken)
+		}
+		resp, _, err := req.Execute()
+		return resp.GetItems(), resp.NextToken, err
+	}
+}
+
+func (s *orgIdentityService) GetWorkspaceSnapshot(ctx context.Context, workspaceHandle, snapshotId string) (openapi.WorkspaceSnapshot, error) {
+	resp, _, err := s.svc.OrgWorkspaceSnapshots.Get(ctx, s.identity.Handle, workspaceHandle, snapshotId).Execute()
+	return resp, classifyError(err)
+}
+
+func (s *orgIdentityService) DownloadWorkspaceSnapshot(ctx context.Context, workspaceHandle, snapshotId, contentType string) (openapi.WorkspaceSnapshotData, error) {
+	resp, _, err := s.svc.OrgWorkspaceSnapshots.Download(ctx, s.identity.Handle, workspaceHandle, snapshotId, contentType).Execute()
+	return resp, classifyError(err)
+}
+
+func (s *orgIdentityService) DownloadWorkspaceSnapshotFile(ctx context.Context, workspaceHandle, snapshotId, format string) ([]byte, error) {
+	_, resp, err := s.svc.OrgWorkspaceSnapshots.Download(ctx, s.identity.Handle, workspaceHandle, snapshotId, format).Execute()
+	return snapshotFileBody(resp, err)
+}
+
+func (s *orgIdentityService) DeleteWorkspaceSnapshot(ctx context.Context, workspaceHandle, snapshotId string) (openapi.WorkspaceSnapshot, error) {
+	resp, _, err := s.svc.OrgWorkspaceSnapshots.Delete(ctx, s.identity.Handle, workspaceHandle, snapshotId).Execute()
+	return resp, classifyError(err)
+}
+
+func (s *orgIdentityService) ListWorkspaceUsage(workspaceHandle, filter string) listPageFunc[openapi.UsageMetric] {
+	return func(ctx context.Context, nextToken *string, limit int32) ([]openapi.UsageMetric, *string, error) {
+		req := s.svc.OrgWorkspaceUsages.List(ctx, s.identity.Handle, workspaceHandle).Where(filter).Limit(limit)
+		if nextToken != nil {
+			req = req.NextToken(*nextToken)
+		}
+		resp, _, err := req.Execute()
+		return resp.GetItems(), resp.NextToken, err
+	}
+}
@@ -0,0 +1,85 @@
+package pipes
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+)
+
+func TestIdentityServiceDispatch(t *testing.T) {
+	tests := []struct {
+		name             string
+		quals            []*proto.Qual
+		want             []string
+		identityRequests []string
+	}{
+		{
+			name:  "connection user by handle",
+			quals: []*proto.Qual{qual("identity_handle", "=", stringQualValue("alice"))},
+			want:  []string{"a_1", "a_2"},
+			// the connection user is resolved from the actor, not the identity API
+			identityRequests: nil,
+		},
+		{
+			name:             "org by handle",
+			quals:            []*proto.Qual{qual("identity_handle", "=", stringQualValue("acme"))},
+			want:             []string{"a_3"},
+			identityRequests: []string{"GET /identity/acme"},
+		},
+		{
+			name:             "org by id",
+			quals:            []*proto.Qual{qual("identity_id", "=", stringQualValue("o_acme"))},
+			want:             []string{"a_3"},
+			identityRequests: []string{"GET /identity/o_acme"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newFakePipesAPI(t, "pipes_api.json")
+			p := newTestPlugin(t, api)
+
+			rows := p.MustQuery(testQuery{
+				Table:   "pipes_audit_log",
+				Columns: []string{"id", "identity_handle"},
+				Quals:   tt.quals,
+			})
+
+			if got := rowStrings(rows, "id"); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ids = %v, want %v", got, tt.want)
+			}
+			var identityRequests []string
+			for _, route := range []string{"GET /identity/alice", "GET /identity/u_alice", "GET /identity/acme", "GET /identity/o_acme"} {
+				// cached for the query, so each identity is fetched at most once
+				if n := len(api.Requests(route)); n > 0 {
+					identityRequests = append(identityRequests, route)
+					if n > 1 {
+						t.Errorf("%s requested %d times, want 1", route, n)
+					}
+				}
+			}
+			if !reflect.DeepEqual(identityRequests, tt.identityRequests) {
+				t.Errorf("identity requests = %v, want %v", identityRequests, tt.identityRequests)
+			}
+		})
+	}
+}
+
+func TestResolveIdentityPerQuery(t *testing.T) {
+	api := newFakePipesAPI(t, "pipes_api.json")
+	p := newTestPlugin(t, api)
+
+	query := testQuery{
+		Table:   "pipes_audit_log",
+		Columns: []string{"id"},
+		Quals:   []*proto.Qual{qual("identity_handle", "=", stringQualValue("acme"))},
+	}
+	p.MustQuery(query)
+	p.MustQuery(query)
+
+	// the identity is not cached between queries, so a renamed handle is
+	// picked up by the next query
+	if got := len(api.Requests("GET /identity/acme")); got != 2 {
+		t.Errorf("identity requests = %d, want 2", got)
+	}
+}
@@ -0,0 +1,68 @@
+package pipes
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+)
+
+func TestOrganizationInvitation(t *testing.T) {
+	api := newFakePipesAPI(t, "pipes_api.json")
+	p := newTestPlugin(t, api)
+
+	rows := p.MustQuery(testQuery{
+		Table:   "pipes_organization_invitation",
+		Columns: []string{"user_handle", "org_handle", "status", "role", "created_by_id"},
+	})
+
+	// accepted members are not invitations
+	if got := rowStrings(rows, "user_handle"); !reflect.DeepEqual(got, []string{"bob"}) {
+		t.Fatalf("user_handle = %v, want [bob]", got)
+	}
+	for col, want := range map[string]string{"org_handle": "acme", "status": "invited", "role": "member", "created_by_id": "u_alice"} {
+		if got := rows[0].Columns[col].GetStringValue(); got != want {
+			t.Errorf("%s = %q, want %q", col, got, want)
+		}
+	}
+}
+
+func TestOrganizationInvitationGet(t *testing.T) {
+	api := newFakePipesAPI(t, "pipes_api.json")
+	p := newTestPlugin(t, api)
+
+	for user, want := range map[string]int{"bob": 1, "alice": 0} {
+		rows := p.MustQuery(testQuery{
+			Table:   "pipes_organization_invitation",
+			Columns: []string{"user_handle"},
+			Quals: []*proto.Qual{
+				qual("org_handle", "=", stringQualValue("acme")),
+				qual("user_handle", "=", stringQualValue(user)),
+			},
+		})
+		if len(rows) != want {
+			t.Errorf("%s rows = %d, want %d", user, len(rows), want)
+		}
+	}
+}
+
+func TestWorkspaceInvitation(t *testing.T) {
+	api := newFakePipesAPI(t, "pipes_api.json")
+	p := newTestPlugin(t, api)
+
+	rows := p.MustQuery(testQuery{
+		Table:   "pipes_workspace_invitation",
+		Columns: []string{"user_handle", "org_handle", "workspace_handle", "status"},
+	})
+
+	if got := rowStrings(rows, "user_handle"); !reflect.DeepEqual(got, []string{"carol"}) {
+		t.Fatalf("user_handle = %v, want [carol]", got)
+	}
+	if got := rows[0].Columns["org_handle"].GetStringValue() + "/" + rows[0].Columns["workspace_handle"].GetStringValue(); got != "acme/prod" {
+		t.Errorf("org_handle/workspace_handle = %q, want acme/prod", got)
+	}
+	// user workspaces have no members to invite
+	if got := len(api.Requests("GET /user/alice/workspace/dev/member")); got != 0 {
+		t.Errorf("user workspace member requests = %d, want 0", got)
+	}
+}
@@ -0,0 +1,96 @@
+package pipes
+
+import (
+	"context"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+)
+
+// maxPageSize is the largest page size accepted by the Pipes list APIs.
+const maxPageSize = int32(100)
+
+// listPageFunc fetches a single page from a Pipes list API. nextToken is nil
+// for the first page; limit is the page size to request. It returns the items
+// on the page and the token for the next page, or nil if this was the last one.
+type listPageFunc[T any] func(ctx context.Context, nextToken *string, limit int32) ([]T, *string, error)
+
+// listPageSize returns the page size to request from the API. If the requested
+// number of rows is less than the paging max limit, use that instead.
+func listPageSize(d *plugin.QueryData) int32 {
+	limit := d.QueryContext.Limit
+	if limit != nil && *limit < int64(maxPageSize) {
+		if *limit < 1 {
+			return 1
+		}
+		return int32(*limit)
+	}
+	return maxPageSize
+}
+
+// paginate pages through a Pipes list API, streaming every item it returns.
+// Failed page fetches are retried by the API client's transport, and API
+// errors are returned classified, see classifyError. Each page
+// after the first waits for the list rate limiter, and paging stops as soon as
+// the query has no rows remaining (limit hit or context cancelled).
+func paginate[T any](ctx context.Context, d *plugin.QueryData, fetch listPageFunc[T]) error {
+	return paginateTo(ctx, d, fetch, func(item T) bool {
+		d.StreamListItem(ctx, item)
+
+		// Context can be cancelled due to manual cancellation or the limit has been hit
+		return d.RowsRemaining(ctx) != 0
+	})
+}
+
+// paginateTo pages through a Pipes list API like paginate, but passes every
+// item to send rather than streaming it. Paging stops as soon as send returns
+// false. It is for lists fetched concurrently, which must not stream from more
+// than one goroutine at a time.
+func paginateTo[T any](ctx context.Context, d *plugin.QueryData, fetch listPageFunc[T], send func(item T) bool) error {
+	limit := listPageSize(d)
+	var nextToken *string
+
+	for {
+		items, pageToken, err := fetch(ctx, nextToken, limit)
+		if err != nil {
+			return classifyError(err)
+		}
+
+		for _, item := range items {
+			if !send(item) {
+				return nil
+			}
+		}
+
+		if pageToken == nil || *pageToken == "" {
+			return nil
+		}
+		nextToken = pageToken
+
+		// The SDK waits for the rate limiter before the list hydrate is called,
+		// so only the later pages need to wait
+		d.WaitForListRateLimit(ctx)
+	}
+}
+
+// listAll fetches every page of a Pipes list API and returns the items rather
+// than streaming them, for lists that drive further list calls. Each page
+// after the first waits for the list rate limiter.
+func listAll[T any](ctx context.Context, d *plugin.QueryData, fetch listPageFunc[T]) ([]T, error) {
+	var all []T
+	var nextToken *string
+
+	for {
+		items, pageToken, err := fetch(ctx, nextToken, maxPageSize)
+		if err != nil {
+			return nil, classifyError(err)
+		}
+		all = append(all, items...)
+
+		if pageToken == nil || *pageToken == "" {
+			return all, nil
+		}
+		nextToken = pageToken
+
+		d.WaitForListRateLimit(ctx)
+	}
+}
@@ -0,0 +1,80 @@
+package pipes
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+)
+
+func TestPaginateFollowsNextToken(t *testing.T) {
+	api := newFakePipesAPI(t, "pipes_api.json")
+	p := newTestPlugin(t, api)
+
+	rows := p.MustQuery(testQuery{
+		Table:   "pipes_workspace",
+		Columns: []string{"id", "handle"},
+		Quals:   []*proto.Qual{qual("identity_handle", "=", stringQualValue("alice"))},
+	})
+
+	want := []string{"demo", "dev", "sandbox", "stage", "test"}
+	if got := rowStrings(rows, "handle"); !reflect.DeepEqual(got, want) {
+		t.Errorf("handles = %v, want %v", got, want)
+	}
+
+	// the fake serves at most 2 workspaces per page
+	requests := api.Requests("GET /user/alice/workspace")
+	if len(requests) != 3 {
+		t.Fatalf("requests = %d, want 3", len(requests))
+	}
+	wantTokens := []string{"", "2", "4"}
+	for i, r := range requests {
+		if got := r.Query.Get("next_token"); got != wantTokens[i] {
+			t.Errorf("request %d next_token = %q, want %q", i, got, wantTokens[i])
+		}
+		if got := r.Query.Get("limit"); got != "100" {
+			t.Errorf("request %d limit = %q, want %q", i, got, "100")
+		}
+	}
+}
+
+func TestPaginateStopsAtLimit(t *testing.T) {
+	api := newFakePipesAPI(t, "pipes_api.json")
+	p := newTestPlugin(t, api)
+
+	rows := p.MustQuery(testQuery{
+		Table:   "pipes_workspace",
+		Columns: []string{"id", "handle"},
+		Quals:   []*proto.Qual{qual("identity_handle", "=", stringQualValue("alice"))},
+		Limit:   3,
+	})
+
+	if len(rows) != 3 {
+		t.Errorf("rows = %d, want 3", len(rows))
+	}
+
+	// the first page is requested with the query limit as the page size, and
+	// paging stops as soon as the limit has been streamed
+	requests := api.Requests("GET /user/alice/workspace")
+	if len(requests) != 2 {
+		t.Fatalf("requests = %d, want 2", len(requests))
+	}
+	if got := requests[0].Query.Get("limit"); got != "3" {
+		t.Errorf("limit = %q, want %q", got, "3")
+	}
+}
+
+func TestPaginateActorWorkspaces(t *testing.T) {
+	api := newFakePipesAPI(t, "pipes_api.json")
+	p := newTestPlugin(t, api)
+
+	rows := p.MustQuery(testQuery{
+		Table:   "pipes_workspace",
+		Columns: []string{"id", "handle"},
+	})
+
+	want := []string{"dev", "prod"}
+	if got := rowStrings(rows, "handle"); !reflect.DeepEqual(got, want) {
+		t.Errorf("handles = %v, want %v", got, want)
+	}
+}
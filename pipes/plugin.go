@@ -2,9 +2,11 @@ package pipes
 
 import (
 	"context"
+	"net/http"
 
 	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
 	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+	"github.com/turbot/steampipe-plugin-sdk/v5/rate_limiter"
 )
 
 const pluginName = "steampipe-plugin-pipes"
@@ -15,15 +17,19 @@ func Plugin(ctx context.Context) *plugin.Plugin {
 		Name:             pluginName,
 		DefaultTransform: transform.FromGo(),
 		DefaultIgnoreConfig: &plugin.IgnoreConfig{
-			ShouldIgnoreErrorFunc: shouldIgnoreErrors([]string{"404"}),
+			ShouldIgnoreErrorFunc: shouldIgnoreErrors([]int{http.StatusNotFound}),
 		},
-		DefaultRetryConfig: &plugin.RetryConfig{
-			ShouldRetryErrorFunc: shouldRetryErrorFunc,
-			BackoffAlgorithm:     "Exponential",
-			RetryInterval:        250,  // milliseconds - initial delay between retries
-			CappedDuration:       2000, // milliseconds - maximum delay between retries
-			MaxAttempts:          12,   // maximum number of retry attempts
-			MaxDuration:          30,   // seconds - total time limit for all retries
+		// Failed API requests are retried by the API client, see retryTransport.
+		// The default limiter can be overridden with a `limiter "pipes_api"`
+		// block in the plugin config. Requests are also limited per identity
+		// when `requests_per_second` is set, see rateLimitTransport.
+		RateLimiters: []*rate_limiter.Definition{
+			{
+				Name:       "pipes_api",
+				FillRate:   20,
+				BucketSize: 40,
+				Scope:      []string{"connection"},
+			},
 		},
 		ConnectionConfigSchema: &plugin.ConnectionConfigSchema{
 			NewInstance: ConfigInstance,
@@ -33,29 +39,55 @@ func Plugin(ctx context.Context) *plugin.Plugin {
 				Name:    "user_id",
 				Hydrate: getUserIdForConnection,
 			},
+			{
+				Name:    "pipes_host",
+				Hydrate: getPipesHost,
+			},
 		},
 		TableMap: map[string]*plugin.Table{
-			"pipes_audit_log":                     tablePipesAuditLog(ctx),
-			"pipes_connection":                    tablePipesConnection(ctx),
-			"pipes_organization_member":           tablePipesOrganizationMember(ctx),
-			"pipes_organization":                  tablePipesOrganization(ctx),
-			"pipes_process":                       tablePipesProcess(ctx),
-			"pipes_organization_workspace_member": tablePipesOrganizationWorkspaceMember(ctx),
-			"pipes_tenant":                        tablePipesTenant(ctx),
-			"pipes_tenant_member":                 tablePipesTenantMember(ctx),
-			"pipes_token":                         tablePipesToken(ctx),
-			"pipes_user":                          tablePipesUser(ctx),
-			"pipes_user_email":                    tablePipesUserEmail(ctx),
-			"pipes_user_preferences":              tablePipesUserPreferences(ctx),
-			"pipes_workspace":                     tablePipesWorkspace(ctx),
-			"pipes_workspace_aggregator":          tablePipesWorkspaceAggregator(ctx),
-			"pipes_workspace_connection":          tablePipesWorkspaceConnection(ctx),
-			"pipes_workspace_mod":                 tablePipesWorkspaceMod(ctx),
-			"pipes_workspace_mod_variable":        tablePipesWorkspaceModVariable(ctx),
-			"pipes_workspace_db_log":              tablePipesWorkspaceDBLog(ctx),
-			"pipes_workspace_pipeline":            tablePipesWorkspacePipeline(ctx),
-			"pipes_workspace_process":             tablePipesWorkspaceProcess(ctx),
-			"pipes_workspace_snapshot":            tablePipesWorkspaceSnapshot(ctx),
+			"pipes_action_pipeline_run":               tablePipesActionPipelineRun(ctx),
+			"pipes_action_snapshot_delete":            tablePipesActionSnapshotDelete(ctx),
+			"pipes_action_workspace_create":           tablePipesActionWorkspaceCreate(ctx),
+			"pipes_audit_log":                         tablePipesAuditLog(ctx),
+			"pipes_billing_subscription":              tablePipesBillingSubscription(ctx),
+			"pipes_connection":                        tablePipesConnection(ctx),
+			"pipes_connection_folder":                 tablePipesConnectionFolder(ctx),
+			"pipes_integration":                       tablePipesIntegration(ctx),
+			"pipes_notifier":                          tablePipesNotifier(ctx),
+			"pipes_organization_invitation":           tablePipesOrganizationInvitation(ctx),
+			"pipes_organization_member":               tablePipesOrganizationMember(ctx),
+			"pipes_organization":                      tablePipesOrganization(ctx),
+			"pipes_process":                           tablePipesProcess(ctx),
+			"pipes_organization_workspace_member":     tablePipesOrganizationWorkspaceMember(ctx),
+			"pipes_tenant":                            tablePipesTenant(ctx),
+			"pipes_tenant_audit_log":                  tablePipesTenantAuditLog(ctx),
+			"pipes_tenant_member":                     tablePipesTenantMember(ctx),
+			"pipes_token":                             tablePipesToken(ctx),
+			"pipes_usage":                             tablePipesUsage(ctx),
+			"pipes_user":                              tablePipesUser(ctx),
+			"pipes_user_email":                        tablePipesUserEmail(ctx),
+			"pipes_user_preferences":                  tablePipesUserPreferences(ctx),
+			"pipes_workspace":                         tablePipesWorkspace(ctx),
+			"pipes_workspace_aggregator":              tablePipesWorkspaceAggregator(ctx),
+			"pipes_workspace_connection":              tablePipesWorkspaceConnection(ctx),
+			"pipes_workspace_mod":                     tablePipesWorkspaceMod(ctx),
+			"pipes_workspace_mod_variable":            tablePipesWorkspaceModVariable(ctx),
+			"pipes_workspace_datatank":                tablePipesWorkspaceDatatank(ctx),
+			"pipes_workspace_datatank_table":          tablePipesWorkspaceDatatankTable(ctx),
+			"pipes_workspace_db_log":                  tablePipesWorkspaceDBLog(ctx),
+			"pipes_workspace_flowpipe_trigger":        tablePipesWorkspaceFlowpipeTrigger(ctx),
+			"pipes_workspace_integration":             tablePipesWorkspaceIntegration(ctx),
+			"pipes_workspace_invitation":              tablePipesWorkspaceInvitation(ctx),
+			"pipes_workspace_notifier":                tablePipesWorkspaceNotifier(ctx),
+			"pipes_workspace_pipeline":                tablePipesWorkspacePipeline(ctx),
+			"pipes_workspace_process":                 tablePipesWorkspaceProcess(ctx),
+			"pipes_workspace_schema":                  tablePipesWorkspaceSchema(ctx),
+			"pipes_workspace_schema_table":            tablePipesWorkspaceSchemaTable(ctx),
+			"pipes_workspace_snapshot":                tablePipesWorkspaceSnapshot(ctx),
+			"pipes_workspace_snapshot_control_result": tablePipesWorkspaceSnapshotControlResult(ctx),
+			"pipes_workspace_snapshot_diff":           tablePipesWorkspaceSnapshotDiff(ctx),
+			"pipes_workspace_snapshot_download":       tablePipesWorkspaceSnapshotDownload(ctx),
+			"pipes_workspace_snapshot_panel":          tablePipesWorkspaceSnapshotPanel(ctx),
 		},
 	}
 
@@ -0,0 +1,576 @@
+package pipes
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Sources of the queries in the db log of a workspace, see querySource.
+const (
+	querySourceDashboard   = "dashboard"
+	querySourceInteractive = "interactive"
+	querySourceSystem      = "system"
+)
+
+// QueryMetadata is derived from the SQL text of a query in a db log.
+type QueryMetadata struct {
+	// QueryFingerprint is the query with comments removed, literals replaced
+	// by ? and whitespace and keyword case normalized, so the runs of the same
+	// query with different values have the same fingerprint.
+	QueryFingerprint string
+	// StatementType is the type of the statement, e.g. 'select' or 'insert'.
+	// For a `with` query it is the type of the statement after the CTEs.
+	StatementType string
+	// ReferencedSchemas are the schemas of the schema qualified tables the
+	// query reads or writes.
+	ReferencedSchemas []string
+	// ReferencedTables are the tables the query reads or writes, schema
+	// qualified if they were in the query, excluding CTEs.
+	ReferencedTables []string
+	// QuerySource is where the query most likely came from, see querySource.
+	QuerySource string
+}
+
+// systemSchemas are the schemas of the catalog and of Steampipe's own tables.
+// Queries which only reference them are made by clients rather than users.
+var systemSchemas = map[string]bool{
+	"information_schema": true,
+	"pg_catalog":         true,
+	"steampipe_command":  true,
+	"steampipe_internal": true,
+}
+
+// sessionStatements are statements which manage the session or transaction
+// rather than query data, e.g. those sent by a client when it connects.
+var sessionStatements = map[string]bool{
+	"begin":      true,
+	"commit":     true,
+	"deallocate": true,
+	"discard":    true,
+	"listen":     true,
+	"reset":      true,
+	"rollback":   true,
+	"set":        true,
+	"show":       true,
+	"start":      true,
+	"unlisten":   true,
+}
+
+// queryKeywords are the keywords which may come directly before an opening
+// parenthesis that is not a function call, e.g. `in (select ...)`.
+var queryKeywords = map[string]bool{
+	"all": true, "and": true, "any": true, "as": true, "between": true,
+	"case": true, "else": true, "except": true, "exists": true, "from": true,
+	"in": true, "intersect": true, "into": true, "is": true, "join": true,
+	"lateral": true, "materialized": true, "not": true, "on": true, "or": true, "select": true,
+	"some": true, "then": true, "union": true, "using": true, "values": true,
+	"when": true, "where": true, "with": true,
+}
+
+// parseQueryMetadata derives the metadata of a query from its SQL text. The
+// query is scanned rather than fully parsed, so the referenced tables are the
+// names which follow from, join, into, update and table; that is enough to
+// aggregate the queries of a workspace by table, but may miss tables of
+// unusual statements.
+func parseQueryMetadata(query string) QueryMetadata {
+	tokens := scanQuery(query)
+	metadata := QueryMetadata{
+		QueryFingerprint:  queryFingerprint(tokens),
+		StatementType:     statementType(tokens),
+		ReferencedSchemas: []string{},
+		ReferencedTables:  []string{},
+	}
+
+	schemas := map[string]bool{}
+	tables := map[string]bool{}
+	for _, name := range referencedTables(tokens) {
+		tables[strings.Join(name, ".")] = true
+		if len(name) > 1 {
+			schemas[name[len(name)-2]] = true
+		}
+	}
+	metadata.ReferencedSchemas = sortedKeys(schemas)
+	metadata.ReferencedTables = sortedKeys(tables)
+	metadata.QuerySource = querySource(tokens, metadata)
+	return metadata
+}
+
+// querySource returns where a query most likely came from. The db log does
+// not record the client, so it is inferred from the query:
+//   - 'system' for session statements and catalog queries, e.g. those a SQL
+//     client makes to list the tables of the workspace
+//   - 'dashboard' for the prepared statements and the queries tagged with a
+//     mod resource that dashboards, benchmarks and snapshots run
+//   - 'interactive' for everything else, e.g. queries from the query console
+func querySource(tokens []queryToken, metadata QueryMetadata) string {
+	if metadata.StatementType == "" || sessionStatements[metadata.StatementType] {
+		return querySourceSystem
+	}
+	if len(metadata.ReferencedTables) > 0 && allSystemTables(metadata.ReferencedTables) {
+		return querySourceSystem
+	}
+
+	if metadata.StatementType == "execute" || metadata.StatementType == "prepare" {
+		return querySourceDashboard
+	}
+	for _, token := range tokens {
+		if token.kind != queryTokenComment {
+			break
+		}
+		if isModResourceComment(token.text) {
+			return querySourceDashboard
+		}
+	}
+	return querySourceInteractive
+}
+
+// allSystemTables reports whether the tables are all in the system schemas,
+// or are unqualified catalog tables such as pg_class.
+func allSystemTables(tables []string) bool {
+	for _, table := range tables {
+		schema, _, qualified := strings.Cut(table, ".")
+		if qualified && !systemSchemas[schema] {
+			return false
+		}
+		if !qualified && !strings.HasPrefix(table, "pg_") {
+			return false
+		}
+	}
+	return true
+}
+
+// isModResourceComment reports whether a comment names a mod resource, e.g.
+// `-- aws_compliance.control.cis_v300_1_4`, as the queries of dashboards and
+// benchmarks are tagged with.
+func isModResourceComment(comment string) bool {
+	comment = strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(strings.TrimPrefix(comment, "--"), "/*"), "*/"))
+	fields := strings.Fields(comment)
+	if len(fields) == 0 {
+		return false
+	}
+	parts := strings.Split(fields[0], ".")
+	if len(parts) < 3 {
+		return false
+	}
+	switch parts[1] {
+	case "benchmark", "card", "chart", "control", "dashboard", "detection", "edge", "flow", "graph", "hierarchy", "image", "input", "node", "query", "table", "text":
+		return true
+	}
+	return false
+}
+
+//// SCANNING
+
+type queryTokenKind int
+
+const (
+	queryTokenWord queryTokenKind = iota
+	queryTokenQuotedIdentifier
+	queryTokenString
+	queryTokenNumber
+	queryTokenParameter
+	queryTokenPunctuation
+	queryTokenComment
+)
+
+// queryToken is a token of a SQL query. The text of unquoted words is lower
+// case, and quoted identifiers are unquoted.
+type queryToken struct {
+	kind queryTokenKind
+	text string
+}
+
+// scanQuery splits a SQL query into tokens. Leading comments are kept, so the
+// query source can be read from them, and all other comments are dropped.
+func scanQuery(query string) []queryToken {
+	var tokens []queryToken
+	runes := []rune(query)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '-' && i+1 < len(runes) && runes[i+1] == '-':
+			start := i
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+			tokens = appendComment(tokens, string(runes[start:i]))
+		case r == '/' && i+1 < len(runes) && runes[i+1] == '*':
+			start := i
+			depth := 0
+			for i < len(runes) {
+				if runes[i] == '/' && i+1 < len(runes) && runes[i+1] == '*' {
+					depth++
+					i += 2
+				} else if runes[i] == '*' && i+1 < len(runes) && runes[i+1] == '/' {
+					depth--
+					i += 2
+					if depth == 0 {
+						break
+					}
+				} else {
+					i++
+				}
+			}
+			tokens = appendComment(tokens, string(runes[start:i]))
+		case r == '\'' || (r == 'e' || r == 'E') && i+1 < len(runes) && runes[i+1] == '\'':
+			escapes := r != '\''
+			if escapes {
+				i++
+			}
+			i = skipQuoted(runes, i, '\'', escapes)
+			tokens = append(tokens, queryToken{kind: queryTokenString})
+		case r == '"':
+			start := i
+			i = skipQuoted(runes, i, '"', false)
+			text := strings.ReplaceAll(strings.Trim(string(runes[start:i]), `"`), `""`, `"`)
+			tokens = append(tokens, queryToken{kind: queryTokenQuotedIdentifier, text: text})
+		case r == '$' && i+1 < len(runes) && unicode.IsDigit(runes[i+1]):
+			i++
+			for i < len(runes) && unicode.IsDigit(runes[i]) {
+				i++
+			}
+			tokens = append(tokens, queryToken{kind: queryTokenParameter})
+		case r == '$':
+			// A dollar quoted string, e.g. $$text$$ or $tag$text$tag$
+			end := i + 1
+			for end < len(runes) && (runes[end] == '_' || unicode.IsLetter(runes[end]) || unicode.IsDigit(runes[end])) {
+				end++
+			}
+			if end >= len(runes) || runes[end] != '$' {
+				tokens = append(tokens, queryToken{kind: queryTokenPunctuation, text: "$"})
+				i++
+				break
+			}
+			tag := string(runes[i : end+1])
+			rest := string(runes[end+1:])
+			if j := strings.Index(rest, tag); j >= 0 {
+				i = end + 1 + len([]rune(rest[:j])) + len([]rune(tag))
+			} else {
+				i = len(runes)
+			}
+			tokens = append(tokens, queryToken{kind: queryTokenString})
+		case unicode.IsDigit(r) || r == '.' && i+1 < len(runes) && unicode.IsDigit(runes[i+1]):
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.' || runes[i] == 'e' || runes[i] == 'E' ||
+				(runes[i] == '-' || runes[i] == '+') && (runes[i-1] == 'e' || runes[i-1] == 'E')) {
+				i++
+			}
+			tokens = append(tokens, queryToken{kind: queryTokenNumber})
+		case r == '_' || unicode.IsLetter(r):
+			start := i
+			for i < len(runes) && (runes[i] == '_' || runes[i] == '$' || unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i])) {
+				i++
+			}
+			tokens = append(tokens, queryToken{kind: queryTokenWord, text: strings.ToLower(string(runes[start:i]))})
+		default:
+			start := i
+			i++
+			// Keep multi-character operators together, e.g. <=, ::, ->>
+			if strings.ContainsRune("<>=!~*+-/%^&|#@:", r) {
+				for i < len(runes) && strings.ContainsRune("<>=!~*%^&|#@:", runes[i]) {
+					i++
+				}
+			}
+			tokens = append(tokens, queryToken{kind: queryTokenPunctuation, text: string(runes[start:i])})
+		}
+	}
+	return tokens
+}
+
+// appendComment keeps a comment only if it comes before the statement.
+func appendComment(tokens []queryToken, comment string) []queryToken {
+	for _, token := range tokens {
+		if token.kind != queryTokenComment {
+			return tokens
+		}
+	}
+	return append(tokens, queryToken{kind: queryTokenComment, text: comment})
+}
+
+// skipQuoted returns the index after the quoted text starting at i, where a
+// doubled quote is an escaped quote. If backslashEscapes is set, as it is for
+// E'...' strings, a backslash escapes the character after it too.
+func skipQuoted(runes []rune, i int, quote rune, backslashEscapes bool) int {
+	for i++; i < len(runes); i++ {
+		switch runes[i] {
+		case '\\':
+			if backslashEscapes {
+				i++
+			}
+		case quote:
+			if i+1 < len(runes) && runes[i+1] == quote {
+				i++
+				continue
+			}
+			return i + 1
+		}
+	}
+	return i
+}
+
+//// DERIVED METADATA
+
+// queryFingerprint joins the tokens of a query with literals replaced by ?.
+// Lists of literals, e.g. `in ('a', 'b')`, collapse to a single ?, so queries
+// with a different number of values share a fingerprint.
+func queryFingerprint(tokens []queryToken) string {
+	var parts []queryToken
+	for _, token := range tokens {
+		switch token.kind {
+		case queryTokenComment:
+			continue
+		case queryTokenString, queryTokenNumber, queryTokenParameter:
+			// Collapse `?, ?` to `?`
+			if n := len(parts); n >= 2 && parts[n-1].text == "," && parts[n-2].text == "?" {
+				parts = parts[:n-1]
+				continue
+			}
+			token.text = "?"
+		case queryTokenQuotedIdentifier:
+			token.text = `"` + strings.ReplaceAll(token.text, `"`, `""`) + `"`
+		}
+		parts = append(parts, token)
+	}
+
+	var b strings.Builder
+	for i, part := range parts {
+		if i > 0 && needsSpace(parts[i-1], part) {
+			b.WriteByte(' ')
+		}
+		b.WriteString(part.text)
+	}
+	return strings.TrimSuffix(b.String(), ";")
+}
+
+// needsSpace reports whether a space separates two tokens in a fingerprint.
+func needsSpace(prev, token queryToken) bool {
+	switch token.text {
+	case ",", ")", ".", ";", "::":
+		return false
+	case "(":
+		// No space for function calls, e.g. count(*)
+		return prev.kind != queryTokenWord || queryKeywords[prev.text]
+	}
+	switch prev.text {
+	case "(", ".", "::":
+		return false
+	}
+	return true
+}
+
+// statementType returns the first keyword of the statement, skipping any CTEs
+// and opening parentheses.
+func statementType(tokens []queryToken) string {
+	i := 0
+	for i < len(tokens) && (tokens[i].kind == queryTokenComment || tokens[i].text == "(") {
+		i++
+	}
+	if i >= len(tokens) || tokens[i].kind != queryTokenWord {
+		return ""
+	}
+	if tokens[i].text != "with" {
+		return tokens[i].text
+	}
+
+	// The statement is the first keyword after the CTEs, outside parentheses
+	depth := 0
+	for _, token := range tokens[i+1:] {
+		switch {
+		case token.text == "(":
+			depth++
+		case token.text == ")":
+			depth--
+		case depth == 0 && token.kind == queryTokenWord:
+			switch token.text {
+			case "select", "insert", "update", "delete", "merge":
+				return token.text
+			}
+		}
+	}
+	return "with"
+}
+
+// referencedTables returns the names of the tables which follow the from,
+// join, into, update and table keywords, and the commas of a from clause, as
+// their dot separated parts. CTE names, subqueries and table functions are
+// skipped, as is the `from` of function calls such as extract(year from t).
+func referencedTables(tokens []queryToken) [][]string {
+	ctes := map[string]bool{}
+	for i, token := range tokens {
+		if token.text != "with" {
+			continue
+		}
+		next := i + 1
+		if next < len(tokens) && tokens[next].text == "recursive" {
+			next++
+		}
+		// The CTEs of a with clause are separated by commas
+		for {
+			name, end := cteDefinition(tokens, next)
+			if name == "" {
+				break
+			}
+			ctes[name] = true
+			if end >= len(tokens) || tokens[end].text != "," {
+				break
+			}
+			next = end + 1
+		}
+	}
+
+	// queryFrame is the state of each level of parentheses
+	type queryFrame struct {
+		function bool // the parentheses are the arguments of a function call
+		inFrom   bool // the tokens are in a from clause
+	}
+	frames := []*queryFrame{{}}
+
+	var names [][]string
+	for i, token := range tokens {
+		frame := frames[len(frames)-1]
+		switch {
+		case token.kind == queryTokenQuotedIdentifier:
+			continue
+		case token.text == "(":
+			function := i > 0 && tokens[i-1].kind == queryTokenWord && !queryKeywords[tokens[i-1].text]
+			frames = append(frames, &queryFrame{function: function})
+			continue
+		case token.text == ")":
+			if len(frames) > 1 {
+				frames = frames[:len(frames)-1]
+			}
+			continue
+		case frame.function:
+			continue
+		}
+
+		switch {
+		case token.text == "from" && (i == 0 || tokens[i-1].text != "distinct"), token.text == "join":
+			// `a is distinct from b` compares values rather than naming a table
+			frame.inFrom = true
+		case token.text == "," && frame.inFrom:
+		case token.text == "update" && i > 0 && (tokens[i-1].text == "for" || tokens[i-1].text == "key"):
+			// A locking clause, e.g. for update of t, names a table of the from
+			// clause rather than another table
+			continue
+		case token.text == "into", token.text == "update", token.text == "table":
+		default:
+			if token.kind == queryTokenWord && endsFromClause(token.text) {
+				frame.inFrom = false
+			}
+			continue
+		}
+
+		name, next := qualifiedName(tokens, i+1)
+		if name == nil {
+			continue
+		}
+		// A table function, e.g. from jsonb_array_elements(...)
+		if next < len(tokens) && tokens[next].text == "(" && token.text != "into" {
+			continue
+		}
+		if len(name) > 1 || !ctes[name[0]] {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// cteDefinition returns the name of the CTE defined at tokens[i], i.e.
+// `name [(columns)] as [[not] materialized] (query)`, along with the index of
+// the token after it. The name is blank if there is no CTE at i.
+func cteDefinition(tokens []queryToken, i int) (string, int) {
+	if i >= len(tokens) || !isIdentifier(tokens[i]) {
+		return "", i
+	}
+	name := tokens[i].text
+	next := i + 1
+	if next < len(tokens) && tokens[next].text == "(" {
+		next = skipParentheses(tokens, next)
+	}
+	if next >= len(tokens) || tokens[next].text != "as" {
+		return "", i
+	}
+	next++
+	if next < len(tokens) && tokens[next].text == "not" {
+		next++
+	}
+	if next < len(tokens) && tokens[next].text == "materialized" {
+		next++
+	}
+	if next >= len(tokens) || tokens[next].text != "(" {
+		return "", i
+	}
+	return name, skipParentheses(tokens, next)
+}
+
+// skipParentheses returns the index of the token after the parenthesis which
+// closes the one at tokens[i].
+func skipParentheses(tokens []queryToken, i int) int {
+	depth := 0
+	for ; i < len(tokens); i++ {
+		switch tokens[i].text {
+		case "(":
+			depth++
+		case ")":
+			depth--
+			if depth == 0 {
+				return i + 1
+			}
+		}
+	}
+	return i
+}
+
+// endsFromClause reports whether a word starts the clause after a from
+// clause, after which commas no longer separate tables.
+func endsFromClause(word string) bool {
+	switch word {
+	case "except", "fetch", "for", "group", "having", "intersect", "limit", "offset", "order", "returning", "select", "set", "union", "values", "where", "window":
+		return true
+	}
+	return false
+}
+
+// qualifiedName returns the dot separated name starting at tokens[i], along
+// with the index of the token after it, or nil if there is no name at i.
+func qualifiedName(tokens []queryToken, i int) ([]string, int) {
+	for i < len(tokens) && tokens[i].kind == queryTokenWord && (tokens[i].text == "only" || tokens[i].text == "lateral") {
+		i++
+	}
+	if i >= len(tokens) || !isIdentifier(tokens[i]) || tokens[i].kind == queryTokenWord && (queryKeywords[tokens[i].text] || isClauseKeyword(tokens[i].text)) {
+		return nil, i
+	}
+	name := []string{tokens[i].text}
+	i++
+	for i+1 < len(tokens) && tokens[i].text == "." && isIdentifier(tokens[i+1]) {
+		name = append(name, tokens[i+1].text)
+		i += 2
+	}
+	return name, i
+}
+
+// isClauseKeyword reports whether a word starts a clause or a join, so it
+// can't be a table name.
+func isClauseKeyword(word string) bool {
+	switch word {
+	case "cross", "fetch", "for", "full", "group", "having", "inner", "left", "limit", "natural", "offset", "order", "outer", "returning", "right", "set", "window":
+		return true
+	}
+	return false
+}
+
+func isIdentifier(token queryToken) bool {
+	return token.kind == queryTokenWord || token.kind == queryTokenQuotedIdentifier
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
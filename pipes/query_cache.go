@@ -0,0 +1,49 @@
+package pipes
+
+import (
+	"runtime"
+	"sync"
+	"weak"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+)
+
+// queryCaches holds the values cached for each running query, keyed by a weak
+// pointer to the query's context. The plugin SDK shares one QueryContext
+// between all the QueryData of a query, including those of child list and
+// hydrate calls, and drops it when the query ends. The cache of a query is
+// removed once its QueryContext is garbage collected.
+var queryCaches sync.Map // weak.Pointer[plugin.QueryContext] -> *sync.Map
+
+// queryCacheEntry is a value cached for a query. The value is fetched once,
+// by the first caller, and the others wait for it.
+type queryCacheEntry struct {
+	once  sync.Once
+	value interface{}
+	err   error
+}
+
+// getQueryCached returns the value cached under key for the current query,
+// calling fetch to get it on first use. Unlike the connection cache, values
+// are never shared between queries, so they can't go stale, and large values
+// such as snapshots are not kept once the query ends.
+func getQueryCached(d *plugin.QueryData, key string, fetch func() (interface{}, error)) (interface{}, error) {
+	if d.QueryContext == nil {
+		return fetch()
+	}
+
+	queryKey := weak.Make(d.QueryContext)
+	cache, loaded := queryCaches.LoadOrStore(queryKey, &sync.Map{})
+	if !loaded {
+		runtime.AddCleanup(d.QueryContext, func(queryKey weak.Pointer[plugin.QueryContext]) {
+			queryCaches.Delete(queryKey)
+		}, queryKey)
+	}
+
+	value, _ := cache.(*sync.Map).LoadOrStore(key, &queryCacheEntry{})
+	entry := value.(*queryCacheEntry)
+	entry.once.Do(func() {
+		entry.value, entry.err = fetch()
+	})
+	return entry.value, entry.err
+}
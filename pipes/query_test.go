@@ -0,0 +1,132 @@
+package pipes
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseQueryMetadata(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  QueryMetadata
+	}{
+		{
+			name:  "literals are stripped",
+			query: "SELECT name,  region FROM aws_prod.aws_s3_bucket\nWHERE region = 'us-east-1' and versioning_enabled = true and size > 1.5e3 and tags ->> 'env' in ('prod', 'it''s')",
+			want: QueryMetadata{
+				QueryFingerprint:  "select name, region from aws_prod.aws_s3_bucket where region = ? and versioning_enabled = true and size > ? and tags ->> ? in (?)",
+				StatementType:     "select",
+				ReferencedSchemas: []string{"aws_prod"},
+				ReferencedTables:  []string{"aws_prod.aws_s3_bucket"},
+				QuerySource:       querySourceInteractive,
+			},
+		},
+		{
+			name:  "joins, subqueries and aliases",
+			query: `select count(*) from aws_ec2_instance i join aws_vpc v on v.vpc_id = i.vpc_id, "Github"."Repo" r where i.instance_id in (select instance_id from aws_all.aws_ebs_volume_attachment) and extract(year from i.launch_time) = $1`,
+			want: QueryMetadata{
+				QueryFingerprint:  `select count(*) from aws_ec2_instance i join aws_vpc v on v.vpc_id = i.vpc_id, "Github"."Repo" r where i.instance_id in (select instance_id from aws_all.aws_ebs_volume_attachment) and extract(year from i.launch_time) = ?`,
+				StatementType:     "select",
+				ReferencedSchemas: []string{"Github", "aws_all"},
+				ReferencedTables:  []string{"Github.Repo", "aws_all.aws_ebs_volume_attachment", "aws_ec2_instance", "aws_vpc"},
+				QuerySource:       querySourceInteractive,
+			},
+		},
+		{
+			name:  "ctes and table functions are not tables",
+			query: "with buckets as (select name, tags from aws_s3_bucket) insert into reports.bucket_tags(name, tag) select b.name, t.key from buckets b, jsonb_each_text(b.tags) t where b.name is distinct from 'x'",
+			want: QueryMetadata{
+				QueryFingerprint:  "with buckets as (select name, tags from aws_s3_bucket) insert into reports.bucket_tags(name, tag) select b.name, t.key from buckets b, jsonb_each_text(b.tags) t where b.name is distinct from ?",
+				StatementType:     "insert",
+				ReferencedSchemas: []string{"reports"},
+				ReferencedTables:  []string{"aws_s3_bucket", "reports.bucket_tags"},
+				QuerySource:       querySourceInteractive,
+			},
+		},
+		{
+			name:  "column aliases are not ctes",
+			query: "select id, name as n, title, aws_account as acct from aws_account join name on true",
+			want: QueryMetadata{
+				QueryFingerprint:  "select id, name as n, title, aws_account as acct from aws_account join name on true",
+				StatementType:     "select",
+				ReferencedSchemas: []string{},
+				ReferencedTables:  []string{"aws_account", "name"},
+				QuerySource:       querySourceInteractive,
+			},
+		},
+		{
+			name:  "cte column lists and materialization",
+			query: "with recursive a(id) as materialized (select vpc_id from aws_vpc), b as not materialized (select id from a) select * from b, aws_subnet",
+			want: QueryMetadata{
+				QueryFingerprint:  "with recursive a(id) as materialized (select vpc_id from aws_vpc), b as not materialized (select id from a) select * from b, aws_subnet",
+				StatementType:     "select",
+				ReferencedSchemas: []string{},
+				ReferencedTables:  []string{"aws_subnet", "aws_vpc"},
+				QuerySource:       querySourceInteractive,
+			},
+		},
+		{
+			name:  "locking clauses do not name tables",
+			query: "select name from aws_s3_bucket b for update of b; select 1 from t for no key update of t",
+			want: QueryMetadata{
+				QueryFingerprint:  "select name from aws_s3_bucket b for update of b; select ? from t for no key update of t",
+				StatementType:     "select",
+				ReferencedSchemas: []string{},
+				ReferencedTables:  []string{"aws_s3_bucket", "t"},
+				QuerySource:       querySourceInteractive,
+			},
+		},
+		{
+			name:  "comments are dropped",
+			query: "/* dashboard /* nested */ panel */ update t set note = E'it\\'s' -- trailing\n where id = 1;",
+			want: QueryMetadata{
+				QueryFingerprint:  "update t set note = ? where id = ?",
+				StatementType:     "update",
+				ReferencedSchemas: []string{},
+				ReferencedTables:  []string{"t"},
+				QuerySource:       querySourceInteractive,
+			},
+		},
+		{
+			name:  "dashboard query",
+			query: "-- aws_compliance.control.cis_v300_1_4\nselect arn as resource from aws_iam_user where mfa_enabled = $$no$$",
+			want: QueryMetadata{
+				QueryFingerprint:  "select arn as resource from aws_iam_user where mfa_enabled = ?",
+				StatementType:     "select",
+				ReferencedSchemas: []string{},
+				ReferencedTables:  []string{"aws_iam_user"},
+				QuerySource:       querySourceDashboard,
+			},
+		},
+		{
+			name:  "catalog query",
+			query: "select c.relname from pg_catalog.pg_class c join pg_namespace n on n.oid = c.relnamespace",
+			want: QueryMetadata{
+				QueryFingerprint:  "select c.relname from pg_catalog.pg_class c join pg_namespace n on n.oid = c.relnamespace",
+				StatementType:     "select",
+				ReferencedSchemas: []string{"pg_catalog"},
+				ReferencedTables:  []string{"pg_catalog.pg_class", "pg_namespace"},
+				QuerySource:       querySourceSystem,
+			},
+		},
+		{
+			name:  "session statement",
+			query: "SET search_path TO aws_prod, public",
+			want: QueryMetadata{
+				QueryFingerprint:  "set search_path to aws_prod, public",
+				StatementType:     "set",
+				ReferencedSchemas: []string{},
+				ReferencedTables:  []string{},
+				QuerySource:       querySourceSystem,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseQueryMetadata(tt.query); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseQueryMetadata() = %#v\nwant %#v", got, tt.want)
+			}
+		})
+	}
+}
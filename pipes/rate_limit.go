@@ -0,0 +1,92 @@
+package pipes
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/rate_limiter"
+	"golang.org/x/time/rate"
+)
+
+// rateLimiterScopeIdentity is the rate limiter scope holding the identity a
+// request is made for, e.g. "org/acme". Requests that are not made for a user,
+// org or tenant, e.g. listing the connection user's orgs, have a blank identity.
+const rateLimiterScopeIdentity = "identity"
+
+// newIdentityRateLimiter returns the definition of the per identity limiter
+// for the connection config, or nil if `requests_per_second` is not set. Each
+// identity may make up to requests_per_second requests every second, with
+// bursts of the same size.
+func newIdentityRateLimiter(config pipesConfig) (*rate_limiter.Definition, error) {
+	if config.RequestsPerSecond == nil {
+		return nil, nil
+	}
+	requestsPerSecond := *config.RequestsPerSecond
+	if requestsPerSecond < 1 {
+		return nil, fmt.Errorf("requests_per_second must be 1 or more, got %d", requestsPerSecond)
+	}
+
+	def := &rate_limiter.Definition{
+		Name:       "pipes_api_identity",
+		FillRate:   rate.Limit(requestsPerSecond),
+		BucketSize: int64(requestsPerSecond),
+		Scope:      []string{rateLimiterScopeIdentity},
+	}
+	if err := def.Initialise(); err != nil {
+		return nil, err
+	}
+	return def, nil
+}
+
+// rateLimitTransport waits for the limiter of the identity a request is made
+// for before sending it. This is done per request rather than with the plugin
+// SDK's hydrate rate limiters, as the scope values of those are fixed per
+// hydrate function while a single list hydrate may call the API for many
+// identities, e.g. pipes_audit_log.
+type rateLimitTransport struct {
+	base     http.RoundTripper
+	def      *rate_limiter.Definition
+	limiters *rate_limiter.LimiterMap
+}
+
+func (t *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	scopeValues := map[string]string{rateLimiterScopeIdentity: requestIdentity(req)}
+	limiter, err := t.limiters.GetOrCreate(t.def, scopeValues)
+	if err != nil {
+		return nil, err
+	}
+	rate_limiter.NewMultiLimiter([]*rate_limiter.HydrateLimiter{limiter}, scopeValues).Wait(req.Context())
+	if err := req.Context().Err(); err != nil {
+		return nil, err
+	}
+	return t.base.RoundTrip(req)
+}
+
+// requestIdentity returns the identity an API request is made for, from its
+// path, e.g. "org/acme" for /api/v0/org/acme/workspace.
+func requestIdentity(req *http.Request) string {
+	segments := strings.Split(strings.Trim(req.URL.Path, "/"), "/")
+
+	// Skip the server path, e.g. /api/v0 or /download
+	for len(segments) > 0 && isServerPathSegment(segments[0]) {
+		segments = segments[1:]
+	}
+
+	if len(segments) < 2 {
+		return ""
+	}
+	switch segments[0] {
+	case "user", "org", "tenant":
+		return segments[0] + "/" + segments[1]
+	}
+	return ""
+}
+
+func isServerPathSegment(segment string) bool {
+	switch segment {
+	case "api", "download":
+		return true
+	}
+	return len(segment) > 1 && segment[0] == 'v' && strings.Trim(segment[1:], "0123456789") == ""
+}
@@ -0,0 +1,86 @@
+package pipes
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/rate_limiter"
+)
+
+func TestRequestIdentity(t *testing.T) {
+	tests := map[string]string{
+		"/api/v0/user/alice/token":        "user/alice",
+		"/api/v0/org/acme/workspace/prod": "org/acme",
+		"/api/v0/tenant/acme/usage":       "tenant/acme",
+		"/download/org/acme/snapshot/s1":  "org/acme",
+		"/user/alice/token":               "user/alice",
+		"/api/v0/actor/org/invite":        "",
+		"/api/v0/actor":                   "",
+		"/api/v0/user":                    "",
+	}
+	for path, want := range tests {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if got := requestIdentity(req); got != want {
+			t.Errorf("requestIdentity(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestNewIdentityRateLimiter(t *testing.T) {
+	intPtr := func(i int) *int { return &i }
+
+	def, err := newIdentityRateLimiter(pipesConfig{})
+	if err != nil || def != nil {
+		t.Errorf("newIdentityRateLimiter() = %v, %v, want no limiter", def, err)
+	}
+
+	def, err = newIdentityRateLimiter(pipesConfig{RequestsPerSecond: intPtr(5)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if def.FillRate != 5 || def.BucketSize != 5 {
+		t.Errorf("limiter = %s, want 5 requests per second", def)
+	}
+
+	if _, err := newIdentityRateLimiter(pipesConfig{RequestsPerSecond: intPtr(0)}); err == nil {
+		t.Error("newIdentityRateLimiter(0) error = nil, want error")
+	}
+}
+
+func TestRateLimitTransportPerIdentity(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	requestsPerSecond := 2
+	def, err := newIdentityRateLimiter(pipesConfig{RequestsPerSecond: &requestsPerSecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &http.Client{Transport: &rateLimitTransport{
+		base:     server.Client().Transport,
+		def:      def,
+		limiters: rate_limiter.NewLimiterMap(),
+	}}
+	get := func(path string) time.Duration {
+		start := time.Now()
+		resp, err := client.Get(server.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return time.Since(start)
+	}
+
+	// the burst for each identity is sent straight away
+	for _, path := range []string{"/org/acme/workspace", "/org/acme/workspace", "/user/alice/token", "/user/alice/token"} {
+		if elapsed := get(path); elapsed > 200*time.Millisecond {
+			t.Errorf("GET %s waited %s, want no wait", path, elapsed)
+		}
+	}
+	// acme has used its burst, so waits for the limiter
+	if elapsed := get("/org/acme/workspace"); elapsed < 300*time.Millisecond {
+		t.Errorf("GET after burst waited %s, want about 500ms", elapsed)
+	}
+}
@@ -0,0 +1,207 @@
+package pipes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// Defaults for the retry connection config arguments. These match the limits
+// of the plugin SDK retry config the plugin used previously.
+const (
+	defaultMaxRetries    = 12
+	defaultMinRetryDelay = 250  // milliseconds
+	defaultMaxRetryDelay = 2000 // milliseconds
+)
+
+// maxRetryDuration caps the total time spent retrying a request. A retry whose
+// delay would end after the cap is not made, and the last response is returned.
+const maxRetryDuration = 30 * time.Second
+
+// retryPolicy controls how failed API requests are retried.
+type retryPolicy struct {
+	// maxRetries is the number of times a request is retried, 0 disables retries.
+	maxRetries int
+	// minDelay is the delay before the first retry, doubling for each retry
+	// after it up to maxDelay.
+	minDelay time.Duration
+	maxDelay time.Duration
+	// maxDuration is the total time allowed for retrying a request.
+	maxDuration time.Duration
+}
+
+// newRetryPolicy returns the retry policy for the connection config, using
+// the defaults for any arguments that are not set.
+func newRetryPolicy(config pipesConfig) (retryPolicy, error) {
+	maxRetries := defaultMaxRetries
+	if config.MaxRetries != nil {
+		maxRetries = *config.MaxRetries
+	}
+	minDelay := defaultMinRetryDelay
+	if config.MinRetryDelay != nil {
+		minDelay = *config.MinRetryDelay
+	}
+	maxDelay := defaultMaxRetryDelay
+	if config.MaxRetryDelay != nil {
+		maxDelay = *config.MaxRetryDelay
+	}
+
+	if maxRetries < 0 {
+		return retryPolicy{}, fmt.Errorf("max_retries must be 0 or more, got %d", maxRetries)
+	}
+	if minDelay < 1 {
+		return retryPolicy{}, fmt.Errorf("min_retry_delay must be 1 or more, got %d", minDelay)
+	}
+	if maxDelay < minDelay {
+		return retryPolicy{}, fmt.Errorf("max_retry_delay (%d) must not be less than min_retry_delay (%d)", maxDelay, minDelay)
+	}
+
+	return retryPolicy{
+		maxRetries:  maxRetries,
+		minDelay:    time.Duration(minDelay) * time.Millisecond,
+		maxDelay:    time.Duration(maxDelay) * time.Millisecond,
+		maxDuration: maxRetryDuration,
+	}, nil
+}
+
+// backoff returns the delay before the given retry, counting from 0. The
+// delay is exponential with full jitter, between minDelay and the capped
+// exponential delay.
+func (p retryPolicy) backoff(retry int) time.Duration {
+	delay := p.maxDelay
+	if retry < 32 {
+		if d := p.minDelay << retry; d > 0 && d < p.maxDelay {
+			delay = d
+		}
+	}
+	return p.minDelay + time.Duration(rand.Int63n(int64(delay-p.minDelay)+1))
+}
+
+// retryTransport retries requests to the Pipes API that fail with a rate
+// limit (429), a server error (5xx) or a dropped connection. The API's
+// Retry-After header is honored when it is set.
+//
+// Retries are done here rather than with the plugin SDK's retry config, as
+// they are configured per connection and need the response headers.
+type retryTransport struct {
+	base   http.RoundTripper
+	policy retryPolicy
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	for retry := 0; ; retry++ {
+		resp, err := t.base.RoundTrip(req)
+		if retry >= t.policy.maxRetries || !shouldRetryRequest(req, resp, err) {
+			return resp, err
+		}
+
+		delay := t.policy.backoff(retry)
+		if resp != nil {
+			if retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+				delay = retryAfter
+			}
+		}
+		if t.policy.maxDuration > 0 && time.Since(start)+delay > t.policy.maxDuration {
+			return resp, err
+		}
+
+		// Requests with a body can only be retried if the body can be rewound
+		if req.Body != nil && req.Body != http.NoBody {
+			if req.GetBody == nil {
+				return resp, err
+			}
+			body, bodyErr := req.GetBody()
+			if bodyErr != nil {
+				return resp, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+
+		if resp != nil {
+			log.Printf("[WARN] retryTransport: %s %s returned %d, retrying in %s", req.Method, req.URL.Path, resp.StatusCode, delay)
+			// Drain the body so that the connection can be reused
+			_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+			resp.Body.Close()
+		} else {
+			log.Printf("[WARN] retryTransport: %s %s failed: %v, retrying in %s", req.Method, req.URL.Path, err, delay)
+		}
+
+		if err := sleepContext(req.Context(), delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// shouldRetryRequest reports whether a request should be retried. Rate limited
+// requests were not processed, so are always retried. Server errors and
+// dropped connections are only retried for idempotent requests.
+func shouldRetryRequest(req *http.Request, resp *http.Response, err error) bool {
+	if req.Context().Err() != nil {
+		return false
+	}
+	if err == nil && resp.StatusCode == http.StatusTooManyRequests {
+		return true
+	}
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+	default:
+		return false
+	}
+	if err != nil {
+		return isConnectionReset(err)
+	}
+	return resp.StatusCode >= 500 && resp.StatusCode != http.StatusNotImplemented
+}
+
+// isConnectionReset reports whether the error is the connection being reset or
+// closed by the server.
+func isConnectionReset(err error) bool {
+	return errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.EPIPE) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// parseRetryAfter parses a Retry-After header, which is either a number of
+// seconds or an HTTP date.
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		delay := time.Until(date)
+		if delay < 0 {
+			delay = 0
+		}
+		return delay, true
+	}
+	return 0, false
+}
+
+// sleepContext waits for the delay, returning early with the context's error
+// if it is cancelled.
+func sleepContext(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
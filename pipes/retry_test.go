@@ -0,0 +1,191 @@
+package pipes
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRetryTransport(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		failures   []int
+		maxRetries int
+		wantStatus int
+		wantCalls  int32
+	}{
+		{name: "rate limit", method: http.MethodGet, failures: []int{429, 429}, maxRetries: 3, wantStatus: 200, wantCalls: 3},
+		{name: "server error", method: http.MethodGet, failures: []int{502, 503}, maxRetries: 3, wantStatus: 200, wantCalls: 3},
+		{name: "retries exhausted", method: http.MethodGet, failures: []int{500, 500, 500}, maxRetries: 2, wantStatus: 500, wantCalls: 3},
+		{name: "retries disabled", method: http.MethodGet, failures: []int{429}, maxRetries: 0, wantStatus: 429, wantCalls: 1},
+		{name: "client error", method: http.MethodGet, failures: []int{403}, maxRetries: 3, wantStatus: 403, wantCalls: 1},
+		{name: "not implemented", method: http.MethodGet, failures: []int{501}, maxRetries: 3, wantStatus: 501, wantCalls: 1},
+		{name: "rate limited post", method: http.MethodPost, failures: []int{429}, maxRetries: 3, wantStatus: 200, wantCalls: 2},
+		{name: "server error on post", method: http.MethodPost, failures: []int{503}, maxRetries: 3, wantStatus: 503, wantCalls: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if n := int(calls.Add(1)); n <= len(tt.failures) {
+					w.WriteHeader(tt.failures[n-1])
+				}
+			}))
+			defer server.Close()
+
+			client := &http.Client{Transport: &retryTransport{
+				base:   server.Client().Transport,
+				policy: retryPolicy{maxRetries: tt.maxRetries, minDelay: time.Millisecond, maxDelay: 5 * time.Millisecond},
+			}}
+			req, _ := http.NewRequest(tt.method, server.URL, nil)
+			resp, err := client.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if got := calls.Load(); got != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", got, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestRetryTransportConnectionReset(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			// drop the connection without a response
+			conn, _, _ := w.(http.Hijacker).Hijack()
+			conn.Close()
+		}
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: &retryTransport{
+		base:   server.Client().Transport,
+		policy: retryPolicy{maxRetries: 3, minDelay: time.Millisecond, maxDelay: 5 * time.Millisecond},
+	}}
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if got := calls.Load(); got != 2 {
+		t.Errorf("calls = %d, want 2", got)
+	}
+}
+
+func TestRetryTransportRetryAfter(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+		}
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: &retryTransport{
+		base:   server.Client().Transport,
+		policy: retryPolicy{maxRetries: 3, minDelay: time.Millisecond, maxDelay: 5 * time.Millisecond},
+	}}
+	start := time.Now()
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("retried after %s, want at least the 1s Retry-After", elapsed)
+	}
+	if got := calls.Load(); got != 2 {
+		t.Errorf("calls = %d, want 2", got)
+	}
+}
+
+func TestRetryTransportMaxDuration(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Retry-After", "60")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: &retryTransport{
+		base:   server.Client().Transport,
+		policy: retryPolicy{maxRetries: 3, minDelay: time.Millisecond, maxDelay: 5 * time.Millisecond, maxDuration: time.Second},
+	}}
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	// the Retry-After delay is past the cap, so the request is not retried
+	if resp.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("status = %d, want 429", resp.StatusCode)
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("calls = %d, want 1", got)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	tests := []struct {
+		value  string
+		want   time.Duration
+		wantOk bool
+	}{
+		{value: "", wantOk: false},
+		{value: "3", want: 3 * time.Second, wantOk: true},
+		{value: "-1", wantOk: false},
+		{value: "soon", wantOk: false},
+		{value: "Mon, 02 Jan 2006 15:04:05 GMT", want: 0, wantOk: true},
+	}
+	for _, tt := range tests {
+		got, ok := parseRetryAfter(tt.value)
+		if ok != tt.wantOk || got != tt.want {
+			t.Errorf("parseRetryAfter(%q) = %s, %v, want %s, %v", tt.value, got, ok, tt.want, tt.wantOk)
+		}
+	}
+}
+
+func TestNewRetryPolicy(t *testing.T) {
+	intPtr := func(i int) *int { return &i }
+
+	policy, err := newRetryPolicy(pipesConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if policy.maxRetries != 12 || policy.minDelay != 250*time.Millisecond || policy.maxDelay != 2*time.Second || policy.maxDuration != 30*time.Second {
+		t.Errorf("default policy = %+v", policy)
+	}
+
+	invalid := []pipesConfig{
+		{MaxRetries: intPtr(-1)},
+		{MinRetryDelay: intPtr(0)},
+		{MinRetryDelay: intPtr(500), MaxRetryDelay: intPtr(100)},
+	}
+	for _, config := range invalid {
+		if _, err := newRetryPolicy(config); err == nil {
+			t.Errorf("newRetryPolicy(%+v) error = nil, want error", config)
+		}
+	}
+
+	policy = retryPolicy{minDelay: 10 * time.Millisecond, maxDelay: 40 * time.Millisecond}
+	for retry := 0; retry < 40; retry++ {
+		if d := policy.backoff(retry); d < policy.minDelay || d > policy.maxDelay {
+			t.Errorf("backoff(%d) = %s, want between %s and %s", retry, d, policy.minDelay, policy.maxDelay)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package pipes
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+)
+
+func TestWorkspaceSchema(t *testing.T) {
+	api := newFakePipesAPI(t, "pipes_api.json")
+	p := newTestPlugin(t, api)
+
+	rows := p.MustQuery(testQuery{
+		Table:   "pipes_workspace_schema",
+		Columns: []string{"name", "type", "plugin", "connection_handle", "aggregator_handle", "search_path_position", "workspace_handle"},
+	})
+
+	if got := rowStrings(rows, "name"); !reflect.DeepEqual(got, []string{"all_aws", "aws_prod", "dt"}) {
+		t.Fatalf("names = %v, want [all_aws aws_prod dt]", got)
+	}
+	if got := rowStrings(rows, "plugin"); !reflect.DeepEqual(got, []string{"", "aws", "aws"}) {
+		t.Errorf("plugins = %v, want [ aws aws]", got)
+	}
+	for _, row := range rows {
+		name := row.Columns["name"].GetStringValue()
+		if got := row.Columns["workspace_handle"].GetStringValue(); got != "dev" {
+			t.Errorf("%s workspace_handle = %q, want dev", name, got)
+		}
+		// only all_aws is in the workspace's search path
+		wantPosition := map[string]int64{"all_aws": 1}[name]
+		if got := row.Columns["search_path_position"].GetIntValue(); got != wantPosition {
+			t.Errorf("%s search_path_position = %d, want %d", name, got, wantPosition)
+		}
+		source := row.Columns["connection_handle"].GetStringValue() + row.Columns["aggregator_handle"].GetStringValue()
+		if want := map[string]string{"aws_prod": "aws_prod", "all_aws": "all_aws"}[name]; source != want {
+			t.Errorf("%s source handle = %q, want %q", name, source, want)
+		}
+	}
+}
+
+func TestWorkspaceSchemaTable(t *testing.T) {
+	api := newFakePipesAPI(t, "pipes_api.json")
+	p := newTestPlugin(t, api)
+
+	rows := p.MustQuery(testQuery{
+		Table:   "pipes_workspace_schema_table",
+		Columns: []string{"schema_name", "name", "column_count", "plugin", "workspace_id"},
+	})
+
+	var got []string
+	for _, row := range rows {
+		got = append(got, row.Columns["schema_name"].GetStringValue()+"."+row.Columns["name"].GetStringValue())
+		if row.Columns["name"].GetStringValue() == "aws_s3_bucket" && row.Columns["column_count"].GetIntValue() != 3 {
+			t.Errorf("aws_s3_bucket column_count = %d, want 3", row.Columns["column_count"].GetIntValue())
+		}
+	}
+	want := []string{"all_aws.aws_s3_bucket", "aws_prod.aws_iam_role", "aws_prod.aws_s3_bucket"}
+	sort.Strings(got)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("tables = %v, want %v", got, want)
+	}
+}
+
+func TestWorkspaceSchemaTableForSchema(t *testing.T) {
+	api := newFakePipesAPI(t, "pipes_api.json")
+	p := newTestPlugin(t, api)
+
+	rows := p.MustQuery(testQuery{
+		Table:   "pipes_workspace_schema_table",
+		Columns: []string{"name", "schema_type"},
+		Quals: []*proto.Qual{
+			qual("workspace_id", "=", stringQualValue("w_dev")),
+			qual("schema_name", "=", stringQualValue("aws_prod")),
+		},
+	})
+
+	if got := rowStrings(rows, "name"); !reflect.DeepEqual(got, []string{"aws_iam_role", "aws_s3_bucket"}) {
+		t.Errorf("names = %v, want [aws_iam_role aws_s3_bucket]", got)
+	}
+	// a single schema needs no schema list
+	if got := len(api.Requests("GET /user/alice/workspace/w_dev/schema")); got != 0 {
+		t.Errorf("schema list requests = %d, want 0", got)
+	}
+}
@@ -0,0 +1,479 @@
+package pipes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	openapi "github.com/turbot/pipes-sdk-go"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+)
+
+// controlResultColumns are the columns every control returns. The other
+// columns of a control's rows are its dimensions, apart from those prefixed
+// with "_", which Powerpipe adds for its own use.
+var controlResultColumns = map[string]bool{
+	"reason":   true,
+	"resource": true,
+	"status":   true,
+}
+
+// SnapshotPanel is a panel of a downloaded snapshot. Only the fields common
+// to the panel types are decoded; the rest are left in Properties.
+type SnapshotPanel struct {
+	Name        string                 `json:"name"`
+	PanelType   string                 `json:"panel_type"`
+	DisplayType string                 `json:"display_type"`
+	Title       string                 `json:"title"`
+	Description string                 `json:"description"`
+	Dashboard   string                 `json:"dashboard"`
+	Status      string                 `json:"status"`
+	Error       string                 `json:"error"`
+	Severity    string                 `json:"severity"`
+	Tags        map[string]interface{} `json:"tags"`
+	Summary     map[string]interface{} `json:"summary"`
+	Properties  map[string]interface{} `json:"properties"`
+	Data        *SnapshotPanelData     `json:"data"`
+	ParentName  string                 `json:"-"`
+}
+
+// SnapshotPanelData is the result of a panel's query.
+type SnapshotPanelData struct {
+	Columns []SnapshotPanelColumn    `json:"columns"`
+	Rows    []map[string]interface{} `json:"rows"`
+}
+
+// SnapshotPanelColumn is a column of a panel's query result.
+type SnapshotPanelColumn struct {
+	Name     string `json:"name"`
+	DataType string `json:"data_type"`
+}
+
+// SnapshotControlResult is a row returned by a control panel of a snapshot.
+type SnapshotControlResult struct {
+	Control    *SnapshotPanel
+	Status     string
+	Reason     string
+	Resource   string
+	Dimensions map[string]interface{}
+}
+
+// snapshotPanels decodes the panels of a snapshot, sorted by name. Each panel
+// is given the name of the panel it is nested in according to the layout.
+func snapshotPanels(data openapi.WorkspaceSnapshotData) ([]*SnapshotPanel, error) {
+	parents := map[string]string{}
+	var walk func(node openapi.WorkspaceSnapshotDataLayout)
+	walk = func(node openapi.WorkspaceSnapshotDataLayout) {
+		if node.Children == nil {
+			return
+		}
+		for _, child := range *node.Children {
+			// A panel can appear more than once in the layout, keep the first parent
+			if _, ok := parents[child.Name]; !ok {
+				parents[child.Name] = node.Name
+			}
+			walk(child)
+		}
+	}
+	walk(data.Layout)
+
+	panels := make([]*SnapshotPanel, 0, len(data.Panels))
+	for name, raw := range data.Panels {
+		encoded, err := json.Marshal(raw)
+		if err != nil {
+			return nil, err
+		}
+		panel := &SnapshotPanel{}
+		if err := json.Unmarshal(encoded, panel); err != nil {
+			return nil, fmt.Errorf("panel %s: %v", name, err)
+		}
+		if panel.Name == "" {
+			panel.Name = name
+		}
+		panel.ParentName = parents[name]
+		panels = append(panels, panel)
+	}
+	sort.Slice(panels, func(i, j int) bool { return panels[i].Name < panels[j].Name })
+	return panels, nil
+}
+
+// controlResults returns the results of a control panel, in the order the
+// control returned them. It returns nil for other panel types.
+func (p *SnapshotPanel) controlResults() []SnapshotControlResult {
+	if p.PanelType != "control" || p.Data == nil {
+		return nil
+	}
+
+	results := make([]SnapshotControlResult, 0, len(p.Data.Rows))
+	for _, row := range p.Data.Rows {
+		result := SnapshotControlResult{
+			Control:    p,
+			Status:     snapshotString(row["status"]),
+			Reason:     snapshotString(row["reason"]),
+			Resource:   snapshotString(row["resource"]),
+			Dimensions: map[string]interface{}{},
+		}
+		for column, value := range row {
+			if controlResultColumns[column] || strings.HasPrefix(column, "_") {
+				continue
+			}
+			result.Dimensions[column] = value
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// snapshotString returns a snapshot value as a string, e.g. a status.
+func snapshotString(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+//// SNAPSHOT HELPERS
+
+// snapshotContentFilterColumns maps the key columns of the snapshot content
+// tables to the snapshot list columns they filter on.
+var snapshotContentFilterColumns = [][2]string{
+	{"snapshot_id", "id"},
+	{"dashboard_name", "dashboard_name"},
+	{"snapshot_created_at", "created_at"},
+}
+
+// snapshotContentKeyColumns are the list key columns shared by the tables
+// over snapshot content. The snapshot quals are pushed into the snapshot list
+// filter, so that only the snapshots asked for are downloaded.
+func snapshotContentKeyColumns() []*plugin.KeyColumn {
+	return []*plugin.KeyColumn{
+		{
+			Name:    "identity_handle",
+			Require: plugin.Optional,
+		},
+		{
+			Name:    "identity_id",
+			Require: plugin.Optional,
+		},
+		{
+			Name:    "workspace_handle",
+			Require: plugin.Optional,
+		},
+		{
+			Name:    "workspace_id",
+			Require: plugin.Optional,
+		},
+		{
+			Name:      "snapshot_id",
+			Require:   plugin.Optional,
+			Operators: []string{"=", "<>"},
+		},
+		{
+			Name:      "dashboard_name",
+			Require:   plugin.Optional,
+			Operators: []string{"=", "<>", "~~", "!~~", "~~*", "!~~*"},
+		},
+		{
+			Name:      "snapshot_created_at",
+			Require:   plugin.Optional,
+			Operators: []string{">", ">=", "=", "<", "<="},
+		},
+	}
+}
+
+// listSnapshotsForContent lists the snapshots of the workspace matching the
+// snapshot quals, oldest first.
+func listSnapshotsForContent(ctx context.Context, d *plugin.QueryData, api identityService, workspace *openapi.Workspace) ([]openapi.WorkspaceSnapshot, error) {
+	filter := &queryFilter{}
+	for _, column := range snapshotContentFilterColumns {
+		if d.Quals[column[0]] == nil {
+			continue
+		}
+		for _, qual := range d.Quals[column[0]].Quals {
+			filter.Qual(column[1], qual)
+		}
+	}
+
+	snapshots, err := listAll(ctx, d, api.ListWorkspaceSnapshots(workspace.Handle, filter.String()))
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(snapshots, func(i, j int) bool { return snapshots[i].CreatedAt < snapshots[j].CreatedAt })
+	return snapshots, nil
+}
+
+// getSnapshotContent downloads the snapshot, once per snapshot however many
+// rows of the query need it. Snapshots can be large, so they are only kept
+// until the query ends rather than in the connection cache.
+func getSnapshotContent(ctx context.Context, d *plugin.QueryData, api identityService, workspaceHandle, snapshotId string) (openapi.WorkspaceSnapshotData, error) {
+	data, err := getQueryCached(d, "SnapshotContent-"+snapshotId, func() (interface{}, error) {
+		return api.DownloadWorkspaceSnapshot(ctx, workspaceHandle, snapshotId, "json")
+	})
+	if err != nil {
+		return openapi.WorkspaceSnapshotData{}, err
+	}
+	return data.(openapi.WorkspaceSnapshotData), nil
+}
+
+// getIdentityWorkspaceDetailsForSnapshotContent returns the identity and
+// workspace columns for a row of a table over snapshot content.
+func getIdentityWorkspaceDetailsForSnapshotContent(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	var snapshot openapi.WorkspaceSnapshot
+	switch item := h.Item.(type) {
+	case WorkspaceSnapshotPanel:
+		snapshot = item.Snapshot
+	case WorkspaceSnapshotControlResult:
+		snapshot = item.Snapshot
+	case WorkspaceSnapshotDownload:
+		snapshot = item.Snapshot
+	}
+	details, err := getIdentityWorkspaceDetailsForIds(ctx, d, h, snapshot.IdentityId, snapshot.WorkspaceId)
+	if err != nil {
+		plugin.Logger(ctx).Error("getIdentityWorkspaceDetailsForSnapshotContent", "error", err)
+		return nil, err
+	}
+	return details, nil
+}
+
+//// SNAPSHOT FILES
+
+// snapshotDownloadFormats are the formats snapshots can be downloaded in,
+// i.e. the file extensions accepted by the snapshot download API.
+var snapshotDownloadFormats = []string{"asff", "csv", "html", "json", "md"}
+
+// validateSnapshotDownloadFormat returns an error if snapshots cannot be
+// downloaded in the format.
+func validateSnapshotDownloadFormat(format string) error {
+	for _, f := range snapshotDownloadFormats {
+		if format == f {
+			return nil
+		}
+	}
+	return fmt.Errorf("unsupported snapshot format %q: must be one of %s", format, strings.Join(snapshotDownloadFormats, ", "))
+}
+
+// snapshotFileBody returns the body of a snapshot download. The API client
+// only knows how to decode JSON downloads, so the body is read from the
+// response, which it leaves readable, for downloads that succeeded.
+func snapshotFileBody(resp *http.Response, err error) ([]byte, error) {
+	if resp == nil || resp.StatusCode >= 300 {
+		return nil, classifyError(err)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// snapshotExportPath returns the path a snapshot is exported to in the export
+// directory. The path only depends on the snapshot and the format, so a
+// snapshot exported again overwrites its previous export.
+func snapshotExportPath(exportDir, identityHandle, workspaceHandle, snapshotId, format string) string {
+	return filepath.Join(exportDir, identityHandle, workspaceHandle, snapshotId+"."+format)
+}
+
+// writeSnapshotFile writes the snapshot content to the path. The content is
+// written to a temporary file which is then renamed, so that an export is
+// never left partially written.
+func writeSnapshotFile(path string, content []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	file, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	if _, err := file.Write(content); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(file.Name(), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), path)
+}
+
+//// SNAPSHOT DIFFS
+
+// SnapshotDiff is a difference between two snapshots: a panel or a control
+// result that was added, removed or changed.
+type SnapshotDiff struct {
+	ItemType          string
+	Change            string
+	PanelName         string
+	PanelType         string
+	Title             string
+	Resource          *string
+	FromStatus        *string
+	ToStatus          *string
+	FromReason        *string
+	ToReason          *string
+	Dimensions        map[string]interface{}
+	ChangedProperties []string
+}
+
+// diffSnapshotPanels returns the differences between the panels of two
+// snapshots, ordered by panel name. Each panel that differs is followed by
+// the differences between its control results, which are matched by
+// resource.
+func diffSnapshotPanels(from, to []*SnapshotPanel) []SnapshotDiff {
+	fromPanels := map[string]*SnapshotPanel{}
+	toPanels := map[string]*SnapshotPanel{}
+	var names []string
+	for _, panel := range from {
+		fromPanels[panel.Name] = panel
+		names = append(names, panel.Name)
+	}
+	for _, panel := range to {
+		toPanels[panel.Name] = panel
+		if fromPanels[panel.Name] == nil {
+			names = append(names, panel.Name)
+		}
+	}
+	sort.Strings(names)
+
+	var diffs []SnapshotDiff
+	for _, name := range names {
+		fromPanel, toPanel := fromPanels[name], toPanels[name]
+		panel := toPanel
+		if panel == nil {
+			panel = fromPanel
+		}
+		diff := SnapshotDiff{ItemType: "panel", PanelName: name, PanelType: panel.PanelType, Title: panel.Title}
+
+		switch {
+		case fromPanel == nil:
+			diff.Change = "added"
+			diff.ToStatus = &toPanel.Status
+		case toPanel == nil:
+			diff.Change = "removed"
+			diff.FromStatus = &fromPanel.Status
+		default:
+			diff.ChangedProperties = changedPanelProperties(fromPanel, toPanel)
+			if len(diff.ChangedProperties) == 0 {
+				continue
+			}
+			diff.Change = "changed"
+			diff.FromStatus = &fromPanel.Status
+			diff.ToStatus = &toPanel.Status
+		}
+		diffs = append(diffs, diff)
+
+		var fromResults, toResults []SnapshotControlResult
+		if fromPanel != nil {
+			fromResults = fromPanel.controlResults()
+		}
+		if toPanel != nil {
+			toResults = toPanel.controlResults()
+		}
+		diffs = append(diffs, diffControlResults(panel, fromResults, toResults)...)
+	}
+	return diffs
+}
+
+// changedPanelProperties returns the properties that differ between two
+// versions of a panel.
+func changedPanelProperties(from, to *SnapshotPanel) []string {
+	var changed []string
+	for _, property := range []struct {
+		name     string
+		from, to interface{}
+	}{
+		{"title", from.Title, to.Title},
+		{"parent_name", from.ParentName, to.ParentName},
+		{"status", from.Status, to.Status},
+		{"error", from.Error, to.Error},
+		{"severity", from.Severity, to.Severity},
+		{"summary", from.Summary, to.Summary},
+		{"properties", from.Properties, to.Properties},
+		{"data", from.Data, to.Data},
+	} {
+		if !reflect.DeepEqual(property.from, property.to) {
+			changed = append(changed, property.name)
+		}
+	}
+	return changed
+}
+
+// diffControlResults returns the differences between two versions of the
+// results of a control. Results are matched by resource; a result changed if
+// its status or reason differs.
+func diffControlResults(control *SnapshotPanel, from, to []SnapshotControlResult) []SnapshotDiff {
+	fromResults := controlResultsByKey(from)
+	toResults := controlResultsByKey(to)
+	var keys []string
+	for key := range fromResults {
+		keys = append(keys, key)
+	}
+	for key := range toResults {
+		if _, ok := fromResults[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	var diffs []SnapshotDiff
+	for _, key := range keys {
+		fromResult, inFrom := fromResults[key]
+		toResult, inTo := toResults[key]
+		result := toResult
+		if !inTo {
+			result = fromResult
+		}
+		diff := SnapshotDiff{
+			ItemType:   "control_result",
+			PanelName:  control.Name,
+			PanelType:  control.PanelType,
+			Title:      control.Title,
+			Resource:   &result.Resource,
+			Dimensions: result.Dimensions,
+		}
+
+		switch {
+		case !inFrom:
+			diff.Change = "added"
+		case !inTo:
+			diff.Change = "removed"
+		case fromResult.Status != toResult.Status || fromResult.Reason != toResult.Reason:
+			diff.Change = "changed"
+		default:
+			continue
+		}
+		if inFrom {
+			diff.FromStatus, diff.FromReason = &fromResult.Status, &fromResult.Reason
+		}
+		if inTo {
+			diff.ToStatus, diff.ToReason = &toResult.Status, &toResult.Reason
+		}
+		diffs = append(diffs, diff)
+	}
+	return diffs
+}
+
+// controlResultsByKey keys control results by resource. A control can return
+// more than one result for a resource, e.g. one per region, so repeated
+// resources are told apart by the order they were returned in.
+func controlResultsByKey(results []SnapshotControlResult) map[string]SnapshotControlResult {
+	byKey := map[string]SnapshotControlResult{}
+	seen := map[string]int{}
+	for _, result := range results {
+		key := fmt.Sprintf("%s\x00%d", result.Resource, seen[result.Resource])
+		seen[result.Resource]++
+		byKey[key] = result
+	}
+	return byKey
+}
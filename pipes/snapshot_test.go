@@ -0,0 +1,375 @@
+package pipes
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+)
+
+func TestWorkspaceSnapshotPanel(t *testing.T) {
+	api := newFakePipesAPI(t, "pipes_api.json")
+	p := newTestPlugin(t, api)
+
+	rows := p.MustQuery(testQuery{
+		Table:   "pipes_workspace_snapshot_panel",
+		Columns: []string{"snapshot_id", "name", "panel_type", "parent_name", "severity"},
+		Quals: []*proto.Qual{
+			qual("identity_handle", "=", stringQualValue("alice")),
+			qual("workspace_handle", "=", stringQualValue("dev")),
+		},
+	})
+
+	got := map[string]string{}
+	for _, row := range rows {
+		c := row.Columns
+		if c["snapshot_id"].GetStringValue() != "snap_dev_1" {
+			continue
+		}
+		got[c["name"].GetStringValue()] = c["panel_type"].GetStringValue() + "/" + c["parent_name"].GetStringValue() + "/" + c["severity"].GetStringValue()
+	}
+	want := map[string]string{
+		"aws_compliance.benchmark.cis_v300":   "benchmark//",
+		"aws_compliance.benchmark.cis_v300_1": "benchmark/aws_compliance.benchmark.cis_v300/",
+		"aws_compliance.control.cis_v300_1_4": "control/aws_compliance.benchmark.cis_v300_1/high",
+		"aws_compliance.control.cis_v300_1_5": "control/aws_compliance.benchmark.cis_v300_1/critical",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("snap_dev_1 panels = %v, want %v", got, want)
+	}
+	if len(rows) != 8 {
+		t.Errorf("rows = %d, want 8", len(rows))
+	}
+
+	// each snapshot is downloaded once, however many panels it has
+	for _, id := range []string{"snap_dev_1", "snap_dev_2"} {
+		if got := len(api.Requests("GET /download/user/alice/workspace/dev/snapshot/" + id + ".json")); got != 1 {
+			t.Errorf("%s downloads = %d, want 1", id, got)
+		}
+	}
+}
+
+func TestWorkspaceSnapshotPanelPerQuery(t *testing.T) {
+	api := newFakePipesAPI(t, "pipes_api.json")
+	p := newTestPlugin(t, api)
+
+	query := testQuery{
+		Table:   "pipes_workspace_snapshot_panel",
+		Columns: []string{"snapshot_id", "name"},
+		Quals: []*proto.Qual{
+			qual("identity_handle", "=", stringQualValue("alice")),
+			qual("workspace_handle", "=", stringQualValue("dev")),
+			qual("snapshot_id", "=", stringQualValue("snap_dev_1")),
+		},
+	}
+	p.MustQuery(query)
+	p.MustQuery(query)
+
+	// downloaded snapshots are only kept for the query that downloaded them
+	if got := len(api.Requests("GET /download/user/alice/workspace/dev/snapshot/snap_dev_1.json")); got != 2 {
+		t.Errorf("downloads = %d, want 2", got)
+	}
+}
+
+func TestWorkspaceSnapshotControlResult(t *testing.T) {
+	api := newFakePipesAPI(t, "pipes_api.json")
+	p := newTestPlugin(t, api)
+
+	rows := p.MustQuery(testQuery{
+		Table:   "pipes_workspace_snapshot_control_result",
+		Columns: []string{"snapshot_id", "snapshot_created_at", "control_name", "resource", "status", "reason", "dimensions", "workspace_handle"},
+		Quals: []*proto.Qual{
+			qual("identity_handle", "=", stringQualValue("alice")),
+			qual("workspace_handle", "=", stringQualValue("dev")),
+		},
+	})
+	if len(rows) != 6 {
+		t.Fatalf("rows = %d, want 6", len(rows))
+	}
+
+	// the status of each resource in each snapshot, to follow its trend
+	var trend []string
+	for _, row := range rows {
+		c := row.Columns
+		trend = append(trend, c["resource"].GetStringValue()+" "+c["snapshot_id"].GetStringValue()+" "+c["status"].GetStringValue())
+	}
+	sort.Strings(trend)
+	want := []string{
+		"arn:aws:::123456789012 snap_dev_1 ok",
+		"arn:aws:::123456789012 snap_dev_2 alarm",
+		"arn:aws:s3:::bucket-a snap_dev_1 alarm",
+		"arn:aws:s3:::bucket-a snap_dev_2 ok",
+		"arn:aws:s3:::bucket-b snap_dev_1 ok",
+		"arn:aws:s3:::bucket-b snap_dev_2 ok",
+	}
+	if !reflect.DeepEqual(trend, want) {
+		t.Errorf("results = %v, want %v", trend, want)
+	}
+
+	for _, row := range rows {
+		c := row.Columns
+		if c["resource"].GetStringValue() != "arn:aws:s3:::bucket-b" || c["snapshot_id"].GetStringValue() != "snap_dev_1" {
+			continue
+		}
+		if got := c["control_name"].GetStringValue() + "/" + c["reason"].GetStringValue() + "/" + c["workspace_handle"].GetStringValue(); got != "aws_compliance.control.cis_v300_1_4/bucket-b ok./dev" {
+			t.Errorf("control_name/reason/workspace_handle = %s", got)
+		}
+		// the columns Powerpipe adds for itself are not dimensions
+		var dimensions map[string]interface{}
+		if err := json.Unmarshal(c["dimensions"].GetJsonValue(), &dimensions); err != nil {
+			t.Fatal(err)
+		}
+		if want := map[string]interface{}{"account_id": "123456789012", "region": "us-west-2"}; !reflect.DeepEqual(dimensions, want) {
+			t.Errorf("dimensions = %v, want %v", dimensions, want)
+		}
+		if got := c["snapshot_created_at"].GetTimestampValue().AsTime().Format("2006-01-02"); got != "2024-02-01" {
+			t.Errorf("snapshot_created_at = %s, want 2024-02-01", got)
+		}
+	}
+}
+
+func TestWorkspaceSnapshotContentDownloadError(t *testing.T) {
+	api := newFakePipesAPI(t, "pipes_api.json")
+	p := newTestPlugin(t, api)
+
+	api.Fail("GET /download/user/alice/workspace/dev/snapshot/snap_dev_2.json", 403)
+	_, err := p.Query(testQuery{
+		Table:   "pipes_workspace_snapshot_control_result",
+		Columns: []string{"status"},
+		Quals: []*proto.Qual{
+			qual("identity_handle", "=", stringQualValue("alice")),
+			qual("workspace_handle", "=", stringQualValue("dev")),
+		},
+	})
+	if err == nil {
+		t.Error("err = nil, want download error")
+	}
+}
+
+func TestWorkspaceSnapshotDownload(t *testing.T) {
+	quals := func(format string) []*proto.Qual {
+		return []*proto.Qual{
+			qual("identity_handle", "=", stringQualValue("alice")),
+			qual("workspace_handle", "=", stringQualValue("dev")),
+			qual("format", "=", stringQualValue(format)),
+		}
+	}
+
+	t.Run("exported", func(t *testing.T) {
+		dir := t.TempDir()
+		api := newFakePipesAPI(t, "pipes_api.json")
+		p := newTestPluginWithConfig(t, api, fmt.Sprintf("snapshot_export_dir = %q\n", dir))
+
+		rows := p.MustQuery(testQuery{
+			Table:   "pipes_workspace_snapshot_download",
+			Columns: []string{"snapshot_id", "format", "content", "size", "export_path"},
+			Quals:   quals("html"),
+		})
+		if got := rowStrings(rows, "snapshot_id"); !reflect.DeepEqual(got, []string{"snap_dev_1", "snap_dev_2"}) {
+			t.Fatalf("snapshot_id = %v, want [snap_dev_1 snap_dev_2]", got)
+		}
+
+		for _, row := range rows {
+			c := row.Columns
+			id := c["snapshot_id"].GetStringValue()
+			want := filepath.Join(dir, "alice", "dev", id+".html")
+			if got := c["export_path"].GetStringValue(); got != want {
+				t.Errorf("%s export_path = %s, want %s", id, got, want)
+			}
+			data, err := os.ReadFile(want)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if content := c["content"].GetStringValue(); string(data) != content || !strings.HasPrefix(content, "<html>") {
+				t.Errorf("%s exported %q, content %q", id, data, content)
+			}
+			if got := c["size"].GetIntValue(); got != int64(len(data)) {
+				t.Errorf("%s size = %d, want %d", id, got, len(data))
+			}
+		}
+
+		// only the exported files are left in the directory
+		files, err := os.ReadDir(filepath.Join(dir, "alice", "dev"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(files) != 2 {
+			t.Errorf("files = %d, want 2", len(files))
+		}
+	})
+
+	t.Run("not exported", func(t *testing.T) {
+		api := newFakePipesAPI(t, "pipes_api.json")
+		p := newTestPlugin(t, api)
+
+		rows := p.MustQuery(testQuery{
+			Table:   "pipes_workspace_snapshot_download",
+			Columns: []string{"snapshot_id", "format", "content", "export_path"},
+			Quals:   quals("json"),
+		})
+		if len(rows) != 2 {
+			t.Fatalf("rows = %d, want 2", len(rows))
+		}
+		for _, row := range rows {
+			c := row.Columns
+			if c["export_path"].GetStringValue() != "" {
+				t.Errorf("export_path = %s, want null", c["export_path"].GetStringValue())
+			}
+			var data map[string]interface{}
+			if err := json.Unmarshal([]byte(c["content"].GetStringValue()), &data); err != nil || data["panels"] == nil {
+				t.Errorf("content is not a snapshot: %v", err)
+			}
+		}
+		// the workspace is fetched rather than listing every workspace
+		if got := len(api.Requests("GET /user/alice/workspace")); got != 0 {
+			t.Errorf("workspace list requests = %d, want 0", got)
+		}
+	})
+
+	t.Run("unsupported format", func(t *testing.T) {
+		api := newFakePipesAPI(t, "pipes_api.json")
+		p := newTestPlugin(t, api)
+
+		_, err := p.Query(testQuery{
+			Table:   "pipes_workspace_snapshot_download",
+			Columns: []string{"content"},
+			Quals:   quals("pdf"),
+		})
+		if err == nil || !strings.Contains(err.Error(), `unsupported snapshot format "pdf"`) {
+			t.Errorf("err = %v, want unsupported format error", err)
+		}
+		if got := len(api.Requests("GET /download/user/alice/workspace/dev/snapshot/snap_dev_1.pdf")); got != 0 {
+			t.Errorf("download requests = %d, want 0", got)
+		}
+	})
+}
+
+func TestWorkspaceSnapshotForWorkspace(t *testing.T) {
+	api := newFakePipesAPI(t, "pipes_api.json")
+	p := newTestPlugin(t, api)
+
+	rows := p.MustQuery(testQuery{
+		Table:   "pipes_workspace_snapshot",
+		Columns: []string{"id", "workspace_handle"},
+		Quals: []*proto.Qual{
+			qual("identity_handle", "=", stringQualValue("alice")),
+			qual("workspace_handle", "=", stringQualValue("dev")),
+		},
+	})
+
+	if got := rowStrings(rows, "id"); !reflect.DeepEqual(got, []string{"snap_dev_1", "snap_dev_2"}) {
+		t.Fatalf("ids = %v, want [snap_dev_1 snap_dev_2]", got)
+	}
+	// the workspace is fetched rather than listing every workspace
+	if got := len(api.Requests("GET /user/alice/workspace")); got != 0 {
+		t.Errorf("workspace list requests = %d, want 0", got)
+	}
+	// the workspace columns choose the workspace, they are not sent as a filter
+	for _, request := range api.Requests("GET /user/alice/workspace/dev/snapshot") {
+		if where := request.Query.Get("where"); strings.Contains(where, "workspace") || strings.Contains(where, "identity") {
+			t.Errorf("where = %q, want no identity or workspace filter", where)
+		}
+	}
+}
+
+func TestWorkspaceSnapshotDataError(t *testing.T) {
+	api := newFakePipesAPI(t, "pipes_api.json")
+	p := newTestPlugin(t, api)
+
+	// a failed download is an error, not a snapshot without data
+	api.Fail("GET /download/user/alice/workspace/w_dev/snapshot/snap_dev_1.json", 403)
+	_, err := p.Query(testQuery{
+		Table:   "pipes_workspace_snapshot",
+		Columns: []string{"id", "data"},
+	})
+	if err == nil || !strings.Contains(err.Error(), "403") {
+		t.Errorf("err = %v, want 403 error", err)
+	}
+}
+
+func TestWorkspaceSnapshotDiff(t *testing.T) {
+	api := newFakePipesAPI(t, "pipes_api.json", "snapshot_diff.json")
+	p := newTestPlugin(t, api)
+
+	rows := p.MustQuery(testQuery{
+		Table:   "pipes_workspace_snapshot_diff",
+		Columns: []string{"item_type", "change", "panel_name", "resource", "from_status", "to_status", "changed_properties", "from_snapshot_id"},
+		Quals: []*proto.Qual{
+			qual("identity_handle", "=", stringQualValue("alice")),
+			qual("workspace_handle", "=", stringQualValue("dev")),
+			qual("from_snapshot_id", "=", stringQualValue("snap_dev_1")),
+			qual("to_snapshot_id", "=", stringQualValue("snap_dev_2")),
+		},
+	})
+
+	var got []string
+	for _, row := range rows {
+		c := row.Columns
+		item := c["item_type"].GetStringValue() + " " + c["change"].GetStringValue() + " " + c["panel_name"].GetStringValue()
+		if resource := c["resource"].GetStringValue(); resource != "" {
+			item += " " + resource
+		}
+		got = append(got, item+" "+c["from_status"].GetStringValue()+"->"+c["to_status"].GetStringValue())
+	}
+	want := []string{
+		"panel changed aws_compliance.control.cis_v300_1_4 complete->complete",
+		"control_result changed aws_compliance.control.cis_v300_1_4 arn:aws:s3:::bucket-a alarm->ok",
+		"control_result removed aws_compliance.control.cis_v300_1_4 arn:aws:s3:::bucket-b ok->",
+		"control_result added aws_compliance.control.cis_v300_1_4 arn:aws:s3:::bucket-c ->alarm",
+		"panel removed aws_compliance.control.cis_v300_1_5 complete->",
+		"control_result removed aws_compliance.control.cis_v300_1_5 arn:aws:::123456789012 ok->",
+		"panel added aws_compliance.control.cis_v300_1_6 ->complete",
+		"control_result added aws_compliance.control.cis_v300_1_6 arn:aws:::123456789012 ->ok",
+	}
+	sort.Strings(got)
+	sort.Strings(want)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("diff =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	for _, row := range rows {
+		c := row.Columns
+		if c["item_type"].GetStringValue() != "panel" || c["change"].GetStringValue() != "changed" {
+			continue
+		}
+		var changed []string
+		if err := json.Unmarshal(c["changed_properties"].GetJsonValue(), &changed); err != nil {
+			t.Fatal(err)
+		}
+		if want := []string{"data"}; !reflect.DeepEqual(changed, want) {
+			t.Errorf("changed_properties = %v, want %v", changed, want)
+		}
+		if got := c["from_snapshot_id"].GetStringValue(); got != "snap_dev_1" {
+			t.Errorf("from_snapshot_id = %s, want snap_dev_1", got)
+		}
+	}
+
+	// a snapshot compared with itself has no differences
+	rows = p.MustQuery(testQuery{
+		Table:   "pipes_workspace_snapshot_diff",
+		Columns: []string{"change"},
+		Quals: []*proto.Qual{
+			qual("identity_handle", "=", stringQualValue("alice")),
+			qual("workspace_handle", "=", stringQualValue("dev")),
+			qual("from_snapshot_id", "=", stringQualValue("snap_dev_1")),
+			qual("to_snapshot_id", "=", stringQualValue("snap_dev_1")),
+		},
+	})
+	if len(rows) != 0 {
+		t.Errorf("rows = %d, want 0", len(rows))
+	}
+
+	// each query downloads the snapshots it compares once, and doesn't reuse
+	// the downloads of earlier queries
+	for id, want := range map[string]int{"snap_dev_1": 2, "snap_dev_2": 1} {
+		if got := len(api.Requests("GET /download/user/alice/workspace/dev/snapshot/" + id + ".json")); got != want {
+			t.Errorf("%s downloads = %d, want %d", id, got, want)
+		}
+	}
+}
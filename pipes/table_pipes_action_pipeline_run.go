@@ -0,0 +1,243 @@
+package pipes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	openapi "github.com/turbot/pipes-sdk-go"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+// defaultPipelineRunWaitTimeout is how long to wait for a pipeline run to
+// finish if wait is set without a wait_timeout.
+const defaultPipelineRunWaitTimeout = 300 * time.Second
+
+// pipelineRunPollInterval is the delay between checks of the state of a
+// pipeline run that is being waited for.
+var pipelineRunPollInterval = 5 * time.Second
+
+//// TABLE DEFINITION
+
+func tablePipesActionPipelineRun(_ context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "pipes_action_pipeline_run",
+		Description: "Runs a workspace pipeline and returns the process started for the run, optionally waiting for it to finish. Requires enable_write_actions = true in the connection config.",
+		Cache:       actionTableCache,
+		List: &plugin.ListConfig{
+			IgnoreConfig: actionIgnoreConfig,
+			Hydrate:      runWorkspacePipeline,
+			KeyColumns: []*plugin.KeyColumn{
+				{
+					Name:    "identity_handle",
+					Require: plugin.Required,
+				},
+				{
+					Name:    "workspace_handle",
+					Require: plugin.Required,
+				},
+				{
+					Name:    "pipeline_id",
+					Require: plugin.Required,
+				},
+				{
+					Name:    "args",
+					Require: plugin.Optional,
+				},
+				{
+					Name:    "wait",
+					Require: plugin.Optional,
+				},
+				{
+					Name:    "wait_timeout",
+					Require: plugin.Optional,
+				},
+			},
+		},
+		Columns: commonColumns([]*plugin.Column{
+			{
+				Name:        "pipeline_id",
+				Description: "The unique identifier of the pipeline to run.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromQual("pipeline_id"),
+			},
+			{
+				Name:        "identity_handle",
+				Description: "The handle of the user or organization which contains the workspace.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromQual("identity_handle"),
+			},
+			{
+				Name:        "workspace_handle",
+				Description: "The handle of the workspace which contains the pipeline.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromQual("workspace_handle"),
+			},
+			{
+				Name:        "args",
+				Description: "The arguments to run the pipeline with, overriding those the pipeline was created with.",
+				Type:        proto.ColumnType_JSON,
+			},
+			{
+				Name:        "wait",
+				Description: "If true, the query waits for the process to reach a terminal state, i.e. 'completed', 'failed', 'canceled' or 'terminated', before returning it.",
+				Type:        proto.ColumnType_BOOL,
+				Transform:   transform.FromQual("wait"),
+			},
+			{
+				Name:        "wait_timeout",
+				Description: "The maximum number of seconds to wait for the process to finish. If it has not finished by then, the process is returned in its current state. Defaults to 300.",
+				Type:        proto.ColumnType_INT,
+				Transform:   transform.FromQual("wait_timeout"),
+			},
+			{
+				Name:        "process_id",
+				Description: "The unique identifier of the process started for the run.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Id"),
+			},
+			{
+				Name:        "identity_id",
+				Description: "The unique identifier of the identity which contains the workspace.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromCamel(),
+			},
+			{
+				Name:        "workspace_id",
+				Description: "The unique identifier of the workspace which contains the pipeline.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromCamel(),
+			},
+			{
+				Name:        "type",
+				Description: "The type of action executed by the process.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "state",
+				Description: "The state of the process when it was returned.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "state_reason",
+				Description: "The reason the process is in its state, e.g. why it failed.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromCamel(),
+			},
+			{
+				Name:        "created_at",
+				Description: "The time when the process was created.",
+				Type:        proto.ColumnType_TIMESTAMP,
+			},
+			{
+				Name:        "created_by_id",
+				Description: "The unique identifier of the user who created the process.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromCamel(),
+			},
+			{
+				Name:        "updated_at",
+				Description: "The time when the process was last updated.",
+				Type:        proto.ColumnType_TIMESTAMP,
+			},
+		}),
+	}
+}
+
+// PipelineRun is the process started by running a pipeline, along with the
+// arguments the pipeline was run with.
+type PipelineRun struct {
+	openapi.SpProcess
+	Args map[string]interface{}
+}
+
+//// LIST FUNCTION
+
+func runWorkspacePipeline(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	if err := checkWriteActionsEnabled(d); err != nil {
+		return nil, err
+	}
+
+	identityHandle := d.EqualsQualString("identity_handle")
+	workspaceHandle := d.EqualsQualString("workspace_handle")
+	pipelineId := d.EqualsQualString("pipeline_id")
+
+	var args map[string]interface{}
+	if d.EqualsQuals["args"] != nil {
+		if err := json.Unmarshal([]byte(d.EqualsQuals["args"].GetJsonbValue()), &args); err != nil {
+			return nil, fmt.Errorf("args must be a JSON object: %v", err)
+		}
+	}
+
+	wait := d.EqualsQuals["wait"].GetBoolValue()
+	waitTimeout := defaultPipelineRunWaitTimeout
+	if d.EqualsQuals["wait_timeout"] != nil {
+		seconds := d.EqualsQuals["wait_timeout"].GetInt64Value()
+		if seconds < 0 {
+			return nil, fmt.Errorf("wait_timeout must not be negative")
+		}
+		waitTimeout = time.Duration(seconds) * time.Second
+	}
+
+	api, err := getIdentityService(ctx, d, h, identityHandle)
+	if err != nil {
+		plugin.Logger(ctx).Error("runWorkspacePipeline", "getIdentityService", err)
+		return nil, err
+	}
+
+	// Mutations are not retried, so that a pipeline is never run twice
+	resp, err := api.RunWorkspacePipeline(ctx, workspaceHandle, pipelineId, args)
+	if err != nil {
+		plugin.Logger(ctx).Error("runWorkspacePipeline", "run", err, "workspace", workspaceHandle, "pipeline", pipelineId)
+		return nil, err
+	}
+
+	var process openapi.SpProcess
+	if wait {
+		process, err = waitForWorkspaceProcess(ctx, api, workspaceHandle, resp.ProcessId, waitTimeout)
+	} else {
+		process, err = api.GetWorkspaceProcess(ctx, workspaceHandle, resp.ProcessId)
+	}
+	if err != nil {
+		plugin.Logger(ctx).Error("runWorkspacePipeline", "get_process", err, "workspace", workspaceHandle, "process", resp.ProcessId)
+		return nil, err
+	}
+
+	d.StreamListItem(ctx, PipelineRun{SpProcess: process, Args: args})
+	return nil, nil
+}
+
+// waitForWorkspaceProcess polls the process until it reaches a terminal state
+// or the timeout expires, and returns the process as last seen.
+func waitForWorkspaceProcess(ctx context.Context, api identityService, workspaceHandle, processId string, timeout time.Duration) (openapi.SpProcess, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		process, err := api.GetWorkspaceProcess(ctx, workspaceHandle, processId)
+		if err != nil || isTerminalProcessState(process.State) || time.Now().Add(pipelineRunPollInterval).After(deadline) {
+			return process, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return process, ctx.Err()
+		case <-time.After(pipelineRunPollInterval):
+		}
+	}
+}
+
+// isTerminalProcessState reports whether a process in the state has finished
+// and will not change state again.
+func isTerminalProcessState(state *openapi.ProcessState) bool {
+	if state == nil {
+		return false
+	}
+	switch *state {
+	case openapi.ProcessCompleted, openapi.ProcessFailed, openapi.ProcessCanceled, openapi.ProcessTerminated:
+		return true
+	}
+	return false
+}
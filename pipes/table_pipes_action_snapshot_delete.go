@@ -0,0 +1,131 @@
+package pipes
+
+import (
+	"context"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+//// TABLE DEFINITION
+
+func tablePipesActionSnapshotDelete(_ context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "pipes_action_snapshot_delete",
+		Description: "Deletes a workspace snapshot and returns the deleted snapshot. Requires enable_write_actions = true in the connection config.",
+		Cache:       actionTableCache,
+		List: &plugin.ListConfig{
+			IgnoreConfig: actionIgnoreConfig,
+			Hydrate:      deleteWorkspaceSnapshot,
+			KeyColumns: []*plugin.KeyColumn{
+				{
+					Name:    "identity_handle",
+					Require: plugin.Required,
+				},
+				{
+					Name:    "workspace_handle",
+					Require: plugin.Required,
+				},
+				{
+					Name:    "id",
+					Require: plugin.Required,
+				},
+			},
+		},
+		Columns: commonColumns([]*plugin.Column{
+			{
+				Name:        "id",
+				Description: "The unique identifier of the snapshot to delete.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromCamel(),
+			},
+			{
+				Name:        "identity_handle",
+				Description: "The handle of the user or organization which contains the workspace.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromQual("identity_handle"),
+			},
+			{
+				Name:        "workspace_handle",
+				Description: "The handle of the workspace which contains the snapshot.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromQual("workspace_handle"),
+			},
+			{
+				Name:        "identity_id",
+				Description: "The unique identifier for the identity which contains the workspace.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromCamel(),
+			},
+			{
+				Name:        "workspace_id",
+				Description: "The unique identifier for the workspace.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromCamel(),
+			},
+			{
+				Name:        "state",
+				Description: "The state of the snapshot.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "visibility",
+				Description: "The visibility of the snapshot.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "title",
+				Description: "The title of the snapshot.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "dashboard_name",
+				Description: "The mod-prefixed name of the dashboard the snapshot belongs to.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "dashboard_title",
+				Description: "The title of the dashboard the snapshot belongs to.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "created_at",
+				Description: "The time when the snapshot was created.",
+				Type:        proto.ColumnType_TIMESTAMP,
+			},
+			{
+				Name:        "updated_at",
+				Description: "The time when the snapshot was last updated.",
+				Type:        proto.ColumnType_TIMESTAMP,
+			},
+		}),
+	}
+}
+
+//// LIST FUNCTION
+
+func deleteWorkspaceSnapshot(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	if err := checkWriteActionsEnabled(d); err != nil {
+		return nil, err
+	}
+
+	identityHandle := d.EqualsQualString("identity_handle")
+	workspaceHandle := d.EqualsQualString("workspace_handle")
+	id := d.EqualsQualString("id")
+
+	api, err := getIdentityService(ctx, d, h, identityHandle)
+	if err != nil {
+		plugin.Logger(ctx).Error("deleteWorkspaceSnapshot", "getIdentityService", err)
+		return nil, err
+	}
+
+	snapshot, err := api.DeleteWorkspaceSnapshot(ctx, workspaceHandle, id)
+	if err != nil {
+		plugin.Logger(ctx).Error("deleteWorkspaceSnapshot", "delete", err, "workspace", workspaceHandle, "id", id)
+		return nil, err
+	}
+
+	d.StreamListItem(ctx, snapshot)
+	return nil, nil
+}
@@ -0,0 +1,141 @@
+package pipes
+
+import (
+	"context"
+
+	openapi "github.com/turbot/pipes-sdk-go"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+//// TABLE DEFINITION
+
+func tablePipesActionWorkspaceCreate(_ context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "pipes_action_workspace_create",
+		Description: "Creates a workspace for a user or organization and returns it. Requires enable_write_actions = true in the connection config.",
+		Cache:       actionTableCache,
+		List: &plugin.ListConfig{
+			IgnoreConfig: actionIgnoreConfig,
+			Hydrate:      createWorkspace,
+			KeyColumns: []*plugin.KeyColumn{
+				{
+					Name:    "identity_handle",
+					Require: plugin.Required,
+				},
+				{
+					Name:    "handle",
+					Require: plugin.Required,
+				},
+				{
+					Name:    "instance_type",
+					Require: plugin.Optional,
+				},
+			},
+		},
+		Columns: commonColumns([]*plugin.Column{
+			{
+				Name:        "handle",
+				Description: "The handle name for the workspace to create.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "identity_handle",
+				Description: "The handle of the user or organization to create the workspace in.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromQual("identity_handle"),
+			},
+			{
+				Name:        "instance_type",
+				Description: "The instance type of the workspace, e.g. 'db1.shared'. Defaults to the smallest instance type for the identity's plan.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromCamel(),
+			},
+			{
+				Name:        "id",
+				Description: "The unique identifier for the created workspace.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromCamel(),
+			},
+			{
+				Name:        "identity_id",
+				Description: "The unique identifier for the identity where the workspace has been created.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromCamel(),
+			},
+			{
+				Name:        "state",
+				Description: "The current workspace state.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "desired_state",
+				Description: "The state the workspace is transitioning to.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromCamel(),
+			},
+			{
+				Name:        "host",
+				Description: "The host for this workspace.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "database_name",
+				Description: "The database name for the workspace.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromCamel(),
+			},
+			{
+				Name:        "created_at",
+				Description: "The time when the workspace was created.",
+				Type:        proto.ColumnType_TIMESTAMP,
+			},
+			{
+				Name:        "created_by_id",
+				Description: "The unique identifier of the user who created the workspace.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromCamel(),
+			},
+			{
+				Name:        "version_id",
+				Description: "The current version ID of the workspace record.",
+				Type:        proto.ColumnType_INT,
+				Transform:   transform.FromCamel(),
+			},
+		}),
+	}
+}
+
+//// LIST FUNCTION
+
+func createWorkspace(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	if err := checkWriteActionsEnabled(d); err != nil {
+		return nil, err
+	}
+
+	identityHandle := d.EqualsQualString("identity_handle")
+	handle := d.EqualsQualString("handle")
+
+	api, err := getIdentityService(ctx, d, h, identityHandle)
+	if err != nil {
+		plugin.Logger(ctx).Error("createWorkspace", "getIdentityService", err)
+		return nil, err
+	}
+
+	request := openapi.CreateWorkspaceRequest{Handle: handle}
+	if instanceType := d.EqualsQualString("instance_type"); instanceType != "" {
+		request.InstanceType = openapi.WorkspaceInstanceType(instanceType).Ptr()
+	}
+
+	// Mutations are not retried, so that a workspace is never created twice
+	workspace, err := api.CreateWorkspace(ctx, request)
+	if err != nil {
+		plugin.Logger(ctx).Error("createWorkspace", "create", err, "identity", identityHandle, "handle", handle)
+		return nil, err
+	}
+
+	d.StreamListItem(ctx, workspace)
+	return nil, nil
+}
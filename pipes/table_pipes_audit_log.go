@@ -2,7 +2,8 @@ package pipes
 
 import (
 	"context"
-	"strings"
+	"net/http"
+	"sync"
 
 	openapi "github.com/turbot/pipes-sdk-go"
 
@@ -11,6 +12,11 @@ import (
 	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
 )
 
+// auditLogIdentityConcurrency is the number of identities whose audit logs are
+// listed at once when a query spans every identity the connection user can
+// access.
+const auditLogIdentityConcurrency = 5
+
 //// TABLE DEFINITION
 
 func tablePipesAuditLog(_ context.Context) *plugin.Table {
@@ -18,240 +24,234 @@ func tablePipesAuditLog(_ context.Context) *plugin.Table {
 		Name:        "pipes_audit_log",
 		Description: "Audit logs record a series of events performed on an identity.",
 		List: &plugin.ListConfig{
-			Hydrate:    listAuditLogs,
-			KeyColumns: plugin.AnyColumn([]string{"identity_handle", "identity_id"}),
+			Hydrate: listAuditLogs,
+			KeyColumns: append([]*plugin.KeyColumn{
+				{
+					Name:    "identity_handle",
+					Require: plugin.Optional,
+				},
+				{
+					Name:    "identity_id",
+					Require: plugin.Optional,
+				},
+			}, auditLogFilterKeyColumns()...),
 		},
-		Columns: commonColumns([]*plugin.Column{
-			{
-				Name:        "id",
-				Description: "The unique identifier for an audit log.",
-				Type:        proto.ColumnType_STRING,
-				Transform:   transform.FromCamel(),
-			},
-			{
-				Name:        "identity_id",
-				Description: "The unique identifier for an identity where the action has been performed.",
-				Type:        proto.ColumnType_STRING,
-				Transform:   transform.FromCamel(),
-			},
-			{
-				Name:        "identity_handle",
-				Description: "The handle name for an identity where the action has been performed.",
-				Type:        proto.ColumnType_STRING,
-			},
-			{
-				Name:        "action_type",
-				Description: "The action performed on the resource.",
-				Type:        proto.ColumnType_STRING,
-			},
-			{
-				Name:        "actor_avatar_url",
-				Description: "The avatar of an actor who has performed the action.",
-				Type:        proto.ColumnType_STRING,
-			},
-			{
-				Name:        "actor_display_name",
-				Description: "The display name of an actor.",
-				Type:        proto.ColumnType_STRING,
-			},
-			{
-				Name:        "actor_handle",
-				Description: "The handle name of an actor.",
-				Type:        proto.ColumnType_STRING,
-			},
-			{
-				Name:        "actor_id",
-				Description: "The unique identifier of an actor.",
-				Type:        proto.ColumnType_STRING,
-				Transform:   transform.FromCamel(),
-			},
-			{
-				Name:        "actor_ip",
-				Description: "The IP address of the actor.",
-				Type:        proto.ColumnType_STRING,
-			},
-			{
-				Name:        "created_at",
-				Description: "The time when the action was performed.",
-				Type:        proto.ColumnType_TIMESTAMP,
-			},
-			{
-				Name:        "target_handle",
-				Description: "The handle name of the entity where the action has been performed.",
-				Type:        proto.ColumnType_STRING,
-			},
-			{
-				Name:        "target_id",
-				Description: "The unique identifier of the entity where the action has been performed.",
-				Type:        proto.ColumnType_STRING,
-				Transform:   transform.FromCamel(),
-			},
-			{
-				Name:        "data",
-				Description: "The data which has been modified on the entity.",
-				Type:        proto.ColumnType_JSON,
-			},
-			{
-				Name:        "process_id",
-				Description: "The process id which this entry is a part of.",
-				Type:        proto.ColumnType_STRING,
-				Transform:   transform.FromCamel(),
-			},
-		}),
+		Columns: commonColumns(auditLogColumns()),
 	}
 }
 
-//// LIST FUNCTION
-
-func listAuditLogs(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
-	// Create Session
-	svc, err := connect(ctx, d)
-	if err != nil {
-		plugin.Logger(ctx).Error("listAuditLogs", "connection_error", err)
-		return nil, err
-	}
-
-	getUserIdentityCached := plugin.HydrateFunc(getUserIdentity).WithCache()
-	commonData, err := getUserIdentityCached(ctx, d, h)
-	if err != nil {
-		plugin.Logger(ctx).Error("listAuditLogs", "getUserIdentityCached", err)
-		return nil, err
+// auditLogColumns returns the columns of an audit log record, shared by the
+// identity and tenant audit log tables.
+func auditLogColumns() []*plugin.Column {
+	return []*plugin.Column{
+		{
+			Name:        "id",
+			Description: "The unique identifier for an audit log.",
+			Type:        proto.ColumnType_STRING,
+			Transform:   transform.FromCamel(),
+		},
+		{
+			Name:        "identity_id",
+			Description: "The unique identifier for an identity where the action has been performed.",
+			Type:        proto.ColumnType_STRING,
+			Transform:   transform.FromCamel(),
+		},
+		{
+			Name:        "identity_handle",
+			Description: "The handle name for an identity where the action has been performed.",
+			Type:        proto.ColumnType_STRING,
+		},
+		{
+			Name:        "action_type",
+			Description: "The action performed on the resource.",
+			Type:        proto.ColumnType_STRING,
+		},
+		{
+			Name:        "actor_avatar_url",
+			Description: "The avatar of an actor who has performed the action.",
+			Type:        proto.ColumnType_STRING,
+		},
+		{
+			Name:        "actor_display_name",
+			Description: "The display name of an actor.",
+			Type:        proto.ColumnType_STRING,
+		},
+		{
+			Name:        "actor_handle",
+			Description: "The handle name of an actor.",
+			Type:        proto.ColumnType_STRING,
+		},
+		{
+			Name:        "actor_id",
+			Description: "The unique identifier of an actor.",
+			Type:        proto.ColumnType_STRING,
+			Transform:   transform.FromCamel(),
+		},
+		{
+			Name:        "actor_ip",
+			Description: "The IP address of the actor.",
+			Type:        proto.ColumnType_STRING,
+		},
+		{
+			Name:        "created_at",
+			Description: "The time when the action was performed.",
+			Type:        proto.ColumnType_TIMESTAMP,
+		},
+		{
+			Name:        "target_handle",
+			Description: "The handle name of the entity where the action has been performed.",
+			Type:        proto.ColumnType_STRING,
+		},
+		{
+			Name:        "target_id",
+			Description: "The unique identifier of the entity where the action has been performed.",
+			Type:        proto.ColumnType_STRING,
+			Transform:   transform.FromCamel(),
+		},
+		{
+			Name:        "data",
+			Description: "The data which has been modified on the entity.",
+			Type:        proto.ColumnType_JSON,
+		},
+		{
+			Name:        "process_id",
+			Description: "The process id which this entry is a part of.",
+			Type:        proto.ColumnType_STRING,
+			Transform:   transform.FromCamel(),
+		},
 	}
+}
 
-	user := commonData.(openapi.User)
-
-	identityHandle := d.EqualsQuals["identity_handle"].GetStringValue()
-	identityId := d.EqualsQuals["identity_id"].GetStringValue()
-
-	// If the requested number of items is less than the paging max limit
-	// set the limit to that instead
-	maxResults := int32(100)
-	limit := d.QueryContext.Limit
-	if d.QueryContext.Limit != nil {
-		if *limit < int64(maxResults) {
-			if *limit < 1 {
-				maxResults = int32(1)
-			} else {
-				maxResults = int32(*limit)
-			}
-		}
+// auditLogFilterKeyColumns returns the optional key columns of the audit log
+// tables which are passed to the API as a `where` filter.
+func auditLogFilterKeyColumns() []*plugin.KeyColumn {
+	return []*plugin.KeyColumn{
+		{
+			Name:      "created_at",
+			Require:   plugin.Optional,
+			Operators: []string{"=", ">", ">=", "<", "<="},
+		},
+		{
+			Name:      "action_type",
+			Require:   plugin.Optional,
+			Operators: []string{"=", "<>", "~~", "!~~"},
+		},
+		{
+			Name:      "actor_handle",
+			Require:   plugin.Optional,
+			Operators: []string{"=", "<>"},
+		},
+		{
+			Name:      "actor_id",
+			Require:   plugin.Optional,
+			Operators: []string{"=", "<>"},
+		},
+		{
+			Name:      "target_id",
+			Require:   plugin.Optional,
+			Operators: []string{"=", "<>"},
+		},
 	}
+}
 
-	if identityHandle == "" && identityId == "" {
-		return nil, nil
-	} else if identityId != "" && strings.HasPrefix(identityId, "u_") {
-		err = listUserAuditLogs(ctx, d, h, identityId, svc, maxResults)
-	} else if identityId != "" && strings.HasPrefix(identityId, "o_") {
-		err = listOrgAuditLogs(ctx, d, h, identityId, svc, maxResults)
-	} else if identityHandle == user.Handle {
-		err = listUserAuditLogs(ctx, d, h, identityHandle, svc, maxResults)
-	} else {
-		err = listOrgAuditLogs(ctx, d, h, identityHandle, svc, maxResults)
-	}
+//// LIST FUNCTION
 
+func listAuditLogs(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	identities, err := identitiesFromQuals(ctx, d, h)
 	if err != nil {
-		plugin.Logger(ctx).Error("listAuditLogs", "list", err)
+		plugin.Logger(ctx).Error("listAuditLogs", "identitiesFromQuals", err)
 		return nil, err
 	}
-	return nil, nil
-}
 
-func listOrgAuditLogs(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData, handle string, svc *openapi.APIClient, maxResults int32) error {
-	var err error
+	// Build the where filter from the quals, with all values escaped
+	filter := buildListFilter(d)
 
-	// execute list call
-	pagesLeft := true
-	var resp openapi.ListAuditLogsResponse
-	var listDetails func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error)
+	// Only skip the orgs whose audit logs can't be read when listing every
+	// identity, an org asked for by the quals must be readable
+	spansIdentities := d.EqualsQualString("identity_handle") == "" && d.EqualsQualString("identity_id") == ""
 
-	for pagesLeft {
-		if resp.NextToken != nil {
-			listDetails = func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
-				resp, _, err = svc.Orgs.ListAuditLogs(ctx, handle).NextToken(*resp.NextToken).Limit(maxResults).Execute()
-				return resp, err
-			}
-		} else {
-			listDetails = func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
-				resp, _, err = svc.Orgs.ListAuditLogs(ctx, handle).Limit(maxResults).Execute()
-				return resp, err
-			}
-		}
-
-		response, err := plugin.RetryHydrate(ctx, d, h, listDetails, &plugin.RetryConfig{})
-
-		if err != nil {
-			plugin.Logger(ctx).Error("listOrgAuditLogs", "list", err)
-			return err
-		}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
 
-		result := response.(openapi.ListAuditLogsResponse)
-
-		if result.HasItems() {
-			for _, log := range *result.Items {
-				d.StreamListItem(ctx, log)
-
-				// Context can be cancelled due to manual cancellation or the limit has been hit
-				if d.RowsRemaining(ctx) == 0 {
-					return nil
-				}
-			}
-		}
-		if result.NextToken == nil {
-			pagesLeft = false
-		} else {
-			resp.NextToken = result.NextToken
+	// The identities are listed concurrently, but the SDK does not allow rows
+	// to be streamed from more than one goroutine at a time, so the records
+	// are sent to this goroutine to stream them
+	items := make(chan openapi.AuditRecord)
+	send := func(item openapi.AuditRecord) bool {
+		select {
+		case items <- item:
+			return true
+		case <-ctx.Done():
+			return false
 		}
 	}
 
-	return nil
-}
-
-func listUserAuditLogs(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData, handle string, svc *openapi.APIClient, maxResults int32) error {
-	var err error
+	var wg sync.WaitGroup
+	var once sync.Once
+	var listErr error
+	sem := make(chan struct{}, auditLogIdentityConcurrency)
+	for _, identity := range identities {
+		wg.Add(1)
+		go func(identity *pipesIdentity) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
 
-	// execute list call
-	pagesLeft := true
-	var resp openapi.ListAuditLogsResponse
-	var listDetails func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error)
+			// Context is cancelled due to manual cancellation, the limit being hit or an error
+			if ctx.Err() != nil {
+				return
+			}
 
-	for pagesLeft {
-		if resp.NextToken != nil {
-			listDetails = func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
-				resp, _, err = svc.Users.ListAuditLogs(ctx, handle).NextToken(*resp.NextToken).Limit(maxResults).Execute()
-				return resp, err
+			err := listIdentityAuditLogs(ctx, d, identity, filter, send)
+			if err == nil || ctx.Err() != nil {
+				return
 			}
-		} else {
-			listDetails = func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
-				resp, _, err = svc.Users.ListAuditLogs(ctx, handle).Limit(maxResults).Execute()
-				return resp, err
+			if spansIdentities && identity.Type == identityTypeOrg && isIdentityAccessError(err) {
+				plugin.Logger(ctx).Warn("listAuditLogs", "skipping org, audit log not accessible", err, "identity", identity.Handle)
+				return
 			}
-		}
+			plugin.Logger(ctx).Error("listAuditLogs", "list", err, "identity", identity.Handle)
+			once.Do(func() {
+				listErr = err
+				cancel()
+			})
+		}(identity)
+	}
+	go func() {
+		wg.Wait()
+		close(items)
+	}()
 
-		response, err := plugin.RetryHydrate(ctx, d, h, listDetails, &plugin.RetryConfig{})
+	for item := range items {
+		d.StreamListItem(ctx, item)
 
-		if err != nil {
-			plugin.Logger(ctx).Error("listUserAuditLogs", "list", err)
-			return err
+		// Context can be cancelled due to manual cancellation or the limit has been hit
+		if d.RowsRemaining(ctx) == 0 {
+			cancel()
 		}
+	}
 
-		result := response.(openapi.ListAuditLogsResponse)
-
-		if result.HasItems() {
-			for _, log := range *result.Items {
-				d.StreamListItem(ctx, log)
+	if listErr != nil {
+		return nil, listErr
+	}
+	return nil, nil
+}
 
-				// Context can be cancelled due to manual cancellation or the limit has been hit
-				if d.RowsRemaining(ctx) == 0 {
-					return nil
-				}
-			}
-		}
-		if resp.NextToken == nil {
-			pagesLeft = false
-		} else {
-			resp.NextToken = result.NextToken
-		}
+func listIdentityAuditLogs(ctx context.Context, d *plugin.QueryData, identity *pipesIdentity, filter string, send func(openapi.AuditRecord) bool) error {
+	api, err := newIdentityService(ctx, d, identity)
+	if err != nil {
+		return err
 	}
+	return paginateTo(ctx, d, api.ListAuditLogs(filter), send)
+}
 
-	return nil
+// isIdentityAccessError reports whether a list of an identity failed because
+// the connection user may not read it, e.g. they are a member of the org but
+// not an owner.
+func isIdentityAccessError(err error) bool {
+	switch errorStatusCode(err) {
+	case http.StatusForbidden, http.StatusNotFound:
+		return true
+	}
+	return false
 }
@@ -0,0 +1,171 @@
+package pipes
+
+import (
+	"context"
+
+	openapi "github.com/turbot/pipes-sdk-go"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+//// TABLE DEFINITION
+
+func tablePipesBillingSubscription(_ context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "pipes_billing_subscription",
+		Description: "Billing subscriptions are the paid plans of a user or organization.",
+		List: &plugin.ListConfig{
+			Hydrate: listBillingSubscriptions,
+			KeyColumns: []*plugin.KeyColumn{
+				{
+					Name:    "identity_handle",
+					Require: plugin.Optional,
+				},
+				{
+					Name:    "identity_id",
+					Require: plugin.Optional,
+				},
+			},
+		},
+		Columns: commonColumns([]*plugin.Column{
+			{
+				Name:        "id",
+				Description: "The unique identifier for the subscription.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromCamel(),
+			},
+			{
+				Name:        "status",
+				Description: "The status of the subscription, e.g. 'active', 'past_due' or 'canceled'.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "currency",
+				Description: "The currency the subscription is billed in.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "current_period_start",
+				Description: "The start of the current billing period.",
+				Type:        proto.ColumnType_TIMESTAMP,
+				Transform:   transform.FromCamel().Transform(transform.NullIfZeroValue),
+			},
+			{
+				Name:        "current_period_end",
+				Description: "The end of the current billing period.",
+				Type:        proto.ColumnType_TIMESTAMP,
+				Transform:   transform.FromCamel().Transform(transform.NullIfZeroValue),
+			},
+			{
+				Name:        "cancel_at",
+				Description: "The time when the subscription is scheduled to be canceled.",
+				Type:        proto.ColumnType_TIMESTAMP,
+				Transform:   transform.FromCamel().Transform(transform.NullIfZeroValue),
+			},
+			{
+				Name:        "cancel_at_period_end",
+				Description: "True if the subscription will be canceled at the end of the current billing period.",
+				Type:        proto.ColumnType_BOOL,
+				Transform:   transform.FromCamel().Transform(transform.NullIfZeroValue),
+			},
+			{
+				Name:        "canceled_at",
+				Description: "The time when the subscription was canceled.",
+				Type:        proto.ColumnType_TIMESTAMP,
+				Transform:   transform.FromCamel().Transform(transform.NullIfZeroValue),
+			},
+			{
+				Name:        "default_payment_method_id",
+				Description: "The unique identifier of the payment method used for the subscription.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromCamel(),
+			},
+			{
+				Name:        "items",
+				Description: "The items of the subscription, with their pricing and quantity.",
+				Type:        proto.ColumnType_JSON,
+			},
+			{
+				Name:        "identity_id",
+				Description: "The unique identifier for the identity the subscription belongs to.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromCamel(),
+			},
+			{
+				Name:        "identity_handle",
+				Description: "The handle of the identity the subscription belongs to.",
+				Type:        proto.ColumnType_STRING,
+				Hydrate:     getIdentityDetailsForBillingSubscription,
+			},
+			{
+				Name:        "identity_type",
+				Description: "The type of identity, which can be 'user' or 'org'.",
+				Type:        proto.ColumnType_STRING,
+				Hydrate:     getIdentityDetailsForBillingSubscription,
+			},
+			{
+				Name:        "created_at",
+				Description: "The time when the subscription was created.",
+				Type:        proto.ColumnType_TIMESTAMP,
+			},
+		}),
+	}
+}
+
+// BillingSubscription is a subscription of an identity. The API does not
+// return the identity the subscription belongs to.
+type BillingSubscription struct {
+	openapi.BillingSubscription
+	IdentityId string
+}
+
+//// LIST FUNCTION
+
+func listBillingSubscriptions(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	identities, err := identitiesFromQuals(ctx, d, h)
+	if err != nil {
+		plugin.Logger(ctx).Error("listBillingSubscriptions", "identitiesFromQuals", err)
+		return nil, err
+	}
+
+	for _, identity := range identities {
+		api, err := newIdentityService(ctx, d, identity)
+		if err != nil {
+			return nil, err
+		}
+
+		err = paginate(ctx, d, func(ctx context.Context, nextToken *string, limit int32) ([]BillingSubscription, *string, error) {
+			subscriptions, pageToken, err := api.ListBillingSubscriptions()(ctx, nextToken, limit)
+			items := make([]BillingSubscription, 0, len(subscriptions))
+			for _, subscription := range subscriptions {
+				items = append(items, BillingSubscription{BillingSubscription: subscription, IdentityId: identity.Id})
+			}
+			return items, pageToken, err
+		})
+		if err != nil {
+			plugin.Logger(ctx).Error("listBillingSubscriptions", "list", err, "identity", identity.Handle)
+			return nil, err
+		}
+
+		// Context can be cancelled due to manual cancellation or the limit has been hit
+		if d.RowsRemaining(ctx) == 0 {
+			return nil, nil
+		}
+	}
+	return nil, nil
+}
+
+//// HYDRATE FUNCTIONS
+
+func getIdentityDetailsForBillingSubscription(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	subscription := h.Item.(BillingSubscription)
+
+	details, err := getIdentityDetailsForId(ctx, d, h, subscription.IdentityId)
+	if err != nil {
+		plugin.Logger(ctx).Error("getIdentityDetailsForBillingSubscription", "error", err)
+		return nil, err
+	}
+	return details, nil
+}
@@ -2,7 +2,6 @@ package pipes
 
 import (
 	"context"
-	"strings"
 
 	openapi "github.com/turbot/pipes-sdk-go"
 
@@ -11,12 +10,6 @@ import (
 	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
 )
 
-var getIdentityDetailsForConnectionCached = plugin.HydrateFunc(getIdentityDetailsForConnection).WithCache()
-
-func getIdentityDetailsColumn(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
-	return getIdentityDetailsForConnectionCached(ctx, d, h)
-}
-
 //// TABLE DEFINITION
 
 func tablePipesConnection(_ context.Context) *plugin.Table {
@@ -62,13 +55,13 @@ func tablePipesConnection(_ context.Context) *plugin.Table {
 				Name:        "identity_handle",
 				Description: "The handle name for an identity where the connection has been created.",
 				Type:        proto.ColumnType_STRING,
-				Hydrate:     getIdentityDetailsColumn,
+				Hydrate:     getIdentityDetailsForConnection,
 			},
 			{
 				Name:        "identity_type",
 				Description: "The type of identity, which can be 'user' or 'org'.",
 				Type:        proto.ColumnType_STRING,
-				Hydrate:     getIdentityDetailsColumn,
+				Hydrate:     getIdentityDetailsForConnection,
 			},
 			{
 				Name:        "type",
@@ -85,6 +78,24 @@ func tablePipesConnection(_ context.Context) *plugin.Table {
 				Description: "The connection config details.",
 				Type:        proto.ColumnType_JSON,
 			},
+			{
+				Name:        "parent_id",
+				Description: "The unique identifier of the entity where the connection is stored, which is either its identity or a connection folder.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromCamel(),
+			},
+			{
+				Name:        "folder_id",
+				Description: "The unique identifier of the connection folder that contains the connection.",
+				Type:        proto.ColumnType_STRING,
+				Hydrate:     getConnectionFolderDetailsForConnection,
+			},
+			{
+				Name:        "folder_path",
+				Description: "The titles of the connection folder that contains the connection and each of its parent folders, separated by '/'.",
+				Type:        proto.ColumnType_STRING,
+				Hydrate:     getConnectionFolderDetailsForConnection,
+			},
 			{
 				Name:        "created_at",
 				Description: "The connection created time.",
@@ -130,56 +141,37 @@ func tablePipesConnection(_ context.Context) *plugin.Table {
 //// LIST FUNCTION
 
 func listConnections(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
-	// Create Session
-	svc, err := connect(ctx, d)
-	if err != nil {
-		plugin.Logger(ctx).Error("listConnections", "connection_error", err)
-		return nil, err
+	if d.EqualsQualString("identity_handle") == "" && d.EqualsQualString("identity_id") == "" {
+		// Create Session
+		svc, err := connect(ctx, d)
+		if err != nil {
+			plugin.Logger(ctx).Error("listConnections", "connection_error", err)
+			return nil, err
+		}
+
+		err = listActorConnections(ctx, d, h, svc)
+		if err != nil {
+			plugin.Logger(ctx).Error("listConnections", "list", err)
+			return nil, err
+		}
+		return nil, nil
 	}
 
-	getUserIdentityCached := plugin.HydrateFunc(getUserIdentity).WithCache()
-	commonData, err := getUserIdentityCached(ctx, d, h)
+	identity, err := identityFromQuals(ctx, d, h)
 	if err != nil {
-		plugin.Logger(ctx).Error("listConnections", "getUserIdentityCached", err)
+		plugin.Logger(ctx).Error("listConnections", "identityFromQuals", err)
 		return nil, err
 	}
-
-	user := commonData.(openapi.User)
-
-	var identityHandle, identityId string
-	if d.EqualsQuals["identity_handle"] != nil {
-		identityHandle = d.EqualsQuals["identity_handle"].GetStringValue()
-	}
-	if d.EqualsQuals["identity_id"] != nil {
-		identityId = d.EqualsQuals["identity_id"].GetStringValue()
-	}
-
-	// If the requested number of items is less than the paging max limit
-	// set the limit to that instead
-	maxResults := int32(100)
-	limit := d.QueryContext.Limit
-	if d.QueryContext.Limit != nil {
-		if *limit < int64(maxResults) {
-			if *limit < 1 {
-				maxResults = int32(1)
-			} else {
-				maxResults = int32(*limit)
-			}
-		}
+	if identity == nil {
+		return nil, nil
 	}
 
-	if identityHandle == "" && identityId == "" {
-		err = listActorConnections(ctx, d, h, svc, maxResults)
-	} else if identityId != "" && strings.HasPrefix(identityId, "u_") {
-		err = listUserConnections(ctx, d, h, identityId, svc, maxResults)
-	} else if identityId != "" && strings.HasPrefix(identityId, "o_") {
-		err = listOrgConnections(ctx, d, h, identityId, svc, maxResults)
-	} else if identityHandle == user.Handle {
-		err = listUserConnections(ctx, d, h, identityHandle, svc, maxResults)
-	} else {
-		err = listOrgConnections(ctx, d, h, identityHandle, svc, maxResults)
+	api, err := newIdentityService(ctx, d, identity)
+	if err != nil {
+		return nil, err
 	}
 
+	err = paginate(ctx, d, api.ListConnections())
 	if err != nil {
 		plugin.Logger(ctx).Error("listConnections", "list", err)
 		return nil, err
@@ -187,151 +179,15 @@ func listConnections(ctx context.Context, d *plugin.QueryData, h *plugin.Hydrate
 	return nil, nil
 }
 
-func listOrgConnections(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData, handle string, svc *openapi.APIClient, maxResults int32) error {
-	var err error
-
-	// execute list call
-	pagesLeft := true
-	var resp openapi.ListConnectionsResponse
-	var listDetails func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error)
-
-	for pagesLeft {
-		if resp.NextToken != nil {
-			listDetails = func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
-				resp, _, err = svc.OrgConnections.List(ctx, handle).NextToken(*resp.NextToken).Limit(maxResults).Execute()
-				return resp, err
-			}
-		} else {
-			listDetails = func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
-				resp, _, err = svc.OrgConnections.List(ctx, handle).Limit(maxResults).Execute()
-				return resp, err
-			}
+func listActorConnections(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData, svc *openapi.APIClient) error {
+	return paginate(ctx, d, func(ctx context.Context, nextToken *string, limit int32) ([]openapi.Connection, *string, error) {
+		req := svc.Actors.ListConnections(ctx).Limit(limit)
+		if nextToken != nil {
+			req = req.NextToken(*nextToken)
 		}
-
-		response, err := plugin.RetryHydrate(ctx, d, h, listDetails, &plugin.RetryConfig{})
-
-		if err != nil {
-			plugin.Logger(ctx).Error("listOrgConnections", "list", err)
-			return err
-		}
-
-		result := response.(openapi.ListConnectionsResponse)
-
-		for _, connection := range *result.Items {
-			d.StreamListItem(ctx, connection)
-
-			// Context can be cancelled due to manual cancellation or the limit has been hit
-			if d.RowsRemaining(ctx) == 0 {
-				return nil
-			}
-		}
-		if result.NextToken == nil {
-			pagesLeft = false
-		} else {
-			resp.NextToken = result.NextToken
-		}
-	}
-
-	return nil
-}
-
-func listUserConnections(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData, handle string, svc *openapi.APIClient, maxResults int32) error {
-	var err error
-
-	// execute list call
-	pagesLeft := true
-	var resp openapi.ListConnectionsResponse
-	var listDetails func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error)
-
-	for pagesLeft {
-		if resp.NextToken != nil {
-			listDetails = func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
-				resp, _, err = svc.UserConnections.List(ctx, handle).NextToken(*resp.NextToken).Limit(maxResults).Execute()
-				return resp, err
-			}
-		} else {
-			listDetails = func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
-				resp, _, err = svc.UserConnections.List(ctx, handle).Limit(maxResults).Execute()
-				return resp, err
-			}
-		}
-
-		response, err := plugin.RetryHydrate(ctx, d, h, listDetails, &plugin.RetryConfig{})
-
-		if err != nil {
-			plugin.Logger(ctx).Error("listUserConnections", "list", err)
-			return err
-		}
-
-		result := response.(openapi.ListConnectionsResponse)
-
-		for _, connection := range *result.Items {
-			d.StreamListItem(ctx, connection)
-
-			// Context can be cancelled due to manual cancellation or the limit has been hit
-			if d.RowsRemaining(ctx) == 0 {
-				return nil
-			}
-		}
-		if result.NextToken == nil {
-			pagesLeft = false
-		} else {
-			resp.NextToken = result.NextToken
-		}
-	}
-
-	return nil
-}
-
-func listActorConnections(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData, svc *openapi.APIClient, maxResults int32) error {
-	var err error
-
-	// execute list call
-	pagesLeft := true
-
-	var resp openapi.ListConnectionsResponse
-	var listDetails func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error)
-
-	for pagesLeft {
-		if resp.NextToken != nil {
-			listDetails = func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
-				resp, _, err = svc.Actors.ListConnections(ctx).NextToken(*resp.NextToken).Limit(maxResults).Execute()
-				return resp, err
-			}
-		} else {
-			listDetails = func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
-				resp, _, err = svc.Actors.ListConnections(ctx).Limit(maxResults).Execute()
-				return resp, err
-			}
-		}
-
-		response, err := plugin.RetryHydrate(ctx, d, h, listDetails, &plugin.RetryConfig{})
-
-		if err != nil {
-			plugin.Logger(ctx).Error("listActorConnections", "list", err)
-			return err
-		}
-
-		result := response.(openapi.ListConnectionsResponse)
-
-		if result.HasItems() {
-			for _, connection := range *result.Items {
-				d.StreamListItem(ctx, connection)
-
-				// Context can be cancelled due to manual cancellation or the limit has been hit
-				if d.RowsRemaining(ctx) == 0 {
-					return nil
-				}
-			}
-		}
-		if result.NextToken == nil {
-			pagesLeft = false
-		} else {
-			resp.NextToken = result.NextToken
-		}
-	}
-
-	return nil
+		resp, _, err := req.Execute()
+		return resp.GetItems(), resp.NextToken, err
+	})
 }
 
 //// HYDRATE FUNCTIONS
@@ -345,113 +201,57 @@ func getConnection(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateDa
 		return nil, nil
 	}
 
-	// Create Session
-	svc, err := connect(ctx, d)
-	if err != nil {
-		plugin.Logger(ctx).Error("getConnection", "connection_error", err)
-		return nil, err
-	}
-
-	getUserIdentityCached := plugin.HydrateFunc(getUserIdentity).WithCache()
-	commonData, err := getUserIdentityCached(ctx, d, h)
-	if err != nil {
-		plugin.Logger(ctx).Error("getConnection", "getUserIdentityCached", err)
-		return nil, err
-	}
-
-	user := commonData.(openapi.User)
-	var resp interface{}
-
-	if identityHandle == user.Handle {
-		resp, err = getUserConnection(ctx, d, h, identityHandle, handle, svc)
-	} else {
-		resp, err = getOrgConnection(ctx, d, h, identityHandle, handle, svc)
-	}
-
-	if err != nil {
-		plugin.Logger(ctx).Error("getConnection", "get", err)
-		return nil, err
-	}
-
-	if resp == nil {
-		return nil, nil
-	}
-
-	return resp.(openapi.Connection), nil
-}
-
-func getOrgConnection(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData, identityHandle string, handle string, svc *openapi.APIClient) (interface{}, error) {
-	var err error
-
-	// execute get call
-	var resp openapi.Connection
-
-	getDetails := func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
-		resp, _, err = svc.OrgConnections.Get(ctx, identityHandle, handle).Execute()
-		return resp, err
-	}
-
-	response, err := plugin.RetryHydrate(ctx, d, h, getDetails, &plugin.RetryConfig{})
-
-	connection := response.(openapi.Connection)
-
+	api, err := getIdentityService(ctx, d, h, identityHandle)
 	if err != nil {
-		plugin.Logger(ctx).Error("getOrgConnection", "get", err)
+		plugin.Logger(ctx).Error("getConnection", "getIdentityService", err)
 		return nil, err
 	}
 
-	return connection, nil
-}
-
-func getUserConnection(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData, identityHandle string, handle string, svc *openapi.APIClient) (interface{}, error) {
-	var err error
-
-	// execute get call
-	var resp openapi.Connection
-
 	getDetails := func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
-		resp, _, err = svc.UserConnections.Get(ctx, identityHandle, handle).Execute()
-		return resp, err
+		return api.GetConnection(ctx, handle)
 	}
 
 	response, err := plugin.RetryHydrate(ctx, d, h, getDetails, &plugin.RetryConfig{})
-
-	connection := response.(openapi.Connection)
-
 	if err != nil {
-		plugin.Logger(ctx).Error("getUserConnection", "get", err)
+		plugin.Logger(ctx).Error("getConnection", "get", err)
 		return nil, err
 	}
 
-	return connection, nil
+	return response.(openapi.Connection), nil
 }
 
 func getIdentityDetailsForConnection(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
-	// Create Session
-	svc, err := connect(ctx, d)
-	if err != nil {
-		plugin.Logger(ctx).Error("getIdentityDetailsForConnection", "connection_error", err)
-		return nil, err
-	}
-
 	// Get the identity id from the connection hydrate object
 	var identityId string
 	switch w := h.Item.(type) {
 	case openapi.Connection:
-		identityId = *h.Item.(openapi.Connection).IdentityId
+		identityId = w.GetIdentityId()
 	case *openapi.Connection:
-		identityId = *h.Item.(*openapi.Connection).IdentityId
+		identityId = w.GetIdentityId()
 	default:
 		plugin.Logger(ctx).Debug("getIdentityDetailsForConnection", "Unknown Type", w)
+		return nil, nil
 	}
 
-	getDetails := func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
-		resp, _, err := svc.Identities.Get(ctx, identityId).Execute()
-		return resp, err
-	}
+	return getIdentityDetailsForId(ctx, d, h, identityId)
+}
 
-	response, _ := plugin.RetryHydrate(ctx, d, h, getDetails, &plugin.RetryConfig{})
-	identity := response.(openapi.Identity)
+func getConnectionFolderDetailsForConnection(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	var connection *openapi.Connection
+	switch w := h.Item.(type) {
+	case openapi.Connection:
+		connection = &w
+	case *openapi.Connection:
+		connection = w
+	default:
+		plugin.Logger(ctx).Debug("getConnectionFolderDetailsForConnection", "Unknown Type", w)
+		return nil, nil
+	}
 
-	return &IdentityDetails{IdentityHandle: identity.Handle, IdentityType: identity.Type}, nil
+	details, err := getConnectionFolderDetails(ctx, d, h, connection)
+	if err != nil {
+		plugin.Logger(ctx).Error("getConnectionFolderDetailsForConnection", "error", err)
+		return nil, err
+	}
+	return details, nil
 }
@@ -0,0 +1,288 @@
+package pipes
+
+import (
+	"context"
+	"strings"
+
+	openapi "github.com/turbot/pipes-sdk-go"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+//// TABLE DEFINITION
+
+func tablePipesConnectionFolder(_ context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "pipes_connection_folder",
+		Description: "Connection folders organize the connections of an organization into a hierarchy.",
+		List: &plugin.ListConfig{
+			Hydrate: listConnectionFolders,
+			KeyColumns: []*plugin.KeyColumn{
+				{
+					Name:    "identity_handle",
+					Require: plugin.Optional,
+				},
+				{
+					Name:    "identity_id",
+					Require: plugin.Optional,
+				},
+			},
+		},
+		Get: &plugin.GetConfig{
+			KeyColumns: plugin.AllColumns([]string{"identity_handle", "id"}),
+			Hydrate:    getConnectionFolder,
+		},
+		Columns: commonColumns([]*plugin.Column{
+			{
+				Name:        "id",
+				Description: "The unique identifier for the folder.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromCamel(),
+			},
+			{
+				Name:        "title",
+				Description: "The title of the folder.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "parent_id",
+				Description: "The unique identifier of the folder's parent, which is either another folder or the organization.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromCamel(),
+			},
+			{
+				Name:        "path",
+				Description: "The titles of the folder and each of its parent folders, from the top of the hierarchy down, separated by '/'.",
+				Type:        proto.ColumnType_STRING,
+				Hydrate:     getConnectionFolderPath,
+				Transform:   transform.FromValue(),
+			},
+			{
+				Name:        "identity_id",
+				Description: "The unique identifier for the identity where the folder has been created.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromCamel(),
+			},
+			{
+				Name:        "identity_handle",
+				Description: "The handle of the identity where the folder has been created.",
+				Type:        proto.ColumnType_STRING,
+				Hydrate:     getIdentityDetailsForConnection,
+			},
+			{
+				Name:        "identity_type",
+				Description: "The type of identity, which can be 'user' or 'org'.",
+				Type:        proto.ColumnType_STRING,
+				Hydrate:     getIdentityDetailsForConnection,
+			},
+			{
+				Name:        "tenant_id",
+				Description: "The unique identifier for the tenant where the folder has been created.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromCamel(),
+			},
+			{
+				Name:        "created_at",
+				Description: "The time when the folder was created.",
+				Type:        proto.ColumnType_TIMESTAMP,
+			},
+			{
+				Name:        "created_by_id",
+				Description: "The unique identifier of the user who created the folder.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromCamel(),
+			},
+			{
+				Name:        "created_by",
+				Description: "Information about the user who created the folder.",
+				Type:        proto.ColumnType_JSON,
+			},
+			{
+				Name:        "updated_at",
+				Description: "The time when the folder was last updated.",
+				Type:        proto.ColumnType_TIMESTAMP,
+			},
+			{
+				Name:        "updated_by_id",
+				Description: "The unique identifier of the user who last updated the folder.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromCamel(),
+			},
+			{
+				Name:        "updated_by",
+				Description: "Information about the user who last updated the folder.",
+				Type:        proto.ColumnType_JSON,
+			},
+			{
+				Name:        "version_id",
+				Description: "The current version ID of the folder.",
+				Type:        proto.ColumnType_INT,
+				Transform:   transform.FromCamel(),
+			},
+		}),
+	}
+}
+
+// ConnectionFolderDetails holds the folder columns hydrated for connections.
+type ConnectionFolderDetails struct {
+	FolderID   *string `json:"folder_id"`
+	FolderPath *string `json:"folder_path"`
+}
+
+// connectionFolders indexes the connection folders of an identity, or of an
+// identity and one of its workspaces, by id.
+type connectionFolders map[string]openapi.Connection
+
+// path returns the titles of the folder and its parent folders, from the top
+// of the hierarchy down, separated by "/". It returns an empty string if the
+// folder is not in the index.
+func (f connectionFolders) path(folderId string) string {
+	var titles []string
+	// Stop after visiting every folder, in case the parents form a cycle
+	for i := 0; i < len(f); i++ {
+		folder, ok := f[folderId]
+		if !ok {
+			break
+		}
+		titles = append(titles, folder.GetTitle())
+		folderId = folder.ParentId
+	}
+	for i, j := 0, len(titles)-1; i < j; i, j = i+1, j-1 {
+		titles[i], titles[j] = titles[j], titles[i]
+	}
+	return strings.Join(titles, "/")
+}
+
+//// LIST FUNCTION
+
+func listConnectionFolders(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	identities, err := identitiesFromQuals(ctx, d, h)
+	if err != nil {
+		plugin.Logger(ctx).Error("listConnectionFolders", "identitiesFromQuals", err)
+		return nil, err
+	}
+
+	for _, identity := range identities {
+		// Users can only create folders in their workspaces
+		if identity.isUser() {
+			continue
+		}
+
+		folders, err := getConnectionFolders(ctx, d, h, identity.Id, "")
+		if err != nil {
+			plugin.Logger(ctx).Error("listConnectionFolders", "getConnectionFolders", err, "identity", identity.Handle)
+			return nil, err
+		}
+		for _, folder := range folders {
+			d.StreamListItem(ctx, folder)
+
+			// Context can be cancelled due to manual cancellation or the limit has been hit
+			if d.RowsRemaining(ctx) == 0 {
+				return nil, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+//// HYDRATE FUNCTIONS
+
+func getConnectionFolder(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	identityHandle := d.EqualsQuals["identity_handle"].GetStringValue()
+	id := d.EqualsQuals["id"].GetStringValue()
+
+	// check if id or identityHandle is empty
+	if identityHandle == "" || id == "" {
+		return nil, nil
+	}
+
+	identity, err := resolveIdentity(ctx, d, h, identityHandle)
+	if err != nil {
+		plugin.Logger(ctx).Error("getConnectionFolder", "resolveIdentity", err)
+		return nil, err
+	}
+
+	folders, err := getConnectionFolders(ctx, d, h, identity.Id, "")
+	if err != nil {
+		plugin.Logger(ctx).Error("getConnectionFolder", "getConnectionFolders", err)
+		return nil, err
+	}
+
+	folder, ok := folders[id]
+	if !ok {
+		return nil, nil
+	}
+	return folder, nil
+}
+
+func getConnectionFolderPath(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	folder := h.Item.(openapi.Connection)
+
+	folders, err := getConnectionFolders(ctx, d, h, folder.GetIdentityId(), "")
+	if err != nil {
+		plugin.Logger(ctx).Error("getConnectionFolderPath", "getConnectionFolders", err)
+		return nil, err
+	}
+	return folders.path(folder.Id), nil
+}
+
+// getConnectionFolderDetails returns the folder columns for a connection. A
+// connection is in a folder if its parent is one of the folders of its
+// identity, or of its workspace for connections created in a workspace.
+func getConnectionFolderDetails(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData, connection *openapi.Connection) (*ConnectionFolderDetails, error) {
+	if connection == nil || connection.ParentId == "" {
+		return &ConnectionFolderDetails{}, nil
+	}
+
+	folders, err := getConnectionFolders(ctx, d, h, connection.GetIdentityId(), connection.GetWorkspaceId())
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := folders[connection.ParentId]; !ok {
+		return &ConnectionFolderDetails{}, nil
+	}
+	path := folders.path(connection.ParentId)
+	return &ConnectionFolderDetails{FolderID: &connection.ParentId, FolderPath: &path}, nil
+}
+
+// getConnectionFolders returns the connection folders of the identity, plus
+// those of the workspace if workspaceId is set. The folders are listed once
+// per identity and workspace in each query, however many rows need them, so
+// a renamed or moved folder shows up in the next query.
+func getConnectionFolders(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData, identityId, workspaceId string) (connectionFolders, error) {
+	folders, err := getQueryCached(d, "ConnectionFolders-"+identityId+"-"+workspaceId, func() (interface{}, error) {
+		return listConnectionFoldersUncached(ctx, d, h, identityId, workspaceId)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return folders.(connectionFolders), nil
+}
+
+func listConnectionFoldersUncached(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData, identityId, workspaceId string) (connectionFolders, error) {
+	api, err := getIdentityService(ctx, d, h, identityId)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := listAll(ctx, d, api.ListConnectionFolders())
+	if err != nil {
+		return nil, err
+	}
+	if workspaceId != "" {
+		workspaceItems, err := listAll(ctx, d, api.ListWorkspaceConnectionFolders(workspaceId))
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, workspaceItems...)
+	}
+
+	folders := make(connectionFolders, len(items))
+	for _, item := range items {
+		folders[item.Id] = item
+	}
+	return folders, nil
+}
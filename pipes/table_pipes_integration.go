@@ -0,0 +1,220 @@
+package pipes
+
+import (
+	"context"
+
+	openapi "github.com/turbot/pipes-sdk-go"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+//// TABLE DEFINITION
+
+func tablePipesIntegration(_ context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "pipes_integration",
+		Description: "Integrations connect a user or organization to external services such as Slack, GitHub or email, for use by pipelines and notifiers.",
+		List: &plugin.ListConfig{
+			Hydrate: listIntegrations,
+			KeyColumns: []*plugin.KeyColumn{
+				{
+					Name:    "identity_handle",
+					Require: plugin.Optional,
+				},
+				{
+					Name:    "identity_id",
+					Require: plugin.Optional,
+				},
+			},
+		},
+		Get: &plugin.GetConfig{
+			KeyColumns: plugin.AllColumns([]string{"identity_handle", "handle"}),
+			Hydrate:    getIntegration,
+		},
+		Columns: commonColumns([]*plugin.Column{
+			{
+				Name:        "id",
+				Description: "The unique identifier for the integration.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromCamel(),
+			},
+			{
+				Name:        "handle",
+				Description: "The handle for the integration.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "type",
+				Description: "The type of the integration, e.g. 'slack', 'github' or 'email'.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "state",
+				Description: "The state of the integration. Can be one of 'pending', 'enabled', 'disabled' or 'error'.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "state_reason",
+				Description: "The reason for the state of the integration.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromCamel(),
+			},
+			{
+				Name:        "identity_id",
+				Description: "The unique identifier for the identity where the integration has been created.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromCamel(),
+			},
+			{
+				Name:        "identity_handle",
+				Description: "The handle of the identity where the integration has been created.",
+				Type:        proto.ColumnType_STRING,
+				Hydrate:     getIdentityDetailsForIntegration,
+			},
+			{
+				Name:        "identity_type",
+				Description: "The type of identity, which can be 'user' or 'org'.",
+				Type:        proto.ColumnType_STRING,
+				Hydrate:     getIdentityDetailsForIntegration,
+			},
+			{
+				Name:        "tenant_id",
+				Description: "The unique identifier for the tenant where the integration has been created.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromCamel(),
+			},
+			{
+				Name:        "pipeline_id",
+				Description: "The unique identifier of the pipeline the integration belongs to.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromCamel(),
+			},
+			{
+				Name:        "pipeline",
+				Description: "Information about the pipeline the integration belongs to.",
+				Type:        proto.ColumnType_JSON,
+			},
+			{
+				Name:        "github_installation_id",
+				Description: "The GitHub App installation ID, for GitHub integrations.",
+				Type:        proto.ColumnType_INT,
+				Transform:   transform.FromCamel(),
+			},
+			{
+				Name:        "config",
+				Description: "The configuration for the integration.",
+				Type:        proto.ColumnType_JSON,
+			},
+			{
+				Name:        "created_at",
+				Description: "The time when the integration was created.",
+				Type:        proto.ColumnType_TIMESTAMP,
+			},
+			{
+				Name:        "created_by_id",
+				Description: "The unique identifier of the user who created the integration.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromCamel(),
+			},
+			{
+				Name:        "created_by",
+				Description: "Information about the user who created the integration.",
+				Type:        proto.ColumnType_JSON,
+			},
+			{
+				Name:        "updated_at",
+				Description: "The time when the integration was last updated.",
+				Type:        proto.ColumnType_TIMESTAMP,
+			},
+			{
+				Name:        "updated_by_id",
+				Description: "The unique identifier of the user who last updated the integration.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromCamel(),
+			},
+			{
+				Name:        "updated_by",
+				Description: "Information about the user who last updated the integration.",
+				Type:        proto.ColumnType_JSON,
+			},
+			{
+				Name:        "version_id",
+				Description: "The current version ID of the integration.",
+				Type:        proto.ColumnType_INT,
+				Transform:   transform.FromCamel(),
+			},
+		}),
+	}
+}
+
+//// LIST FUNCTION
+
+func listIntegrations(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	identities, err := identitiesFromQuals(ctx, d, h)
+	if err != nil {
+		plugin.Logger(ctx).Error("listIntegrations", "identitiesFromQuals", err)
+		return nil, err
+	}
+
+	for _, identity := range identities {
+		api, err := newIdentityService(ctx, d, identity)
+		if err != nil {
+			return nil, err
+		}
+
+		err = paginate(ctx, d, api.ListIntegrations())
+		if err != nil {
+			plugin.Logger(ctx).Error("listIntegrations", "list", err, "identity", identity.Handle)
+			return nil, err
+		}
+
+		// Context can be cancelled due to manual cancellation or the limit has been hit
+		if d.RowsRemaining(ctx) == 0 {
+			return nil, nil
+		}
+	}
+	return nil, nil
+}
+
+//// HYDRATE FUNCTIONS
+
+func getIntegration(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	identityHandle := d.EqualsQuals["identity_handle"].GetStringValue()
+	handle := d.EqualsQuals["handle"].GetStringValue()
+
+	// check if handle or identityHandle is empty
+	if identityHandle == "" || handle == "" {
+		return nil, nil
+	}
+
+	api, err := getIdentityService(ctx, d, h, identityHandle)
+	if err != nil {
+		plugin.Logger(ctx).Error("getIntegration", "getIdentityService", err)
+		return nil, err
+	}
+
+	getDetails := func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+		return api.GetIntegration(ctx, handle)
+	}
+
+	response, err := plugin.RetryHydrate(ctx, d, h, getDetails, &plugin.RetryConfig{})
+	if err != nil {
+		plugin.Logger(ctx).Error("getIntegration", "get", err)
+		return nil, err
+	}
+
+	return response.(openapi.Integration), nil
+}
+
+func getIdentityDetailsForIntegration(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	integration := h.Item.(openapi.Integration)
+
+	details, err := getIdentityDetailsForId(ctx, d, h, integration.GetIdentityId())
+	if err != nil {
+		plugin.Logger(ctx).Error("getIdentityDetailsForIntegration", "error", err)
+		return nil, err
+	}
+	return details, nil
+}
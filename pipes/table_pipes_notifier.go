@@ -0,0 +1,203 @@
+package pipes
+
+import (
+	"context"
+
+	openapi "github.com/turbot/pipes-sdk-go"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+//// TABLE DEFINITION
+
+func tablePipesNotifier(_ context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "pipes_notifier",
+		Description: "Notifiers define where notifications from pipelines are sent, as a list of integrations and the channels or addresses to notify.",
+		List: &plugin.ListConfig{
+			Hydrate: listNotifiers,
+			KeyColumns: []*plugin.KeyColumn{
+				{
+					Name:    "identity_handle",
+					Require: plugin.Optional,
+				},
+				{
+					Name:    "identity_id",
+					Require: plugin.Optional,
+				},
+			},
+		},
+		Get: &plugin.GetConfig{
+			KeyColumns: plugin.AllColumns([]string{"identity_handle", "name"}),
+			Hydrate:    getNotifier,
+		},
+		Columns: commonColumns([]*plugin.Column{
+			{
+				Name:        "id",
+				Description: "The unique identifier for the notifier.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromCamel(),
+			},
+			{
+				Name:        "name",
+				Description: "The name of the notifier, unique up and down the resource hierarchy.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "state",
+				Description: "The state of the notifier. Can be one of 'enabled' or 'disabled'.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "state_reason",
+				Description: "The reason for the state of the notifier.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromCamel(),
+			},
+			{
+				Name:        "precedence",
+				Description: "The precedence of the notifier, e.g. 'default' for the notifier used when a pipeline does not name one.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "notifies",
+				Description: "The integrations and channels or addresses that the notifier sends notifications to.",
+				Type:        proto.ColumnType_JSON,
+			},
+			{
+				Name:        "identity_id",
+				Description: "The unique identifier for the identity where the notifier has been created.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromCamel(),
+			},
+			{
+				Name:        "identity_handle",
+				Description: "The handle of the identity where the notifier has been created.",
+				Type:        proto.ColumnType_STRING,
+				Hydrate:     getIdentityDetailsForNotifier,
+			},
+			{
+				Name:        "identity_type",
+				Description: "The type of identity, which can be 'user' or 'org'.",
+				Type:        proto.ColumnType_STRING,
+				Hydrate:     getIdentityDetailsForNotifier,
+			},
+			{
+				Name:        "tenant_id",
+				Description: "The unique identifier for the tenant where the notifier has been created.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromCamel(),
+			},
+			{
+				Name:        "created_at",
+				Description: "The time when the notifier was created.",
+				Type:        proto.ColumnType_TIMESTAMP,
+			},
+			{
+				Name:        "created_by_id",
+				Description: "The unique identifier of the user who created the notifier.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromCamel(),
+			},
+			{
+				Name:        "created_by",
+				Description: "Information about the user who created the notifier.",
+				Type:        proto.ColumnType_JSON,
+			},
+			{
+				Name:        "updated_at",
+				Description: "The time when the notifier was last updated.",
+				Type:        proto.ColumnType_TIMESTAMP,
+			},
+			{
+				Name:        "updated_by_id",
+				Description: "The unique identifier of the user who last updated the notifier.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromCamel(),
+			},
+			{
+				Name:        "updated_by",
+				Description: "Information about the user who last updated the notifier.",
+				Type:        proto.ColumnType_JSON,
+			},
+			{
+				Name:        "version_id",
+				Description: "The current version ID of the notifier.",
+				Type:        proto.ColumnType_INT,
+				Transform:   transform.FromCamel(),
+			},
+		}),
+	}
+}
+
+//// LIST FUNCTION
+
+func listNotifiers(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	identities, err := identitiesFromQuals(ctx, d, h)
+	if err != nil {
+		plugin.Logger(ctx).Error("listNotifiers", "identitiesFromQuals", err)
+		return nil, err
+	}
+
+	for _, identity := range identities {
+		api, err := newIdentityService(ctx, d, identity)
+		if err != nil {
+			return nil, err
+		}
+
+		err = paginate(ctx, d, api.ListNotifiers())
+		if err != nil {
+			plugin.Logger(ctx).Error("listNotifiers", "list", err, "identity", identity.Handle)
+			return nil, err
+		}
+
+		// Context can be cancelled due to manual cancellation or the limit has been hit
+		if d.RowsRemaining(ctx) == 0 {
+			return nil, nil
+		}
+	}
+	return nil, nil
+}
+
+//// HYDRATE FUNCTIONS
+
+func getNotifier(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	identityHandle := d.EqualsQuals["identity_handle"].GetStringValue()
+	name := d.EqualsQuals["name"].GetStringValue()
+
+	// check if name or identityHandle is empty
+	if identityHandle == "" || name == "" {
+		return nil, nil
+	}
+
+	api, err := getIdentityService(ctx, d, h, identityHandle)
+	if err != nil {
+		plugin.Logger(ctx).Error("getNotifier", "getIdentityService", err)
+		return nil, err
+	}
+
+	getDetails := func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+		return api.GetNotifier(ctx, name)
+	}
+
+	response, err := plugin.RetryHydrate(ctx, d, h, getDetails, &plugin.RetryConfig{})
+	if err != nil {
+		plugin.Logger(ctx).Error("getNotifier", "get", err)
+		return nil, err
+	}
+
+	return response.(openapi.Notifier), nil
+}
+
+func getIdentityDetailsForNotifier(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	notifier := h.Item.(openapi.Notifier)
+
+	details, err := getIdentityDetailsForId(ctx, d, h, notifier.GetIdentityId())
+	if err != nil {
+		plugin.Logger(ctx).Error("getIdentityDetailsForNotifier", "error", err)
+		return nil, err
+	}
+	return details, nil
+}
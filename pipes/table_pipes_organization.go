@@ -103,64 +103,22 @@ func listOrganizations(ctx context.Context, d *plugin.QueryData, h *plugin.Hydra
 		return nil, err
 	}
 
-	// If the requested number of items is less than the paging max limit
-	// set the limit to that instead
-	maxResults := int32(100)
-	limit := d.QueryContext.Limit
-	if d.QueryContext.Limit != nil {
-		if *limit < int64(maxResults) {
-			if *limit < 1 {
-				maxResults = int32(1)
-			} else {
-				maxResults = int32(*limit)
-			}
-		}
-	}
-
-	// execute list call
-	pagesLeft := true
-
-	var resp openapi.ListUserOrgsResponse
-	var listDetails func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error)
-
-	for pagesLeft {
-		if resp.NextToken != nil {
-			listDetails = func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
-				resp, _, err = svc.Actors.ListOrgs(ctx).NextToken(*resp.NextToken).Limit(maxResults).Execute()
-				return resp, err
-			}
-		} else {
-			listDetails = func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
-				resp, _, err = svc.Actors.ListOrgs(ctx).Limit(maxResults).Execute()
-				return resp, err
-			}
-		}
-
-		response, err := plugin.RetryHydrate(ctx, d, h, listDetails, &plugin.RetryConfig{})
-
-		if err != nil {
-			plugin.Logger(ctx).Error("listOrganizations", "list", err)
-			return nil, err
+	err = paginate(ctx, d, func(ctx context.Context, nextToken *string, limit int32) ([]*openapi.Org, *string, error) {
+		req := svc.Actors.ListOrgs(ctx).Limit(limit)
+		if nextToken != nil {
+			req = req.NextToken(*nextToken)
 		}
+		resp, _, err := req.Execute()
 
-		result := response.(openapi.ListUserOrgsResponse)
-
-		if result.HasItems() {
-			for _, org := range *result.Items {
-				d.StreamListItem(ctx, org.Org)
-
-				// Context can be cancelled due to manual cancellation or the limit has been hit
-				if d.RowsRemaining(ctx) == 0 {
-					return nil, nil
-				}
-			}
-		}
-
-		if result.NextToken == nil {
-			pagesLeft = false
-		} else {
-			resp.NextToken = result.NextToken
+		var orgs []*openapi.Org
+		for _, userOrg := range resp.GetItems() {
+			orgs = append(orgs, userOrg.Org)
 		}
+		return orgs, resp.NextToken, err
+	})
+	if err != nil {
+		plugin.Logger(ctx).Error("listOrganizations", "list", err)
+		return nil, err
 	}
 
 	return nil, nil
@@ -185,7 +143,7 @@ func getOrganization(ctx context.Context, d *plugin.QueryData, h *plugin.Hydrate
 
 	getDetails := func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
 		resp, _, err = svc.Orgs.Get(ctx, handle).Execute()
-		return resp, err
+		return resp, classifyError(err)
 	}
 
 	response, err := plugin.RetryHydrate(ctx, d, h, getDetails, &plugin.RetryConfig{})
@@ -0,0 +1,183 @@
+package pipes
+
+import (
+	"context"
+
+	openapi "github.com/turbot/pipes-sdk-go"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+// memberStatusAccepted is the status of an organization or workspace member
+// who has accepted their invitation.
+const memberStatusAccepted = "accepted"
+
+//// TABLE DEFINITION
+
+func tablePipesOrganizationInvitation(_ context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "pipes_organization_invitation",
+		Description: "Organization invitations are the outstanding invitations for users to join an organization.",
+		List: &plugin.ListConfig{
+			ParentHydrate: listOrganizations,
+			Hydrate:       listOrganizationInvitations,
+		},
+		Get: &plugin.GetConfig{
+			KeyColumns: plugin.AllColumns([]string{"org_handle", "user_handle"}),
+			Hydrate:    getOrganizationInvitation,
+		},
+		Columns: connectionColumns([]*plugin.Column{
+			{
+				Name:        "id",
+				Description: "The unique identifier for the invitation.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromCamel(),
+			},
+			{
+				Name:        "org_id",
+				Description: "The unique identifier for the organization.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromCamel(),
+			},
+			{
+				Name:        "org_handle",
+				Description: "The handle of the organization.",
+				Type:        proto.ColumnType_STRING,
+				Hydrate:     getIdentityDetailsForOrgInvitation,
+				Transform:   transform.FromField("IdentityHandle"),
+			},
+			{
+				Name:        "status",
+				Description: "The invitation status, e.g. 'invited'.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "user_id",
+				Description: "The unique identifier for the invited user.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromCamel(),
+			},
+			{
+				Name:        "user_handle",
+				Description: "The handle of the invited user.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "user",
+				Description: "Information about the invited user.",
+				Type:        proto.ColumnType_JSON,
+			},
+			{
+				Name:        "role",
+				Description: "The role the user has been invited to.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "scope",
+				Description: "The scope of the role. Will always be 'org'.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "created_at",
+				Description: "The time when the user was invited.",
+				Type:        proto.ColumnType_TIMESTAMP,
+			},
+			{
+				Name:        "created_by_id",
+				Description: "The unique identifier of the user who sent the invitation.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromCamel(),
+			},
+			{
+				Name:        "created_by",
+				Description: "Information about the user who sent the invitation.",
+				Type:        proto.ColumnType_JSON,
+			},
+			{
+				Name:        "updated_at",
+				Description: "The time when the invitation was last updated.",
+				Type:        proto.ColumnType_TIMESTAMP,
+			},
+			{
+				Name:        "updated_by_id",
+				Description: "The unique identifier of the user who last updated the invitation.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromCamel(),
+			},
+			{
+				Name:        "updated_by",
+				Description: "Information about the user who last updated the invitation.",
+				Type:        proto.ColumnType_JSON,
+			},
+			{
+				Name:        "version_id",
+				Description: "The current version ID for the invitation.",
+				Type:        proto.ColumnType_INT,
+				Transform:   transform.FromCamel(),
+			},
+		}),
+	}
+}
+
+//// LIST FUNCTION
+
+func listOrganizationInvitations(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	org := h.Item.(*openapi.Org)
+
+	// Create Session
+	svc, err := connect(ctx, d)
+	if err != nil {
+		plugin.Logger(ctx).Error("listOrganizationInvitations", "connection_error", err)
+		return nil, err
+	}
+
+	// Invitations are the members who have not accepted yet
+	err = paginate(ctx, d, func(ctx context.Context, nextToken *string, limit int32) ([]openapi.OrgUser, *string, error) {
+		req := svc.OrgMembers.List(ctx, org.Handle).Limit(limit)
+		if nextToken != nil {
+			req = req.NextToken(*nextToken)
+		}
+		resp, _, err := req.Execute()
+
+		var invitations []openapi.OrgUser
+		for _, member := range resp.GetItems() {
+			if member.Status != memberStatusAccepted {
+				invitations = append(invitations, member)
+			}
+		}
+		return invitations, resp.NextToken, err
+	})
+	if err != nil {
+		plugin.Logger(ctx).Error("listOrganizationInvitations", "list", err)
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+//// HYDRATE FUNCTIONS
+
+func getOrganizationInvitation(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	member, err := getOrganizationMember(ctx, d, h)
+	if err != nil {
+		plugin.Logger(ctx).Error("getOrganizationInvitation", "getOrganizationMember", err)
+		return nil, err
+	}
+	if member == nil || member.(openapi.OrgUser).Status == memberStatusAccepted {
+		return nil, nil
+	}
+	return member, nil
+}
+
+func getIdentityDetailsForOrgInvitation(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	invitation := h.Item.(openapi.OrgUser)
+
+	details, err := getIdentityDetailsForId(ctx, d, h, invitation.OrgId)
+	if err != nil {
+		plugin.Logger(ctx).Error("getIdentityDetailsForOrgInvitation", "error", err)
+		return nil, err
+	}
+	return details, nil
+}
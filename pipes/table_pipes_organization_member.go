@@ -28,7 +28,7 @@ func tablePipesOrganizationMember(_ context.Context) *plugin.Table {
 			KeyColumns: plugin.AllColumns([]string{"org_handle", "user_handle"}),
 			Hydrate:    getOrganizationMember,
 		},
-		Columns: []*plugin.Column{
+		Columns: connectionColumns([]*plugin.Column{
 			{
 				Name:        "id",
 				Description: "The unique identifier for the member.",
@@ -121,7 +121,7 @@ func tablePipesOrganizationMember(_ context.Context) *plugin.Table {
 				Type:        proto.ColumnType_INT,
 				Transform:   transform.FromCamel(),
 			},
-		},
+		}),
 	}
 }
 
@@ -130,21 +130,7 @@ func tablePipesOrganizationMember(_ context.Context) *plugin.Table {
 func listOrganizationMembers(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
 	org := h.Item.(*openapi.Org)
 
-	// If the requested number of items is less than the paging max limit
-	// set the limit to that instead
-	maxResults := int32(100)
-	limit := d.QueryContext.Limit
-	if d.QueryContext.Limit != nil {
-		if *limit < int64(maxResults) {
-			if *limit < 1 {
-				maxResults = int32(1)
-			} else {
-				maxResults = int32(*limit)
-			}
-		}
-	}
-
-	err := listOrgMembers(ctx, d, h, org.Handle, maxResults)
+	err := listOrgMembers(ctx, d, h, org.Handle)
 	if err != nil {
 		plugin.Logger(ctx).Error("listOrganizationMembers", "error", err)
 		return nil, err
@@ -153,7 +139,7 @@ func listOrganizationMembers(ctx context.Context, d *plugin.QueryData, h *plugin
 	return nil, nil
 }
 
-func listOrgMembers(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData, handle string, maxResults int32) error {
+func listOrgMembers(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData, handle string) error {
 	// Create Session
 	svc, err := connect(ctx, d)
 	if err != nil {
@@ -161,50 +147,14 @@ func listOrgMembers(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateD
 		return err
 	}
 
-	pagesLeft := true
-	var resp openapi.ListOrgUsersResponse
-	var listDetails func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error)
-
-	for pagesLeft {
-		if resp.NextToken != nil {
-			listDetails = func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
-				resp, _, err = svc.OrgMembers.List(ctx, handle).NextToken(*resp.NextToken).Limit(maxResults).Execute()
-				return resp, err
-			}
-		} else {
-			listDetails = func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
-				resp, _, err = svc.OrgMembers.List(ctx, handle).Limit(maxResults).Execute()
-				return resp, err
-			}
+	return paginate(ctx, d, func(ctx context.Context, nextToken *string, limit int32) ([]openapi.OrgUser, *string, error) {
+		req := svc.OrgMembers.List(ctx, handle).Limit(limit)
+		if nextToken != nil {
+			req = req.NextToken(*nextToken)
 		}
-
-		response, err := plugin.RetryHydrate(ctx, d, h, listDetails, &plugin.RetryConfig{})
-
-		if err != nil {
-			plugin.Logger(ctx).Error("listOrgMembers", "list", err)
-			return err
-		}
-
-		result := response.(openapi.ListOrgUsersResponse)
-
-		if result.HasItems() {
-			for _, member := range *result.Items {
-				d.StreamListItem(ctx, member)
-
-				// Context can be cancelled due to manual cancellation or the limit has been hit
-				if d.RowsRemaining(ctx) == 0 {
-					return nil
-				}
-			}
-		}
-		if result.NextToken == nil {
-			pagesLeft = false
-		} else {
-			resp.NextToken = result.NextToken
-		}
-	}
-
-	return nil
+		resp, _, err := req.Execute()
+		return resp.GetItems(), resp.NextToken, err
+	})
 }
 
 func getOrganizationMember(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
@@ -227,19 +177,16 @@ func getOrganizationMember(ctx context.Context, d *plugin.QueryData, h *plugin.H
 
 	getDetails := func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
 		orgUser, _, err = svc.OrgMembers.Get(ctx, orgHandle, userhandle).Execute()
-		return orgUser, err
+		return orgUser, classifyError(err)
 	}
 
 	response, err := plugin.RetryHydrate(ctx, d, h, getDetails, &plugin.RetryConfig{})
-
-	orgUser = response.(openapi.OrgUser)
-
 	if err != nil {
 		plugin.Logger(ctx).Error("getOrganizationMember", "get", err)
 		return nil, err
 	}
 
-	return orgUser, nil
+	return response.(openapi.OrgUser), nil
 }
 
 func getOrgDetails(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
@@ -262,7 +209,7 @@ func getOrgDetails(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateDa
 
 	getDetails := func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
 		resp, _, err := svc.Orgs.Get(ctx, h.Item.(openapi.OrgUser).OrgId).Execute()
-		return resp, err
+		return resp, classifyError(err)
 	}
 
 	response, _ := plugin.RetryHydrate(ctx, d, h, getDetails, &plugin.RetryConfig{})
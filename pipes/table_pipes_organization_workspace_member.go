@@ -2,7 +2,6 @@ package pipes
 
 import (
 	"context"
-	"strings"
 
 	openapi "github.com/turbot/pipes-sdk-go"
 
@@ -30,7 +29,7 @@ func tablePipesOrganizationWorkspaceMember(_ context.Context) *plugin.Table {
 			KeyColumns: plugin.AllColumns([]string{"org_handle", "workspace_handle", "user_handle"}),
 			Hydrate:    getOrganizationWorkspaceMember,
 		},
-		Columns: []*plugin.Column{
+		Columns: connectionColumns([]*plugin.Column{
 			{
 				Name:        "id",
 				Description: "The unique identifier for the member.",
@@ -135,47 +134,39 @@ func tablePipesOrganizationWorkspaceMember(_ context.Context) *plugin.Table {
 				Type:        proto.ColumnType_INT,
 				Transform:   transform.FromCamel(),
 			},
-		},
+		}),
 	}
 }
 
 //// LIST FUNCTION
 
 func listOrganizationWorkspaceMembers(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
-	workspace := h.Item.(*openapi.Workspace)
-
-	if strings.HasPrefix(workspace.IdentityId, "u_") {
+	workspace := parentWorkspace(h.Item)
+	if workspace == nil {
+		plugin.Logger(ctx).Error("listOrganizationWorkspaceMembers", "unknown response type for workspace list parent hydrate call", h.Item)
 		return nil, nil
 	}
 
-	// If the requested number of items is less than the paging max limit
-	// set the limit to that instead
-	maxResults := int32(100)
-	limit := d.QueryContext.Limit
-	if d.QueryContext.Limit != nil {
-		if *limit < int64(maxResults) {
-			if *limit < 1 {
-				maxResults = int32(1)
-			} else {
-				maxResults = int32(*limit)
-			}
-		}
-	}
-
-	err := listOrgWorkspaceMembers(ctx, d, h, workspace.IdentityId, workspace.Handle, maxResults)
+	// Workspace members only exist for org workspaces
+	identity, err := resolveIdentity(ctx, d, h, workspace.IdentityId)
 	if err != nil {
-		plugin.Logger(ctx).Error("listOrganizationWorkspaceMembers", "error", err)
+		plugin.Logger(ctx).Error("listOrganizationWorkspaceMembers", "resolveIdentity", err)
 		return nil, err
 	}
+	if identity.isUser() {
+		return nil, nil
+	}
 
+	err = listOrgWorkspaceMembers(ctx, d, h, identity.Handle, workspace.Handle)
 	if err != nil {
 		plugin.Logger(ctx).Error("listOrganizationWorkspaceMembers", "error", err)
 		return nil, err
 	}
+
 	return nil, nil
 }
 
-func listOrgWorkspaceMembers(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData, orgHandle string, workspaceHandle string, maxResults int32) error {
+func listOrgWorkspaceMembers(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData, orgHandle string, workspaceHandle string) error {
 	// Create Session
 	svc, err := connect(ctx, d)
 	if err != nil {
@@ -183,50 +174,14 @@ func listOrgWorkspaceMembers(ctx context.Context, d *plugin.QueryData, h *plugin
 		return err
 	}
 
-	pagesLeft := true
-	var resp openapi.ListOrgWorkspaceUsersResponse
-	var listDetails func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error)
-
-	for pagesLeft {
-		if resp.NextToken != nil {
-			listDetails = func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
-				resp, _, err = svc.OrgWorkspaceMembers.List(ctx, orgHandle, workspaceHandle).NextToken(*resp.NextToken).Limit(maxResults).Execute()
-				return resp, err
-			}
-		} else {
-			listDetails = func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
-				resp, _, err = svc.OrgWorkspaceMembers.List(ctx, orgHandle, workspaceHandle).Limit(maxResults).Execute()
-				return resp, err
-			}
-		}
-
-		response, err := plugin.RetryHydrate(ctx, d, h, listDetails, &plugin.RetryConfig{ShouldRetryError: shouldRetryError})
-
-		if err != nil {
-			plugin.Logger(ctx).Error("listOrgWorkspaceMembers", "list", err)
-			return err
-		}
-
-		result := response.(openapi.ListOrgWorkspaceUsersResponse)
-
-		if result.HasItems() {
-			for _, member := range *result.Items {
-				d.StreamListItem(ctx, member)
-
-				// Context can be cancelled due to manual cancellation or the limit has been hit
-				if d.RowsRemaining(ctx) == 0 {
-					return nil
-				}
-			}
+	return paginate(ctx, d, func(ctx context.Context, nextToken *string, limit int32) ([]openapi.OrgWorkspaceUser, *string, error) {
+		req := svc.OrgWorkspaceMembers.List(ctx, orgHandle, workspaceHandle).Limit(limit)
+		if nextToken != nil {
+			req = req.NextToken(*nextToken)
 		}
-		if result.NextToken == nil {
-			pagesLeft = false
-		} else {
-			resp.NextToken = result.NextToken
-		}
-	}
-
-	return nil
+		resp, _, err := req.Execute()
+		return resp.GetItems(), resp.NextToken, err
+	})
 }
 
 func getOrganizationWorkspaceMember(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
@@ -250,19 +205,16 @@ func getOrganizationWorkspaceMember(ctx context.Context, d *plugin.QueryData, h
 
 	getDetails := func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
 		orgWorkspaceUser, _, err = svc.OrgWorkspaceMembers.Get(ctx, orgHandle, workspaceHandle, userhandle).Execute()
-		return orgWorkspaceUser, err
+		return orgWorkspaceUser, classifyError(err)
 	}
 
-	response, err := plugin.RetryHydrate(ctx, d, h, getDetails, &plugin.RetryConfig{ShouldRetryError: shouldRetryError})
-
-	orgWorkspaceUser = response.(openapi.OrgWorkspaceUser)
-
+	response, err := plugin.RetryHydrate(ctx, d, h, getDetails, &plugin.RetryConfig{})
 	if err != nil {
 		plugin.Logger(ctx).Error("getOrganizationWorkspaceMember", "get", err)
 		return nil, err
 	}
 
-	return orgWorkspaceUser, nil
+	return response.(openapi.OrgWorkspaceUser), nil
 }
 
 func getOrgWorkspaceDetails(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
@@ -279,9 +231,9 @@ func getOrgWorkspaceDetails(ctx context.Context, d *plugin.QueryData, h *plugin.
 	case openapi.Workspace:
 		getDetails := func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
 			resp, _, err := svc.Orgs.Get(ctx, h.ParentItem.(openapi.Workspace).IdentityId).Execute()
-			return resp, err
+			return resp, classifyError(err)
 		}
-		response, _ := plugin.RetryHydrate(ctx, d, h, getDetails, &plugin.RetryConfig{ShouldRetryError: shouldRetryError})
+		response, _ := plugin.RetryHydrate(ctx, d, h, getDetails, &plugin.RetryConfig{})
 		return &OrgWorkspaceDetails{OrgHandle: response.(openapi.Org).Handle, WorkspaceHandle: w.Handle}, nil
 	default:
 		plugin.Logger(ctx).Debug("getOrgDetails", "Unknown Type", w)
@@ -289,9 +241,9 @@ func getOrgWorkspaceDetails(ctx context.Context, d *plugin.QueryData, h *plugin.
 
 	getDetails := func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
 		resp, _, err := svc.Orgs.Get(ctx, h.Item.(openapi.OrgWorkspaceUser).OrgId).Execute()
-		return resp, err
+		return resp, classifyError(err)
 	}
-	response, _ := plugin.RetryHydrate(ctx, d, h, getDetails, &plugin.RetryConfig{ShouldRetryError: shouldRetryError})
+	response, _ := plugin.RetryHydrate(ctx, d, h, getDetails, &plugin.RetryConfig{})
 
 	return &OrgWorkspaceDetails{OrgHandle: response.(openapi.Org).Handle, WorkspaceHandle: h.Item.(openapi.OrgWorkspaceUser).WorkspaceHandle}, nil
 }
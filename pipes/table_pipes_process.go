@@ -3,7 +3,6 @@ package pipes
 import (
 	"context"
 	"fmt"
-	"strings"
 
 	openapi "github.com/turbot/pipes-sdk-go"
 
@@ -12,11 +11,6 @@ import (
 	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
 )
 
-type IdentityDetailsForProcess struct {
-	IdentityHandle string `json:"identity_handle"`
-	IdentityType   string `json:"identity_type"`
-}
-
 //// TABLE DEFINITION
 
 func tablePipesProcess(_ context.Context) *plugin.Table {
@@ -126,162 +120,45 @@ func tablePipesProcess(_ context.Context) *plugin.Table {
 //// LIST FUNCTION
 
 func listIdentityProcesses(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
-	var user openapi.User
-	// If the requested number of items is less than the paging max limit
-	// set the limit to that instead
-	maxResults := int32(100)
-	limit := d.QueryContext.Limit
-	if limit != nil {
-		if *limit < int64(maxResults) {
-			maxResults = int32(*limit)
-		}
-	}
-
 	identityHandle := d.EqualsQuals["identity_handle"].GetStringValue()
 	identityId := d.EqualsQuals["identity_id"].GetStringValue()
-	var identityToPass string
-
-	getUserIdentityCached := plugin.HydrateFunc(getUserIdentity).WithCache()
-	commonData, err := getUserIdentityCached(ctx, d, h)
-	if err != nil {
-		plugin.Logger(ctx).Error("pipes_process.listIdentityProcesses", "getUserIdentityCached", err)
-		return nil, err
-	}
-
-	user = commonData.(openapi.User)
 
 	// Error out if both identity_handle and identity_id is passed
 	if identityHandle != "" && identityId != "" {
 		return nil, fmt.Errorf("please pass any one of identity_handle or identity_id")
 	}
-	if identityHandle != "" {
-		identityToPass = identityHandle
-	} else if identityId != "" {
-		identityToPass = identityId
-	} else {
-		identityToPass = user.Id
-	}
-
-	if strings.HasPrefix(identityToPass, "u_") || identityToPass == user.Handle {
-		err = listUserProcesses(ctx, d, h, identityToPass, maxResults)
-	} else if strings.HasPrefix(identityToPass, "o_") || identityToPass != user.Handle {
-		err = listOrgProcesses(ctx, d, h, identityToPass, maxResults)
-	}
 
+	identity, err := identityFromQuals(ctx, d, h)
 	if err != nil {
-		plugin.Logger(ctx).Error("pipes_process.listIdentityProcesses", "query_error", err)
+		plugin.Logger(ctx).Error("pipes_process.listIdentityProcesses", "identityFromQuals", err)
 		return nil, err
 	}
 
-	return nil, nil
-}
-
-func listUserProcesses(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData, userHandle string, maxResults int32) error {
-	// Create Session
-	svc, err := connect(ctx, d)
-	if err != nil {
-		plugin.Logger(ctx).Error("pipes_process.listUserProcesses", "connection_error", err)
-		return err
-	}
-
-	pagesLeft := true
-	var resp openapi.ListProcessesResponse
-	var listDetails func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error)
-
-	for pagesLeft {
-		if resp.NextToken != nil {
-			listDetails = func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
-				resp, _, err = svc.UserProcesses.List(ctx, userHandle).NextToken(*resp.NextToken).Limit(maxResults).Execute()
-				return resp, err
-			}
-		} else {
-			listDetails = func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
-				resp, _, err = svc.UserProcesses.List(ctx, userHandle).Limit(maxResults).Execute()
-				return resp, err
-			}
-		}
-
-		response, err := plugin.RetryHydrate(ctx, d, h, listDetails, &plugin.RetryConfig{})
-
+	// Default to the processes of the connection user
+	if identity == nil {
+		user, err := getUserIdentity(ctx, d, h)
 		if err != nil {
-			plugin.Logger(ctx).Error("pipes_process.listUserProcesses", "query_error", err)
-			return err
-		}
-
-		result := response.(openapi.ListProcessesResponse)
-
-		if result.HasItems() {
-			for _, process := range *result.Items {
-				d.StreamListItem(ctx, process)
-
-				// Context can be cancelled due to manual cancellation or the limit has been hit
-				if d.RowsRemaining(ctx) == 0 {
-					return nil
-				}
-			}
+			plugin.Logger(ctx).Error("pipes_process.listIdentityProcesses", "getUserIdentity", err)
+			return nil, err
 		}
-		if result.NextToken == nil {
-			pagesLeft = false
-		} else {
-			resp.NextToken = result.NextToken
+		identity, err = resolveIdentity(ctx, d, h, user.(openapi.User).Id)
+		if err != nil {
+			return nil, err
 		}
 	}
 
-	return nil
-}
-
-func listOrgProcesses(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData, orgHandle string, maxResults int32) error {
-	// Create Session
-	svc, err := connect(ctx, d)
+	api, err := newIdentityService(ctx, d, identity)
 	if err != nil {
-		plugin.Logger(ctx).Error("pipes_process.listOrgProcesses", "connection_error", err)
-		return err
+		return nil, err
 	}
 
-	pagesLeft := true
-	var resp openapi.ListProcessesResponse
-	var listDetails func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error)
-
-	for pagesLeft {
-		if resp.NextToken != nil {
-			listDetails = func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
-				resp, _, err = svc.OrgProcesses.List(ctx, orgHandle).NextToken(*resp.NextToken).Limit(maxResults).Execute()
-				return resp, err
-			}
-		} else {
-			listDetails = func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
-				resp, _, err = svc.OrgProcesses.List(ctx, orgHandle).Limit(maxResults).Execute()
-				return resp, err
-			}
-		}
-
-		response, err := plugin.RetryHydrate(ctx, d, h, listDetails, &plugin.RetryConfig{})
-
-		if err != nil {
-			plugin.Logger(ctx).Error("pipes_process.listOrgProcesses", "query_error", err)
-			return err
-		}
-
-		result := response.(openapi.ListProcessesResponse)
-
-		if result.HasItems() {
-			for _, process := range *result.Items {
-				d.StreamListItem(ctx, process)
-
-				// Context can be cancelled due to manual cancellation or the limit has been hit
-				if d.RowsRemaining(ctx) == 0 {
-					return nil
-				}
-			}
-		}
-		if result.NextToken == nil {
-			pagesLeft = false
-		} else {
-			resp.NextToken = result.NextToken
-		}
+	err = paginate(ctx, d, api.ListProcesses())
+	if err != nil {
+		plugin.Logger(ctx).Error("pipes_process.listIdentityProcesses", "query_error", err)
+		return nil, err
 	}
 
-	return nil
+	return nil, nil
 }
 
 func getIdentityProcess(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
@@ -293,108 +170,32 @@ func getIdentityProcess(ctx context.Context, d *plugin.QueryData, h *plugin.Hydr
 		return nil, nil
 	}
 
-	getUserIdentityCached := plugin.HydrateFunc(getUserIdentity).WithCache()
-	commonData, err := getUserIdentityCached(ctx, d, h)
-	if err != nil {
-		plugin.Logger(ctx).Error("pipes_process.getIdentityProcess", "connection_error", err)
-		return nil, err
-	}
-
-	user := commonData.(openapi.User)
-	var response interface{}
-	if identityHandle == user.Handle {
-		response, err = getUserProcess(ctx, d, h, identityHandle, processId)
-	} else {
-		response, err = getOrgProcess(ctx, d, h, identityHandle, processId)
-	}
-
-	if err != nil {
-		plugin.Logger(ctx).Error("pipes_process.getIdentityProcess", "query_error", err)
-		return nil, err
-	}
-
-	return response.(openapi.SpProcess), nil
-}
-
-func getUserProcess(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData, userHandle, processId string) (interface{}, error) {
-	// Create Session
-	svc, err := connect(ctx, d)
-	if err != nil {
-		plugin.Logger(ctx).Error("pipes_process.getUserProcess", "connection_error", err)
-		return nil, err
-	}
-
-	var process openapi.SpProcess
-
-	getDetails := func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
-		process, _, err = svc.UserProcesses.Get(ctx, userHandle, processId).Execute()
-		return process, err
-	}
-
-	response, err := plugin.RetryHydrate(ctx, d, h, getDetails, &plugin.RetryConfig{})
-	if err != nil {
-		plugin.Logger(ctx).Error("pipes_process.getUserProcess", "query_error", err)
-		return nil, err
-	}
-
-	return response, nil
-}
-
-func getOrgProcess(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData, orgHandle, processId string) (interface{}, error) {
-	// Create Session
-	svc, err := connect(ctx, d)
+	api, err := getIdentityService(ctx, d, h, identityHandle)
 	if err != nil {
-		plugin.Logger(ctx).Error("pipes_process.getOrgProcess", "connection_error", err)
+		plugin.Logger(ctx).Error("pipes_process.getIdentityProcess", "getIdentityService", err)
 		return nil, err
 	}
 
-	var process openapi.SpProcess
-
 	getDetails := func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
-		process, _, err = svc.OrgProcesses.Get(ctx, orgHandle, processId).Execute()
-		return process, err
+		return api.GetProcess(ctx, processId)
 	}
 
 	response, err := plugin.RetryHydrate(ctx, d, h, getDetails, &plugin.RetryConfig{})
 	if err != nil {
-		plugin.Logger(ctx).Error("pipes_process.getOrgProcess", "query_error", err)
+		plugin.Logger(ctx).Error("pipes_process.getIdentityProcess", "query_error", err)
 		return nil, err
 	}
 
-	return response, nil
+	return response.(openapi.SpProcess), nil
 }
 
 func getIdentityDetailsForProcess(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
-	// Create Session
-	svc, err := connect(ctx, d)
-	if err != nil {
-		plugin.Logger(ctx).Error("pipes_process.getIdentityDetailsForProcess", "connection_error", err)
-		return nil, err
-	}
+	process := h.Item.(openapi.SpProcess)
 
-	getUserIdentityCached := plugin.HydrateFunc(getUserIdentity).WithCache()
-	commonData, err := getUserIdentityCached(ctx, d, h)
+	details, err := getIdentityDetailsForId(ctx, d, h, process.GetIdentityId())
 	if err != nil {
-		plugin.Logger(ctx).Error("pipes_process.getIdentityDetailsForProcess", "getUserIdentityCached", err)
+		plugin.Logger(ctx).Error("pipes_process.getIdentityDetailsForProcess", "query_error", err)
 		return nil, err
 	}
-
-	user := commonData.(openapi.User)
-
-	var identityDetails IdentityDetailsForProcess
-	process := h.Item.(openapi.SpProcess)
-	plugin.Logger(ctx).Info("getIdentityDetailsForProcess", "process Item", process)
-	if *process.IdentityId == user.Id {
-		identityDetails.IdentityHandle = user.Handle
-		identityDetails.IdentityType = "user"
-	} else {
-		org, _, err := svc.Orgs.Get(ctx, *process.IdentityId).Execute()
-		if err != nil {
-			plugin.Logger(ctx).Error("pipes_process.getIdentityDetailsForProcess", "query_error", err)
-			return nil, err
-		}
-		identityDetails.IdentityHandle = org.Handle
-		identityDetails.IdentityType = "org"
-	}
-	return identityDetails, nil
+	return details, nil
 }
@@ -144,62 +144,17 @@ func listTenants(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData
 		return nil, err
 	}
 
-	// If the requested number of items is less than the paging max limit
-	// set the limit to that instead
-	maxResults := int32(100)
-	limit := d.QueryContext.Limit
-	if d.QueryContext.Limit != nil {
-		if *limit < int64(maxResults) {
-			if *limit < 1 {
-				maxResults = int32(1)
-			} else {
-				maxResults = int32(*limit)
-			}
-		}
-	}
-
-	// execute list call
-	pagesLeft := true
-	var resp openapi.ListTenantsResponse
-	var listDetails func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error)
-
-	for pagesLeft {
-		if resp.NextToken != nil {
-			listDetails = func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
-				resp, _, err = svc.Tenants.List(ctx).NextToken(*resp.NextToken).Limit(maxResults).Execute()
-				return resp, err
-			}
-		} else {
-			listDetails = func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
-				resp, _, err = svc.Tenants.List(ctx).Limit(maxResults).Execute()
-				return resp, err
-			}
-		}
-
-		response, err := plugin.RetryHydrate(ctx, d, h, listDetails, &plugin.RetryConfig{})
-
-		if err != nil {
-			plugin.Logger(ctx).Error("listTenants", "list", err)
-			return nil, err
-		}
-
-		result := response.(openapi.ListTenantsResponse)
-
-		if result.HasItems() {
-			for _, tenant := range *result.Items {
-				d.StreamListItem(ctx, tenant)
-
-				// Context can be canceled due to manual cancellation or the limit has been hit
-				if d.RowsRemaining(ctx) == 0 {
-					return nil, nil
-				}
-			}
-		}
-		if result.NextToken == nil {
-			pagesLeft = false
-		} else {
-			resp.NextToken = result.NextToken
+	err = paginate(ctx, d, func(ctx context.Context, nextToken *string, limit int32) ([]openapi.Tenant, *string, error) {
+		req := svc.Tenants.List(ctx).Limit(limit)
+		if nextToken != nil {
+			req = req.NextToken(*nextToken)
 		}
+		resp, _, err := req.Execute()
+		return resp.GetItems(), resp.NextToken, err
+	})
+	if err != nil {
+		plugin.Logger(ctx).Error("pipes_tenant.listTenants", "list", err)
+		return nil, err
 	}
 
 	return nil, nil
@@ -226,7 +181,7 @@ func getTenant(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData)
 	// execute get call
 	getDetails := func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
 		resp, _, err = svc.Tenants.Get(ctx, handle).Execute()
-		return resp, err
+		return resp, classifyError(err)
 	}
 
 	response, err := plugin.RetryHydrate(ctx, d, h, getDetails, &plugin.RetryConfig{})
@@ -23,7 +23,7 @@ func tablePipesToken(_ context.Context) *plugin.Table {
 			KeyColumns: plugin.SingleColumn("id"),
 			Hydrate:    getToken,
 		},
-		Columns: []*plugin.Column{
+		Columns: connectionColumns([]*plugin.Column{
 			{
 				Name:        "id",
 				Description: "The unique identifier for the token.",
@@ -62,7 +62,7 @@ func tablePipesToken(_ context.Context) *plugin.Table {
 				Description: "The token's last updated time.",
 				Type:        proto.ColumnType_TIMESTAMP,
 			},
-		},
+		}),
 	}
 }
 
@@ -84,63 +84,17 @@ func listTokens(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData)
 
 	user := commonData.(openapi.User)
 
-	// If the requested number of items is less than the paging max limit
-	// set the limit to that instead
-	maxResults := int32(100)
-	limit := d.QueryContext.Limit
-	if d.QueryContext.Limit != nil {
-		if *limit < int64(maxResults) {
-			if *limit < 1 {
-				maxResults = int32(1)
-			} else {
-				maxResults = int32(*limit)
-			}
-		}
-	}
-
-	// execute list call
-	pagesLeft := true
-
-	var resp openapi.ListTokensResponse
-	var listDetails func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error)
-
-	for pagesLeft {
-		if resp.NextToken != nil {
-			listDetails = func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
-				resp, _, err = svc.UserTokens.List(ctx, user.Handle).NextToken(*resp.NextToken).Limit(maxResults).Execute()
-				return resp, err
-			}
-		} else {
-			listDetails = func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
-				resp, _, err = svc.UserTokens.List(ctx, user.Handle).Limit(maxResults).Execute()
-				return resp, err
-			}
-		}
-
-		response, err := plugin.RetryHydrate(ctx, d, h, listDetails, &plugin.RetryConfig{})
-
-		if err != nil {
-			plugin.Logger(ctx).Error("listTokens", "list", err)
-			return nil, err
-		}
-
-		result := response.(openapi.ListTokensResponse)
-
-		if result.HasItems() {
-			for _, token := range *result.Items {
-				d.StreamListItem(ctx, token)
-
-				// Context can be cancelled due to manual cancellation or the limit has been hit
-				if d.RowsRemaining(ctx) == 0 {
-					return nil, nil
-				}
-			}
-		}
-		if result.NextToken == nil {
-			pagesLeft = false
-		} else {
-			resp.NextToken = result.NextToken
+	err = paginate(ctx, d, func(ctx context.Context, nextToken *string, limit int32) ([]openapi.Token, *string, error) {
+		req := svc.UserTokens.List(ctx, user.Handle).Limit(limit)
+		if nextToken != nil {
+			req = req.NextToken(*nextToken)
 		}
+		resp, _, err := req.Execute()
+		return resp.GetItems(), resp.NextToken, err
+	})
+	if err != nil {
+		plugin.Logger(ctx).Error("listTokens", "list", err)
+		return nil, err
 	}
 
 	return nil, nil
@@ -170,7 +124,7 @@ func getToken(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (
 
 	getDetails := func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
 		resp, _, err = svc.UserTokens.Get(ctx, id, user.Handle).Execute()
-		return resp, err
+		return resp, classifyError(err)
 	}
 
 	response, err := plugin.RetryHydrate(ctx, d, h, getDetails, &plugin.RetryConfig{})
@@ -0,0 +1,215 @@
+package pipes
+
+import (
+	"context"
+	"net/http"
+
+	openapi "github.com/turbot/pipes-sdk-go"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+//// TABLE DEFINITION
+
+func tablePipesUsage(_ context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "pipes_usage",
+		Description: "Usage records the daily compute, storage and user usage of a user or organization and its workspaces.",
+		List: &plugin.ListConfig{
+			Hydrate: listUsage,
+			KeyColumns: []*plugin.KeyColumn{
+				{
+					Name:      "dimension",
+					Require:   plugin.Optional,
+					Operators: []string{"=", "<>"},
+				},
+				{
+					Name:    "identity_handle",
+					Require: plugin.Optional,
+				},
+				{
+					Name:    "identity_id",
+					Require: plugin.Optional,
+				},
+				{
+					Name:      "metric",
+					Require:   plugin.Optional,
+					Operators: []string{"=", "<>"},
+				},
+				{
+					Name:      "usage_date",
+					Require:   plugin.Optional,
+					Operators: []string{">", ">=", "=", "<", "<="},
+				},
+				{
+					Name:    "workspace_handle",
+					Require: plugin.Optional,
+				},
+			},
+		},
+		Columns: commonColumns([]*plugin.Column{
+			{
+				Name:        "usage_date",
+				Description: "The day the usage was recorded for.",
+				Type:        proto.ColumnType_TIMESTAMP,
+			},
+			{
+				Name:        "dimension",
+				Description: "The dimension of the usage. Can be one of 'compute', 'storage' or 'user'.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "metric",
+				Description: "The usage metric, e.g. 'db_execution_ms', 'db_volume_bytes', 'process_execution_ms', 'snapshot_storage_bytes' or 'user_count'.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "unit",
+				Description: "The unit of the usage values. Can be one of 'millisecond', 'byte' or 'count'.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "value",
+				Description: "The usage for the day, in the metric's unit.",
+				Type:        proto.ColumnType_INT,
+			},
+			{
+				Name:        "value_rounded",
+				Description: "The usage rounded to the nearest billable unit.",
+				Type:        proto.ColumnType_INT,
+			},
+			{
+				Name:        "value_weighted",
+				Description: "The usage weighted according to billing rules such as the database instance type.",
+				Type:        proto.ColumnType_INT,
+			},
+			{
+				Name:        "instance_type",
+				Description: "The database instance type of the workspace, for workspace usage.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "pipe",
+				Description: "The pipe the usage was recorded for.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "identity_id",
+				Description: "The unique identifier for the identity the usage belongs to.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromCamel(),
+			},
+			{
+				Name:        "identity_handle",
+				Description: "The handle of the identity the usage belongs to.",
+				Type:        proto.ColumnType_STRING,
+				Hydrate:     getIdentityDetailsForUsage,
+			},
+			{
+				Name:        "identity_type",
+				Description: "The type of identity, which can be 'user' or 'org'.",
+				Type:        proto.ColumnType_STRING,
+				Hydrate:     getIdentityDetailsForUsage,
+			},
+			{
+				Name:        "workspace_id",
+				Description: "The unique identifier for the workspace the usage belongs to, for workspace usage.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromCamel(),
+			},
+			{
+				Name:        "workspace_handle",
+				Description: "The handle of the workspace the usage belongs to. Only set when workspace_handle is specified in the where clause.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "tenant_id",
+				Description: "The unique identifier for the tenant the usage belongs to.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromCamel(),
+			},
+		}),
+	}
+}
+
+// Usage is a usage record of an identity. The API only sets the identity on
+// some records, so it is filled in from the identity that was queried.
+type Usage struct {
+	openapi.UsageMetric
+	IdentityId      string
+	WorkspaceHandle *string
+}
+
+//// LIST FUNCTION
+
+func listUsage(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	identities, err := identitiesFromQuals(ctx, d, h)
+	if err != nil {
+		plugin.Logger(ctx).Error("listUsage", "identitiesFromQuals", err)
+		return nil, err
+	}
+
+	// Build the where filter from the quals, with all values escaped
+	filter := buildListFilter(d)
+
+	// Only skip the orgs whose usage can't be read when listing every
+	// identity, an org asked for by the quals must be readable
+	spansIdentities := d.EqualsQualString("identity_handle") == "" && d.EqualsQualString("identity_id") == ""
+
+	var workspaceHandle *string
+	if handle := d.EqualsQualString("workspace_handle"); handle != "" {
+		workspaceHandle = &handle
+	}
+
+	for _, identity := range identities {
+		api, err := newIdentityService(ctx, d, identity)
+		if err != nil {
+			return nil, err
+		}
+
+		list := api.ListUsage(filter)
+		if workspaceHandle != nil {
+			list = api.ListWorkspaceUsage(*workspaceHandle, filter)
+		}
+
+		err = paginate(ctx, d, func(ctx context.Context, nextToken *string, limit int32) ([]Usage, *string, error) {
+			metrics, pageToken, err := list(ctx, nextToken, limit)
+			items := make([]Usage, 0, len(metrics))
+			for _, metric := range metrics {
+				items = append(items, Usage{UsageMetric: metric, IdentityId: identity.Id, WorkspaceHandle: workspaceHandle})
+			}
+			return items, pageToken, err
+		})
+		switch {
+		case err == nil:
+		case spansIdentities && workspaceHandle != nil && errorStatusCode(err) == http.StatusNotFound:
+			// The workspace belongs to another identity
+		case spansIdentities && identity.Type == identityTypeOrg && isIdentityAccessError(err):
+			plugin.Logger(ctx).Warn("listUsage", "skipping org, usage not accessible", err, "identity", identity.Handle)
+		default:
+			plugin.Logger(ctx).Error("listUsage", "list", err, "identity", identity.Handle)
+			return nil, err
+		}
+
+		// Context can be cancelled due to manual cancellation or the limit has been hit
+		if d.RowsRemaining(ctx) == 0 {
+			return nil, nil
+		}
+	}
+	return nil, nil
+}
+
+//// HYDRATE FUNCTIONS
+
+func getIdentityDetailsForUsage(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	usage := h.Item.(Usage)
+
+	details, err := getIdentityDetailsForId(ctx, d, h, usage.IdentityId)
+	if err != nil {
+		plugin.Logger(ctx).Error("getIdentityDetailsForUsage", "error", err)
+		return nil, err
+	}
+	return details, nil
+}
@@ -81,7 +81,7 @@ func getUserPreferences(ctx context.Context, d *plugin.QueryData, h *plugin.Hydr
 	// Function to fetch the user preferences
 	getDetails := func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
 		resp, _, err := svc.Users.GetPreferences(ctx, user.Handle).Execute()
-		return resp, err
+		return resp, classifyError(err)
 	}
 
 	// Execute function to fetch the user preferences
@@ -2,7 +2,7 @@ package pipes
 
 import (
 	"context"
-	"strings"
+	"net/http"
 
 	openapi "github.com/turbot/pipes-sdk-go"
 
@@ -11,11 +11,6 @@ import (
 	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
 )
 
-type IdentityDetails struct {
-	IdentityHandle string `json:"identity_handle"`
-	IdentityType   string `json:"identity_type"`
-}
-
 //// TABLE DEFINITION
 
 func tablePipesWorkspace(_ context.Context) *plugin.Table {
@@ -147,51 +142,37 @@ func tablePipesWorkspace(_ context.Context) *plugin.Table {
 //// LIST FUNCTION
 
 func listWorkspaces(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
-	// Create Session
-	svc, err := connect(ctx, d)
-	if err != nil {
-		plugin.Logger(ctx).Error("listWorkspaces", "connection_error", err)
-		return nil, err
+	if d.EqualsQualString("identity_handle") == "" && d.EqualsQualString("identity_id") == "" {
+		// Create Session
+		svc, err := connect(ctx, d)
+		if err != nil {
+			plugin.Logger(ctx).Error("listWorkspaces", "connection_error", err)
+			return nil, err
+		}
+
+		err = listActorWorkspaces(ctx, d, svc, "")
+		if err != nil {
+			plugin.Logger(ctx).Error("listWorkspaces", "list", err)
+			return nil, err
+		}
+		return nil, nil
 	}
 
-	getUserIdentityCached := plugin.HydrateFunc(getUserIdentity).WithCache()
-	commonData, err := getUserIdentityCached(ctx, d, h)
+	identity, err := identityFromQuals(ctx, d, h)
 	if err != nil {
-		plugin.Logger(ctx).Error("listWorkspaces", "getUserIdentityCached", err)
+		plugin.Logger(ctx).Error("listWorkspaces", "identityFromQuals", err)
 		return nil, err
 	}
-
-	user := commonData.(openapi.User)
-
-	identityHandle := d.EqualsQuals["identity_handle"].GetStringValue()
-	identityId := d.EqualsQuals["identity_id"].GetStringValue()
-
-	// If the requested number of items is less than the paging max limit
-	// set the limit to that instead
-	maxResults := int32(100)
-	limit := d.QueryContext.Limit
-	if d.QueryContext.Limit != nil {
-		if *limit < int64(maxResults) {
-			if *limit < 1 {
-				maxResults = int32(1)
-			} else {
-				maxResults = int32(*limit)
-			}
-		}
+	if identity == nil {
+		return nil, nil
 	}
 
-	if identityHandle == "" && identityId == "" {
-		err = listActorWorkspaces(ctx, d, h, svc, maxResults)
-	} else if identityId != "" && strings.HasPrefix(identityId, "u_") {
-		err = listUserWorkspaces(ctx, d, h, identityId, svc, maxResults)
-	} else if identityId != "" && strings.HasPrefix(identityId, "o_") {
-		err = listOrgWorkspaces(ctx, d, h, identityId, svc, maxResults)
-	} else if identityHandle == user.Handle {
-		err = listUserWorkspaces(ctx, d, h, identityHandle, svc, maxResults)
-	} else {
-		err = listOrgWorkspaces(ctx, d, h, identityHandle, svc, maxResults)
+	api, err := newIdentityService(ctx, d, identity)
+	if err != nil {
+		return nil, err
 	}
 
+	err = paginate(ctx, d, api.ListWorkspaces())
 	if err != nil {
 		plugin.Logger(ctx).Error("listWorkspaces", "list", err)
 		return nil, err
@@ -199,277 +180,142 @@ func listWorkspaces(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateD
 	return nil, nil
 }
 
-func listUserWorkspaces(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData, handle string, svc *openapi.APIClient, maxResults int32) error {
-	var err error
-
-	// execute list call
-	pagesLeft := true
-
-	var resp openapi.ListWorkspacesResponse
-	var listDetails func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error)
-
-	for pagesLeft {
-		if resp.NextToken != nil {
-			listDetails = func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
-				resp, _, err = svc.UserWorkspaces.List(ctx, handle).NextToken(*resp.NextToken).Limit(maxResults).Execute()
-				return resp, err
-			}
-		} else {
-			listDetails = func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
-				resp, _, err = svc.UserWorkspaces.List(ctx, handle).Limit(maxResults).Execute()
-				return resp, err
-			}
-		}
-
-		response, err := plugin.RetryHydrate(ctx, d, h, listDetails, &plugin.RetryConfig{})
-
-		if err != nil {
-			plugin.Logger(ctx).Error("listUserWorkspaces", "list", err)
-			return err
+// listActorWorkspaces streams the workspaces of the connection user and their
+// orgs which match the filter and the workspace quals, if any.
+func listActorWorkspaces(ctx context.Context, d *plugin.QueryData, svc *openapi.APIClient, filter string) error {
+	return paginate(ctx, d, func(ctx context.Context, nextToken *string, limit int32) ([]*openapi.Workspace, *string, error) {
+		req := svc.Actors.ListWorkspaces(withQueryFilter(ctx, filter)).Limit(limit)
+		if nextToken != nil {
+			req = req.NextToken(*nextToken)
 		}
+		resp, _, err := req.Execute()
 
-		result := response.(openapi.ListWorkspacesResponse)
-
-		if result.HasItems() {
-			for _, workspace := range *result.Items {
-				d.StreamListItem(ctx, workspace)
-
-				// Context can be cancelled due to manual cancellation or the limit has been hit
-				if d.RowsRemaining(ctx) == 0 {
-					return nil
-				}
+		var workspaces []*openapi.Workspace
+		for _, actorWorkspace := range resp.GetItems() {
+			if actorWorkspace.Workspace == nil || !workspaceMatchesQuals(d, actorWorkspace.Workspace) {
+				continue
 			}
+			workspaces = append(workspaces, actorWorkspace.Workspace)
 		}
-		if result.NextToken == nil {
-			pagesLeft = false
-		} else {
-			resp.NextToken = result.NextToken
-		}
-	}
-
-	return nil
+		return workspaces, resp.NextToken, err
+	})
 }
 
-func listOrgWorkspaces(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData, handle string, svc *openapi.APIClient, maxResults int32) error {
-	var err error
-
-	// execute list call
-	pagesLeft := true
-
-	var resp openapi.ListWorkspacesResponse
-	var listDetails func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error)
-
-	for pagesLeft {
-		if resp.NextToken != nil {
-			listDetails = func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
-				resp, _, err = svc.OrgWorkspaces.List(ctx, handle).NextToken(*resp.NextToken).Limit(maxResults).Execute()
-				return resp, err
-			}
-		} else {
-			listDetails = func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
-				resp, _, err = svc.OrgWorkspaces.List(ctx, handle).Limit(maxResults).Execute()
-				return resp, err
-			}
-		}
-
-		response, err := plugin.RetryHydrate(ctx, d, h, listDetails, &plugin.RetryConfig{})
-
-		if err != nil {
-			plugin.Logger(ctx).Error("listOrgWorkspaces", "list", err)
-			return err
-		}
-
-		result := response.(openapi.ListWorkspacesResponse)
-
-		if result.HasItems() {
-			for _, workspace := range *result.Items {
-				d.StreamListItem(ctx, workspace)
-
-				// Context can be cancelled due to manual cancellation or the limit has been hit
-				if d.RowsRemaining(ctx) == 0 {
-					return nil
-				}
-			}
-		}
-		if result.NextToken == nil {
-			pagesLeft = false
-		} else {
-			resp.NextToken = result.NextToken
-		}
+// listQualWorkspaces is a parent hydrate for workspace-scoped tables which
+// short-circuits the workspace list: if the identity and the workspace are
+// both given by the quals, only that workspace is fetched, and if only the
+// workspace is given, the connection user's workspaces are listed filtered to
+// it. Otherwise the workspaces are listed as for listWorkspaces. Either way
+// the child list call must still check workspaceMatchesQuals.
+func listQualWorkspaces(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	workspaceHandleOrId := d.EqualsQualString("workspace_handle")
+	if workspaceHandleOrId == "" {
+		workspaceHandleOrId = d.EqualsQualString("workspace_id")
 	}
-
-	return nil
-}
-
-func listActorWorkspaces(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData, svc *openapi.APIClient, maxResults int32) error {
-	var err error
-
-	// execute list call
-	pagesLeft := true
-
-	var resp openapi.ListActorWorkspacesResponse
-	var listDetails func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error)
-
-	for pagesLeft {
-		if resp.NextToken != nil {
-			listDetails = func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
-				resp, _, err = svc.Actors.ListWorkspaces(ctx).NextToken(*resp.NextToken).Limit(maxResults).Execute()
-				return resp, err
-			}
-		} else {
-			listDetails = func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
-				resp, _, err = svc.Actors.ListWorkspaces(ctx).Limit(maxResults).Execute()
-				return resp, err
-			}
-		}
-
-		response, err := plugin.RetryHydrate(ctx, d, h, listDetails, &plugin.RetryConfig{})
-
-		if err != nil {
-			plugin.Logger(ctx).Error("listActorWorkspaces", "list", err)
-			return err
-		}
-
-		result := response.(openapi.ListActorWorkspacesResponse)
-
-		if result.HasItems() {
-			for _, actorWorkspace := range *result.Items {
-				d.StreamListItem(ctx, actorWorkspace.Workspace)
-
-				// Context can be cancelled due to manual cancellation or the limit has been hit
-				if d.RowsRemaining(ctx) == 0 {
-					return nil
-				}
-			}
-		}
-		if result.NextToken == nil {
-			pagesLeft = false
-		} else {
-			resp.NextToken = result.NextToken
-		}
+	if workspaceHandleOrId == "" {
+		return listWorkspaces(ctx, d, h)
+	}
+	if d.EqualsQualString("identity_handle") == "" && d.EqualsQualString("identity_id") == "" {
+		return listQualActorWorkspaces(ctx, d)
 	}
 
-	return nil
-}
-
-//// HYDRATE FUNCTIONS
-
-func getWorkspace(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
-	identityHandle := d.EqualsQuals["identity_handle"].GetStringValue()
-	handle := d.EqualsQuals["handle"].GetStringValue()
-
-	// check if handle or identityHandle is empty
-	if identityHandle == "" || handle == "" {
+	identity, err := identityFromQuals(ctx, d, h)
+	if err != nil {
+		plugin.Logger(ctx).Error("listQualWorkspaces", "identityFromQuals", err)
+		return nil, err
+	}
+	if identity == nil {
 		return nil, nil
 	}
 
-	// Create Session
-	svc, err := connect(ctx, d)
+	api, err := newIdentityService(ctx, d, identity)
 	if err != nil {
-		plugin.Logger(ctx).Error("getWorkspace", "connection_error", err)
 		return nil, err
 	}
 
-	getUserIdentityCached := plugin.HydrateFunc(getUserIdentity).WithCache()
-	commonData, err := getUserIdentityCached(ctx, d, h)
+	getDetails := func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+		return api.GetWorkspace(ctx, workspaceHandleOrId)
+	}
+	response, err := plugin.RetryHydrate(ctx, d, h, getDetails, &plugin.RetryConfig{})
 	if err != nil {
-		plugin.Logger(ctx).Error("getWorkspace", "getUserIdentityCached", err)
+		if errorStatusCode(err) == http.StatusNotFound {
+			return nil, nil
+		}
+		plugin.Logger(ctx).Error("listQualWorkspaces", "get", err)
 		return nil, err
 	}
 
-	user := commonData.(openapi.User)
-	var resp interface{}
-
-	if identityHandle == user.Handle {
-		resp, err = getUserWorkspace(ctx, d, h, identityHandle, handle, svc)
-	} else {
-		resp, err = getOrgWorkspace(ctx, d, h, identityHandle, handle, svc)
+	workspace := response.(openapi.Workspace)
+	if !workspaceMatchesQuals(d, &workspace) {
+		return nil, nil
 	}
+	d.StreamListItem(ctx, workspace)
+	return nil, nil
+}
 
+// listQualActorWorkspaces lists the workspaces of the connection user and
+// their orgs with the handle or id given by the quals. A handle can be used by
+// a workspace of each identity, so more than one workspace may match.
+func listQualActorWorkspaces(ctx context.Context, d *plugin.QueryData) (interface{}, error) {
+	svc, err := connect(ctx, d)
 	if err != nil {
-		plugin.Logger(ctx).Error("getWorkspace", "get", err)
+		plugin.Logger(ctx).Error("listQualActorWorkspaces", "connection_error", err)
 		return nil, err
 	}
 
-	if resp == nil {
-		return nil, nil
+	filter := &queryFilter{}
+	if workspaceHandle := d.EqualsQualString("workspace_handle"); workspaceHandle != "" {
+		filter.Compare("handle", "=", workspaceHandle)
+	}
+	if workspaceId := d.EqualsQualString("workspace_id"); workspaceId != "" {
+		filter.Compare("workspace_id", "=", workspaceId)
 	}
 
-	return resp.(openapi.Workspace), nil
+	err = listActorWorkspaces(ctx, d, svc, filter.String())
+	if err != nil {
+		plugin.Logger(ctx).Error("listQualActorWorkspaces", "list", err)
+		return nil, err
+	}
+	return nil, nil
 }
 
-func getOrgWorkspace(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData, identityHandle string, handle string, svc *openapi.APIClient) (interface{}, error) {
-	var err error
+//// HYDRATE FUNCTIONS
 
-	// execute get call
-	var resp openapi.Workspace
+func getWorkspace(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	identityHandle := d.EqualsQuals["identity_handle"].GetStringValue()
+	handle := d.EqualsQuals["handle"].GetStringValue()
 
-	getDetails := func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
-		resp, _, err = svc.OrgWorkspaces.Get(ctx, identityHandle, handle).Execute()
-		return resp, err
+	// check if handle or identityHandle is empty
+	if identityHandle == "" || handle == "" {
+		return nil, nil
 	}
 
-	response, err := plugin.RetryHydrate(ctx, d, h, getDetails, &plugin.RetryConfig{})
-
-	workspace := response.(openapi.Workspace)
-
+	api, err := getIdentityService(ctx, d, h, identityHandle)
 	if err != nil {
-		plugin.Logger(ctx).Error("getOrgWorkspace", "get", err)
+		plugin.Logger(ctx).Error("getWorkspace", "getIdentityService", err)
 		return nil, err
 	}
 
-	return workspace, nil
-}
-
-func getUserWorkspace(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData, identityHandle string, handle string, svc *openapi.APIClient) (interface{}, error) {
-	var err error
-
-	// execute get call
-	var resp openapi.Workspace
-
 	getDetails := func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
-		resp, _, err = svc.UserWorkspaces.Get(ctx, identityHandle, handle).Execute()
-		return resp, err
+		return api.GetWorkspace(ctx, handle)
 	}
 
 	response, err := plugin.RetryHydrate(ctx, d, h, getDetails, &plugin.RetryConfig{})
-
-	workspace := response.(openapi.Workspace)
-
 	if err != nil {
-		plugin.Logger(ctx).Error("getUserWorkspace", "get", err)
+		plugin.Logger(ctx).Error("getWorkspace", "get", err)
 		return nil, err
 	}
 
-	return workspace, nil
+	return response.(openapi.Workspace), nil
 }
 
 func getIdentityDetails(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
-	// Create Session
-	svc, err := connect(ctx, d)
-	if err != nil {
-		plugin.Logger(ctx).Error("getIdentityDetails", "connection_error", err)
-		return nil, err
-	}
-
 	// Get the identity id from the workspace hydrate object
-	var identityId string
-	switch w := h.Item.(type) {
-	case openapi.Workspace:
-		identityId = h.Item.(openapi.Workspace).IdentityId
-	case *openapi.Workspace:
-		identityId = h.Item.(*openapi.Workspace).IdentityId
-	default:
-		plugin.Logger(ctx).Debug("getIdentityDetails", "Unknown Type", w)
-	}
-
-	getDetails := func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
-		resp, _, err := svc.Identities.Get(ctx, identityId).Execute()
-		return resp, err
+	workspace := parentWorkspace(h.Item)
+	if workspace == nil {
+		plugin.Logger(ctx).Debug("getIdentityDetails", "Unknown Type", h.Item)
+		return nil, nil
 	}
 
-	response, _ := plugin.RetryHydrate(ctx, d, h, getDetails, &plugin.RetryConfig{})
-	identity := response.(openapi.Identity)
-
-	return &IdentityDetails{IdentityHandle: identity.Handle, IdentityType: identity.Type}, nil
+	return getIdentityDetailsForId(ctx, d, h, workspace.IdentityId)
 }
@@ -0,0 +1,269 @@
+package pipes
+
+import (
+	"context"
+
+	openapi "github.com/turbot/pipes-sdk-go"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+//// TABLE DEFINITION
+
+func tablePipesWorkspaceDatatank(_ context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "pipes_workspace_datatank",
+		Description: "Datatanks store the results of queries in a workspace, refreshed on a schedule, so that they can be queried quickly.",
+		List: &plugin.ListConfig{
+			ParentHydrate: listWorkspaces,
+			Hydrate:       listWorkspaceDatatanks,
+			KeyColumns: []*plugin.KeyColumn{
+				{
+					Name:    "identity_handle",
+					Require: plugin.Optional,
+				},
+				{
+					Name:    "identity_id",
+					Require: plugin.Optional,
+				},
+				{
+					Name:    "workspace_handle",
+					Require: plugin.Optional,
+				},
+				{
+					Name:    "workspace_id",
+					Require: plugin.Optional,
+				},
+			},
+		},
+		Get: &plugin.GetConfig{
+			KeyColumns: plugin.AllColumns([]string{"identity_id", "workspace_id", "handle"}),
+			Hydrate:    getWorkspaceDatatank,
+		},
+		Columns: commonColumns([]*plugin.Column{
+			{
+				Name:        "id",
+				Description: "The unique identifier for the datatank.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromCamel(),
+			},
+			{
+				Name:        "handle",
+				Description: "The handle of the datatank, which is also its schema name in the workspace database.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "description",
+				Description: "The description of the datatank.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "identity_id",
+				Description: "The unique identifier for the identity which contains the workspace.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromCamel(),
+			},
+			{
+				Name:        "identity_handle",
+				Description: "The handle of the identity which contains the workspace.",
+				Type:        proto.ColumnType_STRING,
+				Hydrate:     getIdentityWorkspaceDetailsForWorkspaceDatatank,
+			},
+			{
+				Name:        "identity_type",
+				Description: "The type of identity, which can be 'user' or 'org'.",
+				Type:        proto.ColumnType_STRING,
+				Hydrate:     getIdentityWorkspaceDetailsForWorkspaceDatatank,
+			},
+			{
+				Name:        "workspace_id",
+				Description: "The unique identifier for the workspace.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromCamel(),
+			},
+			{
+				Name:        "workspace_handle",
+				Description: "The handle for the workspace.",
+				Type:        proto.ColumnType_STRING,
+				Hydrate:     getIdentityWorkspaceDetailsForWorkspaceDatatank,
+			},
+			{
+				Name:        "state",
+				Description: "The actual state of the datatank. Can be one of 'enabled', 'paused', 'disabled' or 'deleted'.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "desired_state",
+				Description: "The state the datatank has been asked to move to. Can be one of 'enabled', 'paused' or 'disabled'.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromCamel(),
+			},
+			{
+				Name:        "state_reason",
+				Description: "The reason for the state of the datatank, e.g. the last error if it failed.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromCamel(),
+			},
+			{
+				Name:        "table_count",
+				Description: "The number of tables in the datatank.",
+				Type:        proto.ColumnType_INT,
+				Hydrate:     getWorkspaceDatatankPartCounts,
+				Transform:   transform.FromField("TableCount"),
+			},
+			{
+				Name:        "part_count",
+				Description: "The total number of parts across the tables in the datatank.",
+				Type:        proto.ColumnType_INT,
+				Hydrate:     getWorkspaceDatatankPartCounts,
+				Transform:   transform.FromField("PartCount"),
+			},
+			{
+				Name:        "error_part_count",
+				Description: "The number of parts across the tables in the datatank that have never been refreshed successfully.",
+				Type:        proto.ColumnType_INT,
+				Hydrate:     getWorkspaceDatatankPartCounts,
+				Transform:   transform.FromField("ErrorPartCount"),
+			},
+			{
+				Name:        "created_at",
+				Description: "The time when the datatank was created.",
+				Type:        proto.ColumnType_TIMESTAMP,
+			},
+			{
+				Name:        "created_by_id",
+				Description: "The unique identifier of the user who created the datatank.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromCamel(),
+			},
+			{
+				Name:        "created_by",
+				Description: "Information about the user who created the datatank.",
+				Type:        proto.ColumnType_JSON,
+			},
+			{
+				Name:        "updated_at",
+				Description: "The time when the datatank was last updated.",
+				Type:        proto.ColumnType_TIMESTAMP,
+			},
+			{
+				Name:        "updated_by_id",
+				Description: "The unique identifier of the user who last updated the datatank.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromCamel(),
+			},
+			{
+				Name:        "updated_by",
+				Description: "Information about the user who last updated the datatank.",
+				Type:        proto.ColumnType_JSON,
+			},
+			{
+				Name:        "version_id",
+				Description: "The current version ID of the datatank.",
+				Type:        proto.ColumnType_INT,
+				Transform:   transform.FromCamel(),
+			},
+		}),
+	}
+}
+
+// DatatankPartCounts holds the table and part counts of a datatank, summed
+// from the freshness of each of its tables.
+type DatatankPartCounts struct {
+	TableCount     int
+	PartCount      int
+	ErrorPartCount int
+}
+
+//// LIST FUNCTION
+
+func listWorkspaceDatatanks(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	workspace := parentWorkspace(h.Item)
+	if workspace == nil {
+		plugin.Logger(ctx).Error("listWorkspaceDatatanks", "unknown response type for workspace list parent hydrate call", h.Item)
+		return nil, nil
+	}
+	if !workspaceMatchesQuals(d, workspace) {
+		return nil, nil
+	}
+
+	api, err := getIdentityService(ctx, d, h, workspace.IdentityId)
+	if err != nil {
+		plugin.Logger(ctx).Error("listWorkspaceDatatanks", "getIdentityService", err)
+		return nil, err
+	}
+
+	err = paginate(ctx, d, api.ListWorkspaceDatatanks(workspace.Id))
+	if err != nil {
+		plugin.Logger(ctx).Error("listWorkspaceDatatanks", "list", err)
+		return nil, err
+	}
+	return nil, nil
+}
+
+//// HYDRATE FUNCTIONS
+
+func getWorkspaceDatatank(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	identityId := d.EqualsQuals["identity_id"].GetStringValue()
+	workspaceId := d.EqualsQuals["workspace_id"].GetStringValue()
+	handle := d.EqualsQuals["handle"].GetStringValue()
+
+	// check if identity or workspace or datatank information is missing
+	if identityId == "" || workspaceId == "" || handle == "" {
+		return nil, nil
+	}
+
+	api, err := getIdentityService(ctx, d, h, identityId)
+	if err != nil {
+		plugin.Logger(ctx).Error("getWorkspaceDatatank", "getIdentityService", err)
+		return nil, err
+	}
+
+	getDetails := func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+		return api.GetWorkspaceDatatank(ctx, workspaceId, handle)
+	}
+
+	response, err := plugin.RetryHydrate(ctx, d, h, getDetails, &plugin.RetryConfig{})
+	if err != nil {
+		plugin.Logger(ctx).Error("getWorkspaceDatatank", "get", err)
+		return nil, err
+	}
+
+	return response.(openapi.Datatank), nil
+}
+
+func getWorkspaceDatatankPartCounts(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	datatank := h.Item.(openapi.Datatank)
+
+	api, err := getIdentityService(ctx, d, h, datatank.IdentityId)
+	if err != nil {
+		plugin.Logger(ctx).Error("getWorkspaceDatatankPartCounts", "getIdentityService", err)
+		return nil, err
+	}
+
+	tables, err := listAll(ctx, d, api.ListWorkspaceDatatankTables(datatank.WorkspaceId, datatank.Handle))
+	if err != nil {
+		plugin.Logger(ctx).Error("getWorkspaceDatatankPartCounts", "list", err)
+		return nil, err
+	}
+
+	counts := DatatankPartCounts{TableCount: len(tables)}
+	for _, table := range tables {
+		freshness := table.GetFreshness()
+		counts.PartCount += int(freshness.GetTotalParts())
+		counts.ErrorPartCount += int(freshness.GetError())
+	}
+	return counts, nil
+}
+
+func getIdentityWorkspaceDetailsForWorkspaceDatatank(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	item := h.Item.(openapi.Datatank)
+	details, err := getIdentityWorkspaceDetailsForIds(ctx, d, h, item.GetIdentityId(), item.GetWorkspaceId())
+	if err != nil {
+		plugin.Logger(ctx).Error("getIdentityWorkspaceDetailsForWorkspaceDatatank", "error", err)
+		return nil, err
+	}
+	return details, nil
+}
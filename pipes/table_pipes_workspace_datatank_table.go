@@ -0,0 +1,341 @@
+package pipes
+
+import (
+	"context"
+	"net/http"
+
+	openapi "github.com/turbot/pipes-sdk-go"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+//// TABLE DEFINITION
+
+func tablePipesWorkspaceDatatankTable(_ context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "pipes_workspace_datatank_table",
+		Description: "Datatank tables hold the results of a query, split into parts that are each refreshed on a schedule.",
+		List: &plugin.ListConfig{
+			ParentHydrate: listWorkspaces,
+			Hydrate:       listWorkspaceDatatankTables,
+			KeyColumns: []*plugin.KeyColumn{
+				{
+					Name:    "datatank_handle",
+					Require: plugin.Optional,
+				},
+				{
+					Name:    "identity_handle",
+					Require: plugin.Optional,
+				},
+				{
+					Name:    "identity_id",
+					Require: plugin.Optional,
+				},
+				{
+					Name:    "workspace_handle",
+					Require: plugin.Optional,
+				},
+				{
+					Name:    "workspace_id",
+					Require: plugin.Optional,
+				},
+			},
+		},
+		Get: &plugin.GetConfig{
+			KeyColumns: plugin.AllColumns([]string{"identity_id", "workspace_id", "datatank_handle", "name"}),
+			Hydrate:    getWorkspaceDatatankTable,
+		},
+		Columns: commonColumns([]*plugin.Column{
+			{
+				Name:        "id",
+				Description: "The unique identifier for the datatank table.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromCamel(),
+			},
+			{
+				Name:        "name",
+				Description: "The name of the table in the datatank schema.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "description",
+				Description: "The description of the datatank table.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "type",
+				Description: "The type of the datatank table, e.g. 'table' or 'query'.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "datatank_id",
+				Description: "The unique identifier for the datatank which contains the table.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromCamel(),
+			},
+			{
+				Name:        "datatank_handle",
+				Description: "The handle of the datatank which contains the table.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Datatank.Handle"),
+			},
+			{
+				Name:        "identity_id",
+				Description: "The unique identifier for the identity which contains the workspace.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Datatank.IdentityId"),
+			},
+			{
+				Name:        "identity_handle",
+				Description: "The handle of the identity which contains the workspace.",
+				Type:        proto.ColumnType_STRING,
+				Hydrate:     getIdentityWorkspaceDetailsForWorkspaceDatatankTable,
+			},
+			{
+				Name:        "identity_type",
+				Description: "The type of identity, which can be 'user' or 'org'.",
+				Type:        proto.ColumnType_STRING,
+				Hydrate:     getIdentityWorkspaceDetailsForWorkspaceDatatankTable,
+			},
+			{
+				Name:        "workspace_id",
+				Description: "The unique identifier for the workspace.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Datatank.WorkspaceId"),
+			},
+			{
+				Name:        "workspace_handle",
+				Description: "The handle for the workspace.",
+				Type:        proto.ColumnType_STRING,
+				Hydrate:     getIdentityWorkspaceDetailsForWorkspaceDatatankTable,
+			},
+			{
+				Name:        "source_query",
+				Description: "The query whose results are stored in the table.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromCamel(),
+			},
+			{
+				Name:        "source_schema",
+				Description: "The schema of the source table, for tables that copy another table.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromCamel(),
+			},
+			{
+				Name:        "source_table",
+				Description: "The name of the source table, for tables that copy another table.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromCamel(),
+			},
+			{
+				Name:        "part_per",
+				Description: "The column the table is split into parts by, e.g. 'connection'.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromCamel(),
+			},
+			{
+				Name:        "frequency",
+				Description: "The frequency at which the parts of the table are refreshed.",
+				Type:        proto.ColumnType_JSON,
+			},
+			{
+				Name:        "state",
+				Description: "The actual state of the table. Can be one of 'enabled', 'paused', 'disabled' or 'deleted'.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "desired_state",
+				Description: "The state the table has been asked to move to. Can be one of 'enabled', 'paused' or 'disabled'.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromCamel(),
+			},
+			{
+				Name:        "state_reason",
+				Description: "The reason for the state of the table, e.g. the last error if it failed.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromCamel(),
+			},
+			{
+				Name:        "part_count",
+				Description: "The total number of parts in the table.",
+				Type:        proto.ColumnType_INT,
+				Transform:   transform.FromField("Freshness.TotalParts"),
+			},
+			{
+				Name:        "last_updated_at",
+				Description: "The time when any of the parts of the table was most recently refreshed successfully.",
+				Type:        proto.ColumnType_TIMESTAMP,
+				Transform:   transform.FromField("Freshness.NewestPartUpdatedAt"),
+			},
+			{
+				Name:        "oldest_part_updated_at",
+				Description: "The time when the least recently refreshed part of the table was refreshed successfully.",
+				Type:        proto.ColumnType_TIMESTAMP,
+				Transform:   transform.FromField("Freshness.OldestPartUpdatedAt"),
+			},
+			{
+				Name:        "freshness",
+				Description: "The number of parts of the table in each state, i.e. fresh, stale, expired, error, pending, removing and disabled.",
+				Type:        proto.ColumnType_JSON,
+			},
+			{
+				Name:        "created_at",
+				Description: "The time when the table was created.",
+				Type:        proto.ColumnType_TIMESTAMP,
+			},
+			{
+				Name:        "created_by_id",
+				Description: "The unique identifier of the user who created the table.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromCamel(),
+			},
+			{
+				Name:        "created_by",
+				Description: "Information about the user who created the table.",
+				Type:        proto.ColumnType_JSON,
+			},
+			{
+				Name:        "updated_at",
+				Description: "The time when the table was last updated.",
+				Type:        proto.ColumnType_TIMESTAMP,
+			},
+			{
+				Name:        "updated_by_id",
+				Description: "The unique identifier of the user who last updated the table.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromCamel(),
+			},
+			{
+				Name:        "updated_by",
+				Description: "Information about the user who last updated the table.",
+				Type:        proto.ColumnType_JSON,
+			},
+			{
+				Name:        "version_id",
+				Description: "The current version ID of the table.",
+				Type:        proto.ColumnType_INT,
+				Transform:   transform.FromCamel(),
+			},
+		}),
+	}
+}
+
+//// LIST FUNCTION
+
+func listWorkspaceDatatankTables(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	workspace := parentWorkspace(h.Item)
+	if workspace == nil {
+		plugin.Logger(ctx).Error("listWorkspaceDatatankTables", "unknown response type for workspace list parent hydrate call", h.Item)
+		return nil, nil
+	}
+	if !workspaceMatchesQuals(d, workspace) {
+		return nil, nil
+	}
+
+	api, err := getIdentityService(ctx, d, h, workspace.IdentityId)
+	if err != nil {
+		plugin.Logger(ctx).Error("listWorkspaceDatatankTables", "getIdentityService", err)
+		return nil, err
+	}
+
+	// Tables are listed per datatank, so list the datatanks of the workspace
+	// unless the query is for a single datatank
+	var datatanks []openapi.Datatank
+	if datatankHandle := d.EqualsQualString("datatank_handle"); datatankHandle != "" {
+		datatank, err := api.GetWorkspaceDatatank(ctx, workspace.Id, datatankHandle)
+		if err != nil {
+			if errorStatusCode(err) == http.StatusNotFound {
+				return nil, nil
+			}
+			plugin.Logger(ctx).Error("listWorkspaceDatatankTables", "get_datatank", err)
+			return nil, err
+		}
+		datatanks = append(datatanks, datatank)
+	} else {
+		datatanks, err = listAll(ctx, d, api.ListWorkspaceDatatanks(workspace.Id))
+		if err != nil {
+			plugin.Logger(ctx).Error("listWorkspaceDatatankTables", "list_datatanks", err)
+			return nil, err
+		}
+	}
+
+	for _, datatank := range datatanks {
+		err = paginate(ctx, d, func(ctx context.Context, nextToken *string, limit int32) ([]openapi.DatatankTable, *string, error) {
+			tables, pageToken, err := api.ListWorkspaceDatatankTables(workspace.Id, datatank.Handle)(ctx, nextToken, limit)
+			// The datatank columns are read from the table's datatank, which
+			// the API does not always include
+			for i := range tables {
+				if tables[i].Datatank == nil {
+					tables[i].Datatank = &datatank
+				}
+			}
+			return tables, pageToken, err
+		})
+		if err != nil {
+			plugin.Logger(ctx).Error("listWorkspaceDatatankTables", "list", err)
+			return nil, err
+		}
+
+		// Context can be cancelled due to manual cancellation or the limit has been hit
+		if d.RowsRemaining(ctx) == 0 {
+			return nil, nil
+		}
+	}
+	return nil, nil
+}
+
+//// HYDRATE FUNCTIONS
+
+func getWorkspaceDatatankTable(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	identityId := d.EqualsQuals["identity_id"].GetStringValue()
+	workspaceId := d.EqualsQuals["workspace_id"].GetStringValue()
+	datatankHandle := d.EqualsQuals["datatank_handle"].GetStringValue()
+	name := d.EqualsQuals["name"].GetStringValue()
+
+	// check if identity or workspace or datatank or table information is missing
+	if identityId == "" || workspaceId == "" || datatankHandle == "" || name == "" {
+		return nil, nil
+	}
+
+	api, err := getIdentityService(ctx, d, h, identityId)
+	if err != nil {
+		plugin.Logger(ctx).Error("getWorkspaceDatatankTable", "getIdentityService", err)
+		return nil, err
+	}
+
+	getDetails := func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+		datatank, err := api.GetWorkspaceDatatank(ctx, workspaceId, datatankHandle)
+		if err != nil {
+			return nil, err
+		}
+		table, err := api.GetWorkspaceDatatankTable(ctx, workspaceId, datatankHandle, name)
+		if err != nil {
+			return nil, err
+		}
+		if table.Datatank == nil {
+			table.Datatank = &datatank
+		}
+		return table, nil
+	}
+
+	response, err := plugin.RetryHydrate(ctx, d, h, getDetails, &plugin.RetryConfig{})
+	if err != nil {
+		plugin.Logger(ctx).Error("getWorkspaceDatatankTable", "get", err)
+		return nil, err
+	}
+
+	return response.(openapi.DatatankTable), nil
+}
+
+func getIdentityWorkspaceDetailsForWorkspaceDatatankTable(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	item := h.Item.(openapi.DatatankTable)
+	datatank := item.GetDatatank()
+	details, err := getIdentityWorkspaceDetailsForIds(ctx, d, h, datatank.GetIdentityId(), datatank.GetWorkspaceId())
+	if err != nil {
+		plugin.Logger(ctx).Error("getIdentityWorkspaceDetailsForWorkspaceDatatankTable", "error", err)
+		return nil, err
+	}
+	return details, nil
+}
@@ -16,9 +16,53 @@ func tablePipesWorkspaceDBLog(_ context.Context) *plugin.Table {
 	return &plugin.Table{
 		Name:        "pipes_workspace_db_log",
 		Description: "Database logs records the underlying queries executed when a user executes a query.",
+		HydrateConfig: []plugin.HydrateConfig{
+			{
+				Func:           getIdentityWorkspaceDetailsForWorkspaceDBLog,
+				MaxConcurrency: 5,
+			},
+		},
 		List: &plugin.ListConfig{
-			ParentHydrate: listWorkspaces,
+			ParentHydrate: listQualWorkspaces,
 			Hydrate:       listWorkspaceDBLogs,
+			KeyColumns: []*plugin.KeyColumn{
+				{
+					Name:    "identity_handle",
+					Require: plugin.Optional,
+				},
+				{
+					Name:    "identity_id",
+					Require: plugin.Optional,
+				},
+				{
+					Name:    "workspace_handle",
+					Require: plugin.Optional,
+				},
+				{
+					Name:    "workspace_id",
+					Require: plugin.Optional,
+				},
+				{
+					Name:      "log_timestamp",
+					Require:   plugin.Optional,
+					Operators: []string{"=", ">", ">=", "<", "<="},
+				},
+				{
+					Name:      "created_at",
+					Require:   plugin.Optional,
+					Operators: []string{"=", ">", ">=", "<", "<="},
+				},
+				{
+					Name:      "actor_handle",
+					Require:   plugin.Optional,
+					Operators: []string{"=", "<>"},
+				},
+				{
+					Name:      "duration",
+					Require:   plugin.Optional,
+					Operators: []string{"=", ">", ">=", "<", "<="},
+				},
+			},
 		},
 		Columns: commonColumns([]*plugin.Column{
 			{
@@ -49,6 +93,24 @@ func tablePipesWorkspaceDBLog(_ context.Context) *plugin.Table {
 				Type:        proto.ColumnType_STRING,
 				Transform:   transform.FromCamel(),
 			},
+			{
+				Name:        "identity_id",
+				Description: "The unique identifier for the identity which contains the workspace.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromCamel(),
+			},
+			{
+				Name:        "identity_handle",
+				Description: "The handle of the identity which contains the workspace.",
+				Type:        proto.ColumnType_STRING,
+				Hydrate:     getIdentityWorkspaceDetailsForWorkspaceDBLog,
+			},
+			{
+				Name:        "identity_type",
+				Description: "The type of identity, which can be 'user' or 'org'.",
+				Type:        proto.ColumnType_STRING,
+				Hydrate:     getIdentityWorkspaceDetailsForWorkspaceDBLog,
+			},
 			{
 				Name:        "workspace_id",
 				Description: "The unique identifier of the workspace on which the query was executed.",
@@ -71,6 +133,31 @@ func tablePipesWorkspaceDBLog(_ context.Context) *plugin.Table {
 				Description: "The query that was executed in the workspace.",
 				Type:        proto.ColumnType_STRING,
 			},
+			{
+				Name:        "query_fingerprint",
+				Description: "The query with literals replaced by ?, and comments, whitespace and keyword case normalized, so that runs of the same query with different values can be grouped.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "statement_type",
+				Description: "The type of the query statement, e.g. 'select', 'insert' or 'update'.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "referenced_schemas",
+				Description: "The schemas of the schema qualified tables referenced by the query, e.g. the plugin connections it queried.",
+				Type:        proto.ColumnType_JSON,
+			},
+			{
+				Name:        "referenced_tables",
+				Description: "The tables referenced by the query, schema qualified if they were in the query.",
+				Type:        proto.ColumnType_JSON,
+			},
+			{
+				Name:        "query_source",
+				Description: "Where the query most likely came from, inferred from the query: 'dashboard' for dashboard, benchmark and snapshot runs, 'system' for catalog queries and session statements made by clients, or 'interactive' for everything else.",
+				Type:        proto.ColumnType_STRING,
+			},
 			{
 				Name:        "log_timestamp",
 				Description: "The time when the log got captured in postgres.",
@@ -79,56 +166,56 @@ func tablePipesWorkspaceDBLog(_ context.Context) *plugin.Table {
 			{
 				Name:        "created_at",
 				Description: "The time when the db log record was generated.",
-				Type:        proto.ColumnType_STRING,
+				Type:        proto.ColumnType_TIMESTAMP,
 			},
 		}),
 	}
 }
 
+// WorkspaceDBLog is a db log record of a workspace, along with the metadata
+// parsed from its query. The record has no identity, so it is filled in from
+// the workspace that was queried.
+type WorkspaceDBLog struct {
+	openapi.LogRecord
+	QueryMetadata
+	IdentityId string
+}
+
 //// LIST FUNCTION
 
 func listWorkspaceDBLogs(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
 	// Get the workspace object from the parent hydrate
-	workspace := h.Item.(*openapi.Workspace)
-
-	// Create the connection
-	svc, err := connect(ctx, d)
-	if err != nil {
-		plugin.Logger(ctx).Error("listDBLogs", "connection_error", err)
-		return nil, err
+	workspace := parentWorkspace(h.Item)
+	if workspace == nil {
+		plugin.Logger(ctx).Error("listDBLogs", "unknown response type for workspace list parent hydrate call", h.Item)
+		return nil, nil
+	}
+	if !workspaceMatchesQuals(d, workspace) {
+		return nil, nil
 	}
 
-	// Get cached user identity
-	getUserIdentityCached := plugin.HydrateFunc(getUserIdentity).WithCache()
-	commonData, err := getUserIdentityCached(ctx, d, h)
+	// Route to the user or org API for the workspace owner
+	api, err := getIdentityService(ctx, d, h, workspace.IdentityId)
 	if err != nil {
-		plugin.Logger(ctx).Error("listDBLogs", "getUserIdentityCached", err)
+		plugin.Logger(ctx).Error("listDBLogs", "getIdentityService", err)
 		return nil, err
 	}
 
-	// Extract the user object from the cached identity
-	user := commonData.(openapi.User)
+	// Build the where filter from the quals, with all values escaped
+	filter := buildListFilter(d)
 
-	// If the requested number of items is less than the paging max limit
-	// set the limit to that instead
-	maxResults := int32(100)
-	limit := d.QueryContext.Limit
-	if d.QueryContext.Limit != nil {
-		if *limit < int64(maxResults) {
-			if *limit < 1 {
-				maxResults = int32(1)
-			} else {
-				maxResults = int32(*limit)
+	err = paginate(ctx, d, func(ctx context.Context, nextToken *string, limit int32) ([]WorkspaceDBLog, *string, error) {
+		records, pageToken, err := api.ListWorkspaceDBLogs(workspace.Id, filter)(ctx, nextToken, limit)
+		items := make([]WorkspaceDBLog, 0, len(records))
+		for _, record := range records {
+			item := WorkspaceDBLog{LogRecord: record, IdentityId: workspace.IdentityId}
+			if record.Query != nil {
+				item.QueryMetadata = parseQueryMetadata(*record.Query)
 			}
+			items = append(items, item)
 		}
-	}
-
-	// If we want to get the db logs for the user
-	if user.Id == workspace.IdentityId {
-		err = listUserWorkspaceDBLogs(ctx, d, h, svc, maxResults, user.Id, workspace.Id)
-	} else {
-		err = listOrgWorkspaceDBLogs(ctx, d, h, svc, maxResults, workspace.IdentityId, workspace.Id)
-	}
+		return items, pageToken, err
+	})
 	if err != nil {
 		plugin.Logger(ctx).Error("listDBLogs", "error", err)
 		return nil, err
@@ -137,102 +224,14 @@ func listWorkspaceDBLogs(ctx context.Context, d *plugin.QueryData, h *plugin.Hyd
 	return nil, nil
 }
 
-func listUserWorkspaceDBLogs(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData, svc *openapi.APIClient, maxResults int32, identityId, workspaceId string) error {
-	var err error
-
-	// execute list call
-	pagesLeft := true
-	var resp openapi.ListLogsResponse
-	var listDetails func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error)
-
-	for pagesLeft {
-		if resp.NextToken != nil {
-			listDetails = func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
-				resp, _, err = svc.UserWorkspaces.ListDBLogs(ctx, identityId, workspaceId).NextToken(*resp.NextToken).Limit(maxResults).Execute()
-				return resp, err
-			}
-		} else {
-			listDetails = func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
-				resp, _, err = svc.UserWorkspaces.ListDBLogs(ctx, identityId, workspaceId).Limit(maxResults).Execute()
-				return resp, err
-			}
-		}
-
-		response, err := plugin.RetryHydrate(ctx, d, h, listDetails, &plugin.RetryConfig{})
-
-		if err != nil {
-			plugin.Logger(ctx).Error("listUserDBLogs", "list", err)
-			return err
-		}
-
-		result := response.(openapi.ListLogsResponse)
-
-		if result.HasItems() {
-			for _, log := range *result.Items {
-				d.StreamListItem(ctx, log)
-
-				// Context can be cancelled due to manual cancellation or the limit has been hit
-				if d.RowsRemaining(ctx) == 0 {
-					return nil
-				}
-			}
-		}
-		if resp.NextToken == nil {
-			pagesLeft = false
-		} else {
-			resp.NextToken = result.NextToken
-		}
-	}
-
-	return nil
-}
-
-func listOrgWorkspaceDBLogs(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData, svc *openapi.APIClient, maxResults int32, identityId, workspaceId string) error {
-	var err error
-
-	// execute list call
-	pagesLeft := true
-	var resp openapi.ListLogsResponse
-	var listDetails func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error)
-
-	for pagesLeft {
-		if resp.NextToken != nil {
-			listDetails = func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
-				resp, _, err = svc.OrgWorkspaces.ListDBLogs(ctx, identityId, workspaceId).NextToken(*resp.NextToken).Limit(maxResults).Execute()
-				return resp, err
-			}
-		} else {
-			listDetails = func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
-				resp, _, err = svc.OrgWorkspaces.ListDBLogs(ctx, identityId, workspaceId).Limit(maxResults).Execute()
-				return resp, err
-			}
-		}
-
-		response, err := plugin.RetryHydrate(ctx, d, h, listDetails, &plugin.RetryConfig{})
-
-		if err != nil {
-			plugin.Logger(ctx).Error("listOrgDBLogs", "list", err)
-			return err
-		}
-
-		result := response.(openapi.ListLogsResponse)
-
-		if result.HasItems() {
-			for _, log := range *result.Items {
-				d.StreamListItem(ctx, log)
+//// HYDRATE FUNCTIONS
 
-				// Context can be cancelled due to manual cancellation or the limit has been hit
-				if d.RowsRemaining(ctx) == 0 {
-					return nil
-				}
-			}
-		}
-		if result.NextToken == nil {
-			pagesLeft = false
-		} else {
-			resp.NextToken = result.NextToken
-		}
+func getIdentityWorkspaceDetailsForWorkspaceDBLog(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	item := h.Item.(WorkspaceDBLog)
+	details, err := getIdentityWorkspaceDetailsForIds(ctx, d, h, item.IdentityId, item.WorkspaceId)
+	if err != nil {
+		plugin.Logger(ctx).Error("getIdentityWorkspaceDetailsForWorkspaceDBLog", "error", err)
+		return nil, err
 	}
-
-	return nil
+	return details, nil
 }
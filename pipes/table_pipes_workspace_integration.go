@@ -0,0 +1,246 @@
+package pipes
+
+import (
+	"context"
+
+	openapi "github.com/turbot/pipes-sdk-go"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+//// TABLE DEFINITION
+
+func tablePipesWorkspaceIntegration(_ context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "pipes_workspace_integration",
+		Description: "Workspace integrations are the integrations that pipelines in a workspace can use.",
+		List: &plugin.ListConfig{
+			ParentHydrate: listQualWorkspaces,
+			Hydrate:       listWorkspaceIntegrations,
+			KeyColumns: []*plugin.KeyColumn{
+				{
+					Name:    "identity_handle",
+					Require: plugin.Optional,
+				},
+				{
+					Name:    "identity_id",
+					Require: plugin.Optional,
+				},
+				{
+					Name:    "workspace_handle",
+					Require: plugin.Optional,
+				},
+				{
+					Name:    "workspace_id",
+					Require: plugin.Optional,
+				},
+			},
+		},
+		Get: &plugin.GetConfig{
+			KeyColumns: plugin.AllColumns([]string{"identity_id", "workspace_id", "handle"}),
+			Hydrate:    getWorkspaceIntegration,
+		},
+		Columns: commonColumns([]*plugin.Column{
+			{
+				Name:        "id",
+				Description: "The unique identifier for the integration.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromCamel(),
+			},
+			{
+				Name:        "handle",
+				Description: "The handle for the integration.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "type",
+				Description: "The type of the integration, e.g. 'slack', 'github' or 'email'.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "state",
+				Description: "The state of the integration. Can be one of 'pending', 'enabled', 'disabled' or 'error'.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "state_reason",
+				Description: "The reason for the state of the integration.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromCamel(),
+			},
+			{
+				Name:        "identity_id",
+				Description: "The unique identifier for the identity which contains the workspace.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromCamel(),
+			},
+			{
+				Name:        "identity_handle",
+				Description: "The handle of the identity which contains the workspace.",
+				Type:        proto.ColumnType_STRING,
+				Hydrate:     getIdentityWorkspaceDetailsForWorkspaceIntegration,
+			},
+			{
+				Name:        "identity_type",
+				Description: "The type of identity, which can be 'user' or 'org'.",
+				Type:        proto.ColumnType_STRING,
+				Hydrate:     getIdentityWorkspaceDetailsForWorkspaceIntegration,
+			},
+			{
+				Name:        "workspace_id",
+				Description: "The unique identifier for the workspace.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromCamel(),
+			},
+			{
+				Name:        "workspace_handle",
+				Description: "The handle for the workspace.",
+				Type:        proto.ColumnType_STRING,
+				Hydrate:     getIdentityWorkspaceDetailsForWorkspaceIntegration,
+			},
+			{
+				Name:        "pipeline_id",
+				Description: "The unique identifier of the pipeline the integration belongs to.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromCamel(),
+			},
+			{
+				Name:        "pipeline",
+				Description: "Information about the pipeline the integration belongs to.",
+				Type:        proto.ColumnType_JSON,
+			},
+			{
+				Name:        "github_installation_id",
+				Description: "The GitHub App installation ID, for GitHub integrations.",
+				Type:        proto.ColumnType_INT,
+				Transform:   transform.FromCamel(),
+			},
+			{
+				Name:        "config",
+				Description: "The configuration for the integration.",
+				Type:        proto.ColumnType_JSON,
+			},
+			{
+				Name:        "created_at",
+				Description: "The time when the integration was created.",
+				Type:        proto.ColumnType_TIMESTAMP,
+			},
+			{
+				Name:        "created_by_id",
+				Description: "The unique identifier of the user who created the integration.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromCamel(),
+			},
+			{
+				Name:        "created_by",
+				Description: "Information about the user who created the integration.",
+				Type:        proto.ColumnType_JSON,
+			},
+			{
+				Name:        "updated_at",
+				Description: "The time when the integration was last updated.",
+				Type:        proto.ColumnType_TIMESTAMP,
+			},
+			{
+				Name:        "updated_by_id",
+				Description: "The unique identifier of the user who last updated the integration.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromCamel(),
+			},
+			{
+				Name:        "updated_by",
+				Description: "Information about the user who last updated the integration.",
+				Type:        proto.ColumnType_JSON,
+			},
+			{
+				Name:        "version_id",
+				Description: "The current version ID of the integration.",
+				Type:        proto.ColumnType_INT,
+				Transform:   transform.FromCamel(),
+			},
+		}),
+	}
+}
+
+// WorkspaceIntegration is an integration available in a workspace. The API
+// returns the integration itself, which does not reference the workspace.
+type WorkspaceIntegration struct {
+	openapi.Integration
+	WorkspaceId string
+}
+
+//// LIST FUNCTION
+
+func listWorkspaceIntegrations(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	workspace := parentWorkspace(h.Item)
+	if workspace == nil {
+		plugin.Logger(ctx).Error("listWorkspaceIntegrations", "unknown response type for workspace list parent hydrate call", h.Item)
+		return nil, nil
+	}
+	if !workspaceMatchesQuals(d, workspace) {
+		return nil, nil
+	}
+
+	api, err := getIdentityService(ctx, d, h, workspace.IdentityId)
+	if err != nil {
+		plugin.Logger(ctx).Error("listWorkspaceIntegrations", "getIdentityService", err)
+		return nil, err
+	}
+
+	err = paginate(ctx, d, func(ctx context.Context, nextToken *string, limit int32) ([]WorkspaceIntegration, *string, error) {
+		integrations, pageToken, err := api.ListWorkspaceIntegrations(workspace.Id)(ctx, nextToken, limit)
+		items := make([]WorkspaceIntegration, 0, len(integrations))
+		for _, integration := range integrations {
+			items = append(items, WorkspaceIntegration{Integration: integration, WorkspaceId: workspace.Id})
+		}
+		return items, pageToken, err
+	})
+	if err != nil {
+		plugin.Logger(ctx).Error("listWorkspaceIntegrations", "list", err)
+		return nil, err
+	}
+	return nil, nil
+}
+
+//// HYDRATE FUNCTIONS
+
+func getWorkspaceIntegration(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	identityId := d.EqualsQuals["identity_id"].GetStringValue()
+	workspaceId := d.EqualsQuals["workspace_id"].GetStringValue()
+	handle := d.EqualsQuals["handle"].GetStringValue()
+
+	// check if identity or workspace or integration information is missing
+	if identityId == "" || workspaceId == "" || handle == "" {
+		return nil, nil
+	}
+
+	api, err := getIdentityService(ctx, d, h, identityId)
+	if err != nil {
+		plugin.Logger(ctx).Error("getWorkspaceIntegration", "getIdentityService", err)
+		return nil, err
+	}
+
+	getDetails := func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+		return api.GetWorkspaceIntegration(ctx, workspaceId, handle)
+	}
+
+	response, err := plugin.RetryHydrate(ctx, d, h, getDetails, &plugin.RetryConfig{})
+	if err != nil {
+		plugin.Logger(ctx).Error("getWorkspaceIntegration", "get", err)
+		return nil, err
+	}
+
+	return WorkspaceIntegration{Integration: response.(openapi.Integration), WorkspaceId: workspaceId}, nil
+}
+
+func getIdentityWorkspaceDetailsForWorkspaceIntegration(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	item := h.Item.(WorkspaceIntegration)
+	details, err := getIdentityWorkspaceDetailsForIds(ctx, d, h, item.GetIdentityId(), item.WorkspaceId)
+	if err != nil {
+		plugin.Logger(ctx).Error("getIdentityWorkspaceDetailsForWorkspaceIntegration", "error", err)
+		return nil, err
+	}
+	return details, nil
+}
@@ -0,0 +1,204 @@
+package pipes
+
+import (
+	"context"
+
+	openapi "github.com/turbot/pipes-sdk-go"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+//// TABLE DEFINITION
+
+func tablePipesWorkspaceInvitation(_ context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "pipes_workspace_invitation",
+		Description: "Workspace invitations are the outstanding invitations for users to join an organization workspace.",
+		List: &plugin.ListConfig{
+			ParentHydrate: listWorkspaces,
+			Hydrate:       listWorkspaceInvitations,
+		},
+		Get: &plugin.GetConfig{
+			KeyColumns: plugin.AllColumns([]string{"org_handle", "workspace_handle", "user_handle"}),
+			Hydrate:    getWorkspaceInvitation,
+		},
+		Columns: connectionColumns([]*plugin.Column{
+			{
+				Name:        "id",
+				Description: "The unique identifier for the invitation.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromCamel(),
+			},
+			{
+				Name:        "org_id",
+				Description: "The unique identifier for the organization.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromCamel(),
+			},
+			{
+				Name:        "org_handle",
+				Description: "The handle of the organization.",
+				Type:        proto.ColumnType_STRING,
+				Hydrate:     getIdentityDetailsForWorkspaceInvitation,
+				Transform:   transform.FromField("IdentityHandle"),
+			},
+			{
+				Name:        "workspace_id",
+				Description: "The unique identifier for the workspace.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromCamel(),
+			},
+			{
+				Name:        "workspace_handle",
+				Description: "The handle of the workspace.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "status",
+				Description: "The invitation status, e.g. 'pending'.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "user_id",
+				Description: "The unique identifier for the invited user.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromCamel(),
+			},
+			{
+				Name:        "user_handle",
+				Description: "The handle of the invited user.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "user",
+				Description: "Information about the invited user.",
+				Type:        proto.ColumnType_JSON,
+			},
+			{
+				Name:        "role",
+				Description: "The role the user has been invited to.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "scope",
+				Description: "The scope of the role. Can be one of org / workspace.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "created_at",
+				Description: "The time when the user was invited.",
+				Type:        proto.ColumnType_TIMESTAMP,
+			},
+			{
+				Name:        "created_by_id",
+				Description: "The unique identifier of the user who sent the invitation.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromCamel(),
+			},
+			{
+				Name:        "created_by",
+				Description: "Information about the user who sent the invitation.",
+				Type:        proto.ColumnType_JSON,
+			},
+			{
+				Name:        "updated_at",
+				Description: "The time when the invitation was last updated.",
+				Type:        proto.ColumnType_TIMESTAMP,
+			},
+			{
+				Name:        "updated_by_id",
+				Description: "The unique identifier of the user who last updated the invitation.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromCamel(),
+			},
+			{
+				Name:        "updated_by",
+				Description: "Information about the user who last updated the invitation.",
+				Type:        proto.ColumnType_JSON,
+			},
+			{
+				Name:        "version_id",
+				Description: "The current version ID for the invitation.",
+				Type:        proto.ColumnType_INT,
+				Transform:   transform.FromCamel(),
+			},
+		}),
+	}
+}
+
+//// LIST FUNCTION
+
+func listWorkspaceInvitations(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	workspace := parentWorkspace(h.Item)
+	if workspace == nil {
+		plugin.Logger(ctx).Error("listWorkspaceInvitations", "unknown response type for workspace list parent hydrate call", h.Item)
+		return nil, nil
+	}
+
+	// Users can only be invited to org workspaces
+	identity, err := resolveIdentity(ctx, d, h, workspace.IdentityId)
+	if err != nil {
+		plugin.Logger(ctx).Error("listWorkspaceInvitations", "resolveIdentity", err)
+		return nil, err
+	}
+	if identity.isUser() {
+		return nil, nil
+	}
+
+	// Create Session
+	svc, err := connect(ctx, d)
+	if err != nil {
+		plugin.Logger(ctx).Error("listWorkspaceInvitations", "connection_error", err)
+		return nil, err
+	}
+
+	// Invitations are the members who have not accepted yet
+	err = paginate(ctx, d, func(ctx context.Context, nextToken *string, limit int32) ([]openapi.OrgWorkspaceUser, *string, error) {
+		req := svc.OrgWorkspaceMembers.List(ctx, identity.Handle, workspace.Handle).Limit(limit)
+		if nextToken != nil {
+			req = req.NextToken(*nextToken)
+		}
+		resp, _, err := req.Execute()
+
+		var invitations []openapi.OrgWorkspaceUser
+		for _, member := range resp.GetItems() {
+			if member.Status != memberStatusAccepted {
+				invitations = append(invitations, member)
+			}
+		}
+		return invitations, resp.NextToken, err
+	})
+	if err != nil {
+		plugin.Logger(ctx).Error("listWorkspaceInvitations", "list", err)
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+//// HYDRATE FUNCTIONS
+
+func getWorkspaceInvitation(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	member, err := getOrganizationWorkspaceMember(ctx, d, h)
+	if err != nil {
+		plugin.Logger(ctx).Error("getWorkspaceInvitation", "getOrganizationWorkspaceMember", err)
+		return nil, err
+	}
+	if member == nil || member.(openapi.OrgWorkspaceUser).Status == memberStatusAccepted {
+		return nil, nil
+	}
+	return member, nil
+}
+
+func getIdentityDetailsForWorkspaceInvitation(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	invitation := h.Item.(openapi.OrgWorkspaceUser)
+
+	details, err := getIdentityDetailsForId(ctx, d, h, invitation.OrgId)
+	if err != nil {
+		plugin.Logger(ctx).Error("getIdentityDetailsForWorkspaceInvitation", "error", err)
+		return nil, err
+	}
+	return details, nil
+}
@@ -2,7 +2,6 @@ package pipes
 
 import (
 	"context"
-	"strings"
 
 	openapi "github.com/turbot/pipes-sdk-go"
 
@@ -11,12 +10,6 @@ import (
 	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
 )
 
-type IdentityWorkspaceDetailsForWorkspaceMod struct {
-	IdentityHandle  string `json:"identity_handle"`
-	IdentityType    string `json:"identity_type"`
-	WorkspaceHandle string `json:"workspace_handle"`
-}
-
 //// TABLE DEFINITION
 
 func tablePipesWorkspaceMod(_ context.Context) *plugin.Table {
@@ -146,44 +139,19 @@ func tablePipesWorkspaceMod(_ context.Context) *plugin.Table {
 //// LIST FUNCTION
 
 func listWorkspaceMods(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
-	workspace := h.Item.(*openapi.Workspace)
-
-	// Create Session
-	svc, err := connect(ctx, d)
-	if err != nil {
-		plugin.Logger(ctx).Error("listWorkspaceMods", "connection_error", err)
-		return nil, err
+	workspace := parentWorkspace(h.Item)
+	if workspace == nil {
+		plugin.Logger(ctx).Error("listWorkspaceMods", "unknown response type for workspace list parent hydrate call", h.Item)
+		return nil, nil
 	}
 
-	getUserIdentityCached := plugin.HydrateFunc(getUserIdentity).WithCache()
-	commonData, err := getUserIdentityCached(ctx, d, h)
+	api, err := getIdentityService(ctx, d, h, workspace.IdentityId)
 	if err != nil {
-		plugin.Logger(ctx).Error("listWorkspaceMods", "getUserIdentityCached", err)
+		plugin.Logger(ctx).Error("listWorkspaceMods", "getIdentityService", err)
 		return nil, err
 	}
 
-	user := commonData.(openapi.User)
-
-	// If the requested number of items is less than the paging max limit
-	// set the limit to that instead
-	maxResults := int32(100)
-	limit := d.QueryContext.Limit
-	if d.QueryContext.Limit != nil {
-		if *limit < int64(maxResults) {
-			if *limit < 1 {
-				maxResults = int32(1)
-			} else {
-				maxResults = int32(*limit)
-			}
-		}
-	}
-
-	if workspace.IdentityId == user.Id {
-		err = listUserWorkspaceMods(ctx, d, h, workspace.IdentityId, workspace.Id, svc, maxResults)
-	} else {
-		err = listOrgWorkspaceMods(ctx, d, h, workspace.IdentityId, workspace.Id, svc, maxResults)
-	}
-
+	err = paginate(ctx, d, api.ListWorkspaceMods(workspace.Id))
 	if err != nil {
 		plugin.Logger(ctx).Error("listWorkspaceMods", "list", err)
 		return nil, err
@@ -191,106 +159,6 @@ func listWorkspaceMods(ctx context.Context, d *plugin.QueryData, h *plugin.Hydra
 	return nil, nil
 }
 
-func listUserWorkspaceMods(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData, userHandle string, workspaceHandle string, svc *openapi.APIClient, maxResults int32) error {
-	var err error
-
-	// execute list call
-	pagesLeft := true
-	var resp openapi.ListWorkspaceModsResponse
-	var listDetails func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error)
-
-	for pagesLeft {
-		if resp.NextToken != nil {
-			listDetails = func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
-				resp, _, err = svc.UserWorkspaceMods.List(ctx, userHandle, workspaceHandle).NextToken(*resp.NextToken).Limit(maxResults).Execute()
-				return resp, err
-			}
-		} else {
-			listDetails = func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
-				resp, _, err = svc.UserWorkspaceMods.List(ctx, userHandle, workspaceHandle).Limit(maxResults).Execute()
-				return resp, err
-			}
-		}
-
-		response, err := plugin.RetryHydrate(ctx, d, h, listDetails, &plugin.RetryConfig{})
-
-		if err != nil {
-			plugin.Logger(ctx).Error("listUserWorkspaceMods", "list", err)
-			return err
-		}
-
-		result := response.(openapi.ListWorkspaceModsResponse)
-
-		if result.HasItems() {
-			for _, workspaceMod := range *result.Items {
-				d.StreamListItem(ctx, workspaceMod)
-
-				// Context can be cancelled due to manual cancellation or the limit has been hit
-				if d.RowsRemaining(ctx) == 0 {
-					return nil
-				}
-			}
-		}
-		if result.NextToken == nil {
-			pagesLeft = false
-		} else {
-			resp.NextToken = result.NextToken
-		}
-	}
-
-	return nil
-}
-
-func listOrgWorkspaceMods(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData, orgHandle string, workspaceHandle string, svc *openapi.APIClient, maxResults int32) error {
-	var err error
-
-	// execute list call
-	pagesLeft := true
-	var resp openapi.ListWorkspaceModsResponse
-	var listDetails func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error)
-
-	for pagesLeft {
-		if resp.NextToken != nil {
-			listDetails = func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
-				resp, _, err = svc.OrgWorkspaceMods.List(ctx, orgHandle, workspaceHandle).NextToken(*resp.NextToken).Limit(maxResults).Execute()
-				return resp, err
-			}
-		} else {
-			listDetails = func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
-				resp, _, err = svc.OrgWorkspaceMods.List(ctx, orgHandle, workspaceHandle).Limit(maxResults).Execute()
-				return resp, err
-			}
-		}
-
-		response, err := plugin.RetryHydrate(ctx, d, h, listDetails, &plugin.RetryConfig{})
-
-		if err != nil {
-			plugin.Logger(ctx).Error("listUserWorkspaceMods", "list", err)
-			return err
-		}
-
-		result := response.(openapi.ListWorkspaceModsResponse)
-
-		if result.HasItems() {
-			for _, workspaceMod := range *result.Items {
-				d.StreamListItem(ctx, workspaceMod)
-
-				// Context can be cancelled due to manual cancellation or the limit has been hit
-				if d.RowsRemaining(ctx) == 0 {
-					return nil
-				}
-			}
-		}
-		if result.NextToken == nil {
-			pagesLeft = false
-		} else {
-			resp.NextToken = result.NextToken
-		}
-	}
-
-	return nil
-}
-
 //// GET FUNCTION
 
 func getWorkspaceMod(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
@@ -303,150 +171,31 @@ func getWorkspaceMod(ctx context.Context, d *plugin.QueryData, h *plugin.Hydrate
 		return nil, nil
 	}
 
-	// Create Session
-	svc, err := connect(ctx, d)
-	if err != nil {
-		plugin.Logger(ctx).Error("getWorkspaceMod", "connection_error", err)
-		return nil, err
-	}
-
-	getUserIdentityCached := plugin.HydrateFunc(getUserIdentity).WithCache()
-	commonData, err := getUserIdentityCached(ctx, d, h)
-	if err != nil {
-		plugin.Logger(ctx).Error("getWorkspaceMod", "getUserIdentityCached", err)
-		return nil, err
-	}
-
-	user := commonData.(openapi.User)
-	var resp interface{}
-
-	if identityId == user.Id {
-		resp, err = getUserWorkspaceMod(ctx, d, h, identityId, workspaceId, alias, svc)
-	} else {
-		resp, err = getOrgWorkspaceMod(ctx, d, h, identityId, workspaceId, alias, svc)
-	}
-
-	if err != nil {
-		plugin.Logger(ctx).Error("getWorkspaceMod", "get", err)
-		return nil, err
-	}
-
-	if resp == nil {
-		return nil, nil
-	}
-
-	return resp.(openapi.WorkspaceMod), nil
-}
-
-func getUserWorkspaceMod(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData, identityId string, workspaceId string, alias string, svc *openapi.APIClient) (interface{}, error) {
-	var err error
-
-	// execute get call
-	var resp openapi.WorkspaceMod
-
-	getDetails := func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
-		resp, _, err = svc.UserWorkspaceMods.Get(ctx, identityId, workspaceId, alias).Execute()
-		return resp, err
-	}
-
-	response, err := plugin.RetryHydrate(ctx, d, h, getDetails, &plugin.RetryConfig{})
-
-	workspaceMod := response.(openapi.WorkspaceMod)
-
+	api, err := getIdentityService(ctx, d, h, identityId)
 	if err != nil {
-		plugin.Logger(ctx).Error("getUserWorkspaceMod", "get", err)
+		plugin.Logger(ctx).Error("getWorkspaceMod", "getIdentityService", err)
 		return nil, err
 	}
 
-	return workspaceMod, nil
-}
-
-func getOrgWorkspaceMod(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData, identityId string, workspaceId string, alias string, svc *openapi.APIClient) (interface{}, error) {
-	var err error
-
-	// execute get call
-	var resp openapi.WorkspaceMod
-
 	getDetails := func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
-		resp, _, err = svc.OrgWorkspaceMods.Get(ctx, identityId, workspaceId, alias).Execute()
-		return resp, err
+		return api.GetWorkspaceMod(ctx, workspaceId, alias)
 	}
 
 	response, err := plugin.RetryHydrate(ctx, d, h, getDetails, &plugin.RetryConfig{})
-
-	workspaceMod := response.(openapi.WorkspaceMod)
-
 	if err != nil {
-		plugin.Logger(ctx).Error("getOrgWorkspaceMod", "get", err)
+		plugin.Logger(ctx).Error("getWorkspaceMod", "get", err)
 		return nil, err
 	}
 
-	return workspaceMod, nil
+	return response.(openapi.WorkspaceMod), nil
 }
 
 func getIdentityWorkspaceDetailsForWorkspaceMod(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
-	// Create Session
-	svc, err := connect(ctx, d)
+	item := h.Item.(openapi.WorkspaceMod)
+	details, err := getIdentityWorkspaceDetailsForIds(ctx, d, h, item.GetIdentityId(), item.GetWorkspaceId())
 	if err != nil {
-		plugin.Logger(ctx).Error("getIdentityWorkspaceDetailsForWorkspaceMod", "connection_error", err)
+		plugin.Logger(ctx).Error("getIdentityWorkspaceDetailsForWorkspaceMod", "error", err)
 		return nil, err
 	}
-
-	var identityWorkspaceDetails IdentityWorkspaceDetailsForWorkspaceMod
-	// get workspace details from hydrate data
-	// workspace details reside in the parent item in this case
-	switch w := h.ParentItem.(type) {
-	case openapi.Workspace:
-		identityId := h.ParentItem.(openapi.Workspace).IdentityId
-		identityWorkspaceDetails.WorkspaceHandle = h.ParentItem.(openapi.Workspace).Handle
-		getDetails := func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
-			if strings.HasPrefix(identityId, "u_") {
-				resp, _, err := svc.Users.Get(ctx, identityId).Execute()
-				identityWorkspaceDetails.IdentityType = "user"
-				identityWorkspaceDetails.IdentityHandle = resp.Handle
-				return nil, err
-			} else {
-				resp, _, err := svc.Orgs.Get(ctx, identityId).Execute()
-				identityWorkspaceDetails.IdentityType = "org"
-				identityWorkspaceDetails.IdentityHandle = resp.Handle
-				return nil, err
-			}
-		}
-		_, _ = plugin.RetryHydrate(ctx, d, h, getDetails, &plugin.RetryConfig{})
-		return identityWorkspaceDetails, nil
-	default:
-		plugin.Logger(ctx).Debug("getIdentityWorkspaceDetailsForWorkspaceMod", "Unknown Type", w)
-	}
-
-	identityId := h.Item.(openapi.WorkspaceMod).IdentityId
-	workspaceId := h.Item.(openapi.WorkspaceMod).WorkspaceId
-	getIdentityDetails := func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
-		if strings.HasPrefix(identityId, "u_") {
-			resp, _, err := svc.Users.Get(ctx, identityId).Execute()
-			identityWorkspaceDetails.IdentityType = "user"
-			identityWorkspaceDetails.IdentityHandle = resp.Handle
-			return nil, err
-		} else {
-			resp, _, err := svc.Orgs.Get(ctx, identityId).Execute()
-			identityWorkspaceDetails.IdentityType = "org"
-			identityWorkspaceDetails.IdentityHandle = resp.Handle
-			return nil, err
-		}
-	}
-	_, _ = plugin.RetryHydrate(ctx, d, h, getIdentityDetails, &plugin.RetryConfig{})
-
-	getWorkspaceDetails := func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
-		if strings.HasPrefix(identityId, "u_") {
-			resp, _, err := svc.UserWorkspaces.Get(ctx, identityId, workspaceId).Execute()
-			identityWorkspaceDetails.WorkspaceHandle = resp.Handle
-			return nil, err
-		} else {
-			resp, _, err := svc.OrgWorkspaces.Get(ctx, identityId, workspaceId).Execute()
-			identityWorkspaceDetails.WorkspaceHandle = resp.Handle
-			return nil, err
-		}
-	}
-	_, _ = plugin.RetryHydrate(ctx, d, h, getWorkspaceDetails, &plugin.RetryConfig{})
-
-	return identityWorkspaceDetails, nil
+	return details, nil
 }
@@ -0,0 +1,225 @@
+package pipes
+
+import (
+	"context"
+
+	openapi "github.com/turbot/pipes-sdk-go"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+//// TABLE DEFINITION
+
+func tablePipesWorkspaceNotifier(_ context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "pipes_workspace_notifier",
+		Description: "Workspace notifiers define where notifications from the pipelines in a workspace are sent.",
+		List: &plugin.ListConfig{
+			ParentHydrate: listQualWorkspaces,
+			Hydrate:       listWorkspaceNotifiers,
+			KeyColumns: []*plugin.KeyColumn{
+				{
+					Name:    "identity_handle",
+					Require: plugin.Optional,
+				},
+				{
+					Name:    "identity_id",
+					Require: plugin.Optional,
+				},
+				{
+					Name:    "workspace_handle",
+					Require: plugin.Optional,
+				},
+				{
+					Name:    "workspace_id",
+					Require: plugin.Optional,
+				},
+			},
+		},
+		Get: &plugin.GetConfig{
+			KeyColumns: plugin.AllColumns([]string{"identity_id", "workspace_id", "name"}),
+			Hydrate:    getWorkspaceNotifier,
+		},
+		Columns: commonColumns([]*plugin.Column{
+			{
+				Name:        "id",
+				Description: "The unique identifier for the notifier.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromCamel(),
+			},
+			{
+				Name:        "name",
+				Description: "The name of the notifier, unique up and down the resource hierarchy.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "state",
+				Description: "The state of the notifier. Can be one of 'enabled' or 'disabled'.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "state_reason",
+				Description: "The reason for the state of the notifier.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromCamel(),
+			},
+			{
+				Name:        "precedence",
+				Description: "The precedence of the notifier, e.g. 'default' for the notifier used when a pipeline does not name one.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "notifies",
+				Description: "The integrations and channels or addresses that the notifier sends notifications to.",
+				Type:        proto.ColumnType_JSON,
+			},
+			{
+				Name:        "identity_id",
+				Description: "The unique identifier for the identity which contains the workspace.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromCamel(),
+			},
+			{
+				Name:        "identity_handle",
+				Description: "The handle of the identity which contains the workspace.",
+				Type:        proto.ColumnType_STRING,
+				Hydrate:     getIdentityWorkspaceDetailsForWorkspaceNotifier,
+			},
+			{
+				Name:        "identity_type",
+				Description: "The type of identity, which can be 'user' or 'org'.",
+				Type:        proto.ColumnType_STRING,
+				Hydrate:     getIdentityWorkspaceDetailsForWorkspaceNotifier,
+			},
+			{
+				Name:        "workspace_id",
+				Description: "The unique identifier for the workspace.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromCamel(),
+			},
+			{
+				Name:        "workspace_handle",
+				Description: "The handle for the workspace.",
+				Type:        proto.ColumnType_STRING,
+				Hydrate:     getIdentityWorkspaceDetailsForWorkspaceNotifier,
+			},
+			{
+				Name:        "created_at",
+				Description: "The time when the notifier was created.",
+				Type:        proto.ColumnType_TIMESTAMP,
+			},
+			{
+				Name:        "created_by_id",
+				Description: "The unique identifier of the user who created the notifier.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromCamel(),
+			},
+			{
+				Name:        "created_by",
+				Description: "Information about the user who created the notifier.",
+				Type:        proto.ColumnType_JSON,
+			},
+			{
+				Name:        "updated_at",
+				Description: "The time when the notifier was last updated.",
+				Type:        proto.ColumnType_TIMESTAMP,
+			},
+			{
+				Name:        "updated_by_id",
+				Description: "The unique identifier of the user who last updated the notifier.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromCamel(),
+			},
+			{
+				Name:        "updated_by",
+				Description: "Information about the user who last updated the notifier.",
+				Type:        proto.ColumnType_JSON,
+			},
+			{
+				Name:        "version_id",
+				Description: "The current version ID of the notifier.",
+				Type:        proto.ColumnType_INT,
+				Transform:   transform.FromCamel(),
+			},
+		}),
+	}
+}
+
+//// LIST FUNCTION
+
+func listWorkspaceNotifiers(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	workspace := parentWorkspace(h.Item)
+	if workspace == nil {
+		plugin.Logger(ctx).Error("listWorkspaceNotifiers", "unknown response type for workspace list parent hydrate call", h.Item)
+		return nil, nil
+	}
+	if !workspaceMatchesQuals(d, workspace) {
+		return nil, nil
+	}
+
+	api, err := getIdentityService(ctx, d, h, workspace.IdentityId)
+	if err != nil {
+		plugin.Logger(ctx).Error("listWorkspaceNotifiers", "getIdentityService", err)
+		return nil, err
+	}
+
+	err = paginate(ctx, d, func(ctx context.Context, nextToken *string, limit int32) ([]openapi.Notifier, *string, error) {
+		notifiers, pageToken, err := api.ListWorkspaceNotifiers(workspace.Id)(ctx, nextToken, limit)
+		// Notifiers inherited from the identity have no workspace, so are
+		// attributed to the workspace they were listed for
+		for i := range notifiers {
+			if notifiers[i].WorkspaceId == nil {
+				notifiers[i].WorkspaceId = &workspace.Id
+			}
+		}
+		return notifiers, pageToken, err
+	})
+	if err != nil {
+		plugin.Logger(ctx).Error("listWorkspaceNotifiers", "list", err)
+		return nil, err
+	}
+	return nil, nil
+}
+
+//// HYDRATE FUNCTIONS
+
+func getWorkspaceNotifier(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	identityId := d.EqualsQuals["identity_id"].GetStringValue()
+	workspaceId := d.EqualsQuals["workspace_id"].GetStringValue()
+	name := d.EqualsQuals["name"].GetStringValue()
+
+	// check if identity or workspace or notifier information is missing
+	if identityId == "" || workspaceId == "" || name == "" {
+		return nil, nil
+	}
+
+	api, err := getIdentityService(ctx, d, h, identityId)
+	if err != nil {
+		plugin.Logger(ctx).Error("getWorkspaceNotifier", "getIdentityService", err)
+		return nil, err
+	}
+
+	getDetails := func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+		return api.GetWorkspaceNotifier(ctx, workspaceId, name)
+	}
+
+	response, err := plugin.RetryHydrate(ctx, d, h, getDetails, &plugin.RetryConfig{})
+	if err != nil {
+		plugin.Logger(ctx).Error("getWorkspaceNotifier", "get", err)
+		return nil, err
+	}
+
+	return response.(openapi.Notifier), nil
+}
+
+func getIdentityWorkspaceDetailsForWorkspaceNotifier(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	item := h.Item.(openapi.Notifier)
+	details, err := getIdentityWorkspaceDetailsForIds(ctx, d, h, item.GetIdentityId(), item.GetWorkspaceId())
+	if err != nil {
+		plugin.Logger(ctx).Error("getIdentityWorkspaceDetailsForWorkspaceNotifier", "error", err)
+		return nil, err
+	}
+	return details, nil
+}
@@ -3,8 +3,6 @@ package pipes
 import (
 	"context"
 	"fmt"
-	"strings"
-	"time"
 
 	openapi "github.com/turbot/pipes-sdk-go"
 
@@ -13,12 +11,6 @@ import (
 	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
 )
 
-type IdentityWorkspaceDetailsForPipeline struct {
-	IdentityHandle  string `json:"identity_handle"`
-	IdentityType    string `json:"identity_type"`
-	WorkspaceHandle string `json:"workspace_handle"`
-}
-
 //// TABLE DEFINITION
 
 func tablePipesWorkspacePipeline(_ context.Context) *plugin.Table {
@@ -50,7 +42,7 @@ func tablePipesWorkspacePipeline(_ context.Context) *plugin.Table {
 				{
 					Name:      "pipeline",
 					Require:   plugin.Optional,
-					Operators: []string{"=", "<>"},
+					Operators: []string{"=", "<>", "~~", "!~~", "~~*", "!~~*"},
 				},
 				{
 					Name:       "query_where",
@@ -60,7 +52,7 @@ func tablePipesWorkspacePipeline(_ context.Context) *plugin.Table {
 				{
 					Name:      "title",
 					Require:   plugin.Optional,
-					Operators: []string{"=", "<>"},
+					Operators: []string{"=", "<>", "~~", "!~~", "~~*", "!~~*"},
 				},
 				{
 					Name:      "updated_at",
@@ -205,25 +197,10 @@ func tablePipesWorkspacePipeline(_ context.Context) *plugin.Table {
 //// LIST FUNCTION
 
 func listWorkspacePipelines(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
-	var workspace *openapi.Workspace
-	switch w := h.Item.(type) {
-	case openapi.Workspace:
-		wo := h.Item.(openapi.Workspace)
-		workspace = &wo
-	case *openapi.Workspace:
-		workspace = h.Item.(*openapi.Workspace)
-	default:
-		plugin.Logger(ctx).Error("listWorkspacePipelines", "unknown response type for workspace list parent hydrate call", w)
-	}
-
-	// If the requested number of items is less than the paging max limit
-	// set the limit to that instead
-	maxResults := int32(100)
-	limit := d.QueryContext.Limit
-	if limit != nil {
-		if *limit < int64(maxResults) {
-			maxResults = int32(*limit)
-		}
+	workspace := parentWorkspace(h.Item)
+	if workspace == nil {
+		plugin.Logger(ctx).Error("listWorkspacePipelines", "unknown response type for workspace list parent hydrate call", h.Item)
+		return nil, nil
 	}
 
 	workspaceHandle := d.EqualsQuals["workspace_handle"].GetStringValue()
@@ -248,221 +225,22 @@ func listWorkspacePipelines(ctx context.Context, d *plugin.QueryData, h *plugin.
 		workspaceToPass = workspace.Id
 	}
 
-	var err error
-	if strings.HasPrefix(workspace.IdentityId, "u_") {
-		err = listUserWorkspacePipelines(ctx, d, h, workspace.IdentityId, workspaceToPass, maxResults)
-	} else {
-		err = listOrgWorkspacePipelines(ctx, d, h, workspace.IdentityId, workspaceToPass, maxResults)
-	}
+	// Build the where filter from the quals, with all values escaped
+	filter := buildListFilter(d)
 
+	api, err := getIdentityService(ctx, d, h, workspace.IdentityId)
 	if err != nil {
-		plugin.Logger(ctx).Error("pipes_workspace_pipeline.listWorkspacePipelines", "query_error", err)
+		plugin.Logger(ctx).Error("listWorkspacePipelines", "getIdentityService", err)
 		return nil, err
 	}
 
-	return nil, nil
-}
-
-func listUserWorkspacePipelines(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData, userHandle string, workspaceHandle string, maxResults int32) error {
-	// Create Session
-	svc, err := connect(ctx, d)
-	if err != nil {
-		plugin.Logger(ctx).Error("listUserWorkspacePipelines", "connection_error", err)
-		return err
-	}
-
-	var filter string
-	// collect all clauses passed as quals except for "query_where", "identity_id", "identity_handle", "workspace_id", "workspace_handle"
-	var clauses []string
-	for _, keyQual := range d.Table.List.KeyColumns {
-		filterQual := d.Quals[keyQual.Name]
-		if filterQual == nil || keyQual.Name == "query_where" || keyQual.Name == "identity_id" || keyQual.Name == "identity_handle" || keyQual.Name == "workspace_id" || keyQual.Name == "workspace_handle" {
-			continue
-		}
-		for _, qual := range filterQual.Quals {
-			if qual.Value != nil {
-				var value string
-				if keyQual.Name == "created_at" || keyQual.Name == "updated_at" {
-					t := time.Unix(qual.Value.GetTimestampValue().Seconds, int64(qual.Value.GetTimestampValue().Nanos)).UTC()
-					value = t.Format("2006-01-02 15:04:05.00000")
-				} else {
-					value = qual.Value.GetStringValue()
-				}
-				switch qual.Operator {
-				case "=":
-					clauses = append(clauses, fmt.Sprintf(`%s = '%s'`, keyQual.Name, value))
-				case "<>":
-					clauses = append(clauses, fmt.Sprintf(`%s <> '%s'`, keyQual.Name, value))
-				case ">":
-					clauses = append(clauses, fmt.Sprintf(`%s > '%s'`, keyQual.Name, value))
-				case ">=":
-					clauses = append(clauses, fmt.Sprintf(`%s >= '%s'`, keyQual.Name, value))
-				case "<":
-					clauses = append(clauses, fmt.Sprintf(`%s < '%s'`, keyQual.Name, value))
-				case "<=":
-					clauses = append(clauses, fmt.Sprintf(`%s <= '%s'`, keyQual.Name, value))
-				}
-			}
-		}
-	}
-
-	// Frame the filter string by joining the collected quals by "and"
-	filter = strings.Join(clauses, " and ")
-
-	// Check if a query_where qual has been passed and add it to the filter string if yes
-	if d.EqualsQuals["query_where"] != nil {
-		if len(filter) >= 1 {
-			filter = filter + " and " + d.EqualsQuals["query_where"].GetStringValue()
-		} else {
-			filter = d.EqualsQuals["query_where"].GetStringValue()
-		}
-	}
-
-	pagesLeft := true
-	var resp openapi.ListPipelinesResponse
-	var listDetails func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error)
-
-	for pagesLeft {
-		if resp.NextToken != nil {
-			listDetails = func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
-				resp, _, err = svc.UserWorkspacePipelines.List(ctx, userHandle, workspaceHandle).Where(filter).NextToken(*resp.NextToken).Limit(maxResults).Execute()
-				return resp, err
-			}
-		} else {
-			listDetails = func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
-				resp, _, err = svc.UserWorkspacePipelines.List(ctx, userHandle, workspaceHandle).Where(filter).Limit(maxResults).Execute()
-				return resp, err
-			}
-		}
-
-		response, err := plugin.RetryHydrate(ctx, d, h, listDetails, &plugin.RetryConfig{})
-
-		if err != nil {
-			plugin.Logger(ctx).Error("pipes_workspace_pipeline.listUserWorkspacePipelines", "query_error", err)
-			return err
-		}
-
-		result := response.(openapi.ListPipelinesResponse)
-
-		if result.HasItems() {
-			for _, pipeline := range *result.Items {
-				d.StreamListItem(ctx, pipeline)
-
-				// Context can be cancelled due to manual cancellation or the limit has been hit
-				if d.RowsRemaining(ctx) == 0 {
-					return nil
-				}
-			}
-		}
-		if result.NextToken == nil {
-			pagesLeft = false
-		} else {
-			resp.NextToken = result.NextToken
-		}
-	}
-
-	return nil
-}
-
-func listOrgWorkspacePipelines(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData, orgHandle string, workspaceHandle string, maxResults int32) error {
-	// Create Session
-	svc, err := connect(ctx, d)
+	err = paginate(ctx, d, api.ListWorkspacePipelines(workspaceToPass, filter))
 	if err != nil {
-		plugin.Logger(ctx).Error("pipes_workspace_pipeline.listOrgWorkspacePipelines", "connection_error", err)
-		return err
-	}
-
-	var filter string
-	// collect all clauses passed as quals except for "query_where", "identity_id", "identity_handle", "workspace_id", "workspace_handle"
-	var clauses []string
-	for _, keyQual := range d.Table.List.KeyColumns {
-		filterQual := d.Quals[keyQual.Name]
-		if filterQual == nil || keyQual.Name == "query_where" || keyQual.Name == "identity_id" || keyQual.Name == "identity_handle" || keyQual.Name == "workspace_id" || keyQual.Name == "workspace_handle" {
-			continue
-		}
-		for _, qual := range filterQual.Quals {
-			if qual.Value != nil {
-				var value string
-				if keyQual.Name == "created_at" || keyQual.Name == "updated_at" {
-					t := time.Unix(qual.Value.GetTimestampValue().Seconds, int64(qual.Value.GetTimestampValue().Nanos)).UTC()
-					value = t.Format("2006-01-02 15:04:05.00000")
-				} else {
-					value = qual.Value.GetStringValue()
-				}
-				switch qual.Operator {
-				case "=":
-					clauses = append(clauses, fmt.Sprintf(`%s = '%s'`, keyQual.Name, value))
-				case "<>":
-					clauses = append(clauses, fmt.Sprintf(`%s <> '%s'`, keyQual.Name, value))
-				case ">":
-					clauses = append(clauses, fmt.Sprintf(`%s > '%s'`, keyQual.Name, value))
-				case ">=":
-					clauses = append(clauses, fmt.Sprintf(`%s >= '%s'`, keyQual.Name, value))
-				case "<":
-					clauses = append(clauses, fmt.Sprintf(`%s < '%s'`, keyQual.Name, value))
-				case "<=":
-					clauses = append(clauses, fmt.Sprintf(`%s <= '%s'`, keyQual.Name, value))
-				}
-			}
-		}
-	}
-
-	// Frame the filter string by joining the collected quals by "and"
-	filter = strings.Join(clauses, " and ")
-
-	// Check if a query_where qual has been passed and add it to the filter string if yes
-	if d.EqualsQuals["query_where"] != nil {
-		if len(filter) >= 1 {
-			filter = filter + " and " + d.EqualsQuals["query_where"].GetStringValue()
-		} else {
-			filter = d.EqualsQuals["query_where"].GetStringValue()
-		}
-	}
-
-	pagesLeft := true
-	var resp openapi.ListPipelinesResponse
-	var listDetails func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error)
-
-	for pagesLeft {
-		if resp.NextToken != nil {
-			listDetails = func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
-				resp, _, err = svc.OrgWorkspacePipelines.List(ctx, orgHandle, workspaceHandle).Where(filter).NextToken(*resp.NextToken).Limit(maxResults).Execute()
-				return resp, err
-			}
-		} else {
-			listDetails = func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
-				resp, _, err = svc.OrgWorkspacePipelines.List(ctx, orgHandle, workspaceHandle).Where(filter).Limit(maxResults).Execute()
-				return resp, err
-			}
-		}
-
-		response, err := plugin.RetryHydrate(ctx, d, h, listDetails, &plugin.RetryConfig{})
-
-		if err != nil {
-			plugin.Logger(ctx).Error("pipes_workspace_pipeline.listOrgWorkspacePipelines", "query_error", err)
-			return err
-		}
-
-		result := response.(openapi.ListPipelinesResponse)
-
-		if result.HasItems() {
-			for _, pipeline := range *result.Items {
-				d.StreamListItem(ctx, pipeline)
-
-				// Context can be cancelled due to manual cancellation or the limit has been hit
-				if d.RowsRemaining(ctx) == 0 {
-					return nil
-				}
-			}
-		}
-		if result.NextToken == nil {
-			pagesLeft = false
-		} else {
-			resp.NextToken = result.NextToken
-		}
+		plugin.Logger(ctx).Error("pipes_workspace_pipeline.listWorkspacePipelines", "query_error", err)
+		return nil, err
 	}
 
-	return nil
+	return nil, nil
 }
 
 func getWorkspacePipeline(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
@@ -475,163 +253,31 @@ func getWorkspacePipeline(ctx context.Context, d *plugin.QueryData, h *plugin.Hy
 		return nil, nil
 	}
 
-	getUserIdentityCached := plugin.HydrateFunc(getUserIdentity).WithCache()
-	commonData, err := getUserIdentityCached(ctx, d, h)
+	api, err := getIdentityService(ctx, d, h, identityHandle)
 	if err != nil {
-		plugin.Logger(ctx).Error("getWorkspacePipeline", "getUserIdentityCached", err)
+		plugin.Logger(ctx).Error("getWorkspacePipeline", "getIdentityService", err)
 		return nil, err
 	}
 
-	user := commonData.(openapi.User)
-	var response interface{}
-	if identityHandle == user.Handle {
-		response, err = getUserWorkspacePipeline(ctx, d, h, identityHandle, workspaceHandle, pipelineId)
-	} else {
-		response, err = getOrgWorkspacePipeline(ctx, d, h, identityHandle, workspaceHandle, pipelineId)
-	}
-
-	if err != nil {
-		plugin.Logger(ctx).Error("getWorkspacePipeline", "error", err)
-		return nil, err
-	}
-
-	return response.(openapi.Pipeline), nil
-}
-
-func getUserWorkspacePipeline(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData, userHandle, workspaceHandle, pipelineId string) (interface{}, error) {
-	// Create Session
-	svc, err := connect(ctx, d)
-	if err != nil {
-		plugin.Logger(ctx).Error("getUserWorkspacePipeline", "connection_error", err)
-		return nil, err
-	}
-
-	var pipeline openapi.Pipeline
-
-	getDetails := func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
-		pipeline, _, err = svc.UserWorkspacePipelines.Get(ctx, userHandle, workspaceHandle, pipelineId).Execute()
-		return pipeline, err
-	}
-
-	response, err := plugin.RetryHydrate(ctx, d, h, getDetails, &plugin.RetryConfig{})
-	if err != nil {
-		plugin.Logger(ctx).Error("getUserWorkspacePipeline", "get", err)
-		return nil, err
-	}
-
-	return response, nil
-}
-
-func getOrgWorkspacePipeline(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData, orgHandle, workspaceHandle, pipelineId string) (interface{}, error) {
-	// Create Session
-	svc, err := connect(ctx, d)
-	if err != nil {
-		plugin.Logger(ctx).Error("getOrgWorkspacePipeline", "connection_error", err)
-		return nil, err
-	}
-
-	var pipeline openapi.Pipeline
-
 	getDetails := func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
-		pipeline, _, err = svc.OrgWorkspacePipelines.Get(ctx, orgHandle, workspaceHandle, pipelineId).Execute()
-		return pipeline, err
+		return api.GetWorkspacePipeline(ctx, workspaceHandle, pipelineId)
 	}
 
 	response, err := plugin.RetryHydrate(ctx, d, h, getDetails, &plugin.RetryConfig{})
 	if err != nil {
-		plugin.Logger(ctx).Error("getOrgWorkspacePipeline", "get", err)
+		plugin.Logger(ctx).Error("getWorkspacePipeline", "error", err)
 		return nil, err
 	}
 
-	return response, nil
+	return response.(openapi.Pipeline), nil
 }
 
 func getIdentityWorkspaceDetailsForPipeline(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
-	// Create Session
-	svc, err := connect(ctx, d)
+	item := h.Item.(openapi.Pipeline)
+	details, err := getIdentityWorkspaceDetailsForIds(ctx, d, h, item.GetIdentityId(), item.GetWorkspaceId())
 	if err != nil {
-		plugin.Logger(ctx).Error("getIdentityWorkspaceDetailsForPipeline", "connection_error", err)
+		plugin.Logger(ctx).Error("getIdentityWorkspaceDetailsForPipeline", "error", err)
 		return nil, err
 	}
-
-	var identityWorkspaceDetails IdentityWorkspaceDetailsForPipeline
-	// get workspace details from hydrate data
-	// workspace details reside in the parent item in this case
-	switch w := h.ParentItem.(type) {
-	case openapi.Workspace:
-		plugin.Logger(ctx).Debug("getIdentityWorkspaceDetailsForPipeline", "openapi.Workspace")
-		identityId := h.ParentItem.(openapi.Workspace).IdentityId
-		identityWorkspaceDetails.WorkspaceHandle = h.ParentItem.(openapi.Workspace).Handle
-		getDetails := func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
-			if strings.HasPrefix(identityId, "u_") {
-				resp, _, err := svc.Users.Get(ctx, identityId).Execute()
-				identityWorkspaceDetails.IdentityType = "user"
-				identityWorkspaceDetails.IdentityHandle = resp.Handle
-				return nil, err
-			} else {
-				resp, _, err := svc.Orgs.Get(ctx, identityId).Execute()
-				identityWorkspaceDetails.IdentityType = "org"
-				identityWorkspaceDetails.IdentityHandle = resp.Handle
-				return nil, err
-			}
-		}
-		_, _ = plugin.RetryHydrate(ctx, d, h, getDetails, &plugin.RetryConfig{})
-		return identityWorkspaceDetails, nil
-	case *openapi.Workspace:
-		plugin.Logger(ctx).Debug("getIdentityWorkspaceDetailsForPipeline", "*openapi.Workspace")
-		identityId := h.ParentItem.(*openapi.Workspace).IdentityId
-		identityWorkspaceDetails.WorkspaceHandle = h.ParentItem.(*openapi.Workspace).Handle
-		getDetails := func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
-			if strings.HasPrefix(identityId, "u_") {
-				resp, _, err := svc.Users.Get(ctx, identityId).Execute()
-				identityWorkspaceDetails.IdentityType = "user"
-				identityWorkspaceDetails.IdentityHandle = resp.Handle
-				return nil, err
-			} else {
-				resp, _, err := svc.Orgs.Get(ctx, identityId).Execute()
-				identityWorkspaceDetails.IdentityType = "org"
-				identityWorkspaceDetails.IdentityHandle = resp.Handle
-				return nil, err
-			}
-		}
-		_, _ = plugin.RetryHydrate(ctx, d, h, getDetails, &plugin.RetryConfig{})
-		plugin.Logger(ctx).Debug("getIdentityWorkspaceDetailsForPipeline", "identityWorkspaceDetails", identityWorkspaceDetails)
-		return &identityWorkspaceDetails, nil
-	default:
-		plugin.Logger(ctx).Debug("getIdentityWorkspaceDetailsForPipeline", "Unknown Type", w)
-		// Since the parent id is of unknown type we can assume that its a get operation.
-		identityId := h.Item.(openapi.Pipeline).IdentityId
-		workspaceId := h.Item.(openapi.Pipeline).WorkspaceId
-		getDetails := func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
-			if strings.HasPrefix(*identityId, "u_") {
-				user, _, err := svc.Users.Get(ctx, *identityId).Execute()
-				if err != nil {
-					return nil, err
-				}
-				identityWorkspaceDetails.IdentityType = "user"
-				identityWorkspaceDetails.IdentityHandle = user.Handle
-				workspace, _, err := svc.UserWorkspaces.Get(ctx, *identityId, *workspaceId).Execute()
-				if err != nil {
-					return nil, err
-				}
-				identityWorkspaceDetails.WorkspaceHandle = workspace.Handle
-			} else {
-				org, _, err := svc.Orgs.Get(ctx, *identityId).Execute()
-				if err != nil {
-					return nil, err
-				}
-				identityWorkspaceDetails.IdentityType = "org"
-				identityWorkspaceDetails.IdentityHandle = org.Handle
-				workspace, _, err := svc.UserWorkspaces.Get(ctx, *identityId, *workspaceId).Execute()
-				if err != nil {
-					return nil, err
-				}
-				identityWorkspaceDetails.WorkspaceHandle = workspace.Handle
-			}
-			return nil, nil
-		}
-		_, _ = plugin.RetryHydrate(ctx, d, h, getDetails, &plugin.RetryConfig{})
-		plugin.Logger(ctx).Debug("getIdentityWorkspaceDetailsForAggregator", "identityWorkspaceDetails", identityWorkspaceDetails)
-		return &identityWorkspaceDetails, err
-	}
+	return details, nil
 }
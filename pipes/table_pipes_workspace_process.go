@@ -3,8 +3,6 @@ package pipes
 import (
 	"context"
 	"fmt"
-	"strings"
-	"time"
 
 	openapi "github.com/turbot/pipes-sdk-go"
 
@@ -13,24 +11,6 @@ import (
 	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
 )
 
-type IdentityWorkspaceDetailsForProcess struct {
-	IdentityHandle  string `json:"identity_handle"`
-	IdentityType    string `json:"identity_type"`
-	WorkspaceHandle string `json:"workspace_handle"`
-}
-
-// Create a cached version of the identity details function
-var getIdentityWorkspaceDetailsForWorkspaceProcessCached = plugin.HydrateFunc(getIdentityWorkspaceDetailsForWorkspaceProcess).WithCache()
-
-// Create a wrapper for column hydration
-func getIdentityWorkspaceDetailsColumn(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
-	data, err := getIdentityWorkspaceDetailsForWorkspaceProcessCached(ctx, d, h)
-	if err != nil {
-		return nil, err
-	}
-	return data, nil
-}
-
 //// TABLE DEFINITION
 
 func tablePipesWorkspaceProcess(_ context.Context) *plugin.Table {
@@ -125,13 +105,13 @@ func tablePipesWorkspaceProcess(_ context.Context) *plugin.Table {
 				Name:        "identity_handle",
 				Description: "The handle of the identity.",
 				Type:        proto.ColumnType_STRING,
-				Hydrate:     getIdentityWorkspaceDetailsColumn,
+				Hydrate:     getIdentityWorkspaceDetailsForWorkspaceProcess,
 			},
 			{
 				Name:        "identity_type",
 				Description: "The type of identity, can be org/user.",
 				Type:        proto.ColumnType_STRING,
-				Hydrate:     getIdentityWorkspaceDetailsColumn,
+				Hydrate:     getIdentityWorkspaceDetailsForWorkspaceProcess,
 			},
 			{
 				Name:        "workspace_id",
@@ -143,7 +123,7 @@ func tablePipesWorkspaceProcess(_ context.Context) *plugin.Table {
 				Name:        "workspace_handle",
 				Description: "The handle of the workspace.",
 				Type:        proto.ColumnType_STRING,
-				Hydrate:     getIdentityWorkspaceDetailsColumn,
+				Hydrate:     getIdentityWorkspaceDetailsForWorkspaceProcess,
 			},
 			{
 				Name:        "pipeline_id",
@@ -212,25 +192,10 @@ func tablePipesWorkspaceProcess(_ context.Context) *plugin.Table {
 //// LIST FUNCTION
 
 func listWorkspaceProcesses(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
-	var workspace *openapi.Workspace
-	switch w := h.Item.(type) {
-	case openapi.Workspace:
-		wo := h.Item.(openapi.Workspace)
-		workspace = &wo
-	case *openapi.Workspace:
-		workspace = h.Item.(*openapi.Workspace)
-	default:
-		plugin.Logger(ctx).Error("listWorkspaceProcesses", "unknown response type for workspace list parent hydrate call", w)
-	}
-
-	// If the requested number of items is less than the paging max limit
-	// set the limit to that instead
-	maxResults := int32(100)
-	limit := d.QueryContext.Limit
-	if limit != nil {
-		if *limit < int64(maxResults) {
-			maxResults = int32(*limit)
-		}
+	workspace := parentWorkspace(h.Item)
+	if workspace == nil {
+		plugin.Logger(ctx).Error("listWorkspaceProcesses", "unknown response type for workspace list parent hydrate call", h.Item)
+		return nil, nil
 	}
 
 	workspaceHandle := d.EqualsQuals["workspace_handle"].GetStringValue()
@@ -256,221 +221,22 @@ func listWorkspaceProcesses(ctx context.Context, d *plugin.QueryData, h *plugin.
 		workspaceToPass = workspace.Id
 	}
 
-	var err error
-	if strings.HasPrefix(workspace.IdentityId, "u_") {
-		err = listUserWorkspaceProcesses(ctx, d, h, workspace.IdentityId, workspaceToPass, maxResults)
-	} else {
-		err = listOrgWorkspaceProcesses(ctx, d, h, workspace.IdentityId, workspaceToPass, maxResults)
-	}
+	// Build the where filter from the quals, with all values escaped
+	filter := buildListFilter(d)
 
+	api, err := getIdentityService(ctx, d, h, workspace.IdentityId)
 	if err != nil {
-		plugin.Logger(ctx).Error("listWorkspaceProcesses", "error", err)
+		plugin.Logger(ctx).Error("listWorkspaceProcesses", "getIdentityService", err)
 		return nil, err
 	}
 
-	return nil, nil
-}
-
-func listUserWorkspaceProcesses(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData, userHandle, workspaceHandle string, maxResults int32) error {
-	// Create Session
-	svc, err := connect(ctx, d)
+	err = paginate(ctx, d, api.ListWorkspaceProcesses(workspaceToPass, filter))
 	if err != nil {
-		plugin.Logger(ctx).Error("listUserWorkspaceProcesses", "connection_error", err)
-		return err
-	}
-
-	var filter string
-	// collect all clauses passed as quals except for "query_where", "identity_id", "identity_handle", "workspace_id", "workspace_handle"
-	var clauses []string
-	for _, keyQual := range d.Table.List.KeyColumns {
-		filterQual := d.Quals[keyQual.Name]
-		if filterQual == nil || keyQual.Name == "query_where" || keyQual.Name == "identity_id" || keyQual.Name == "identity_handle" || keyQual.Name == "workspace_id" || keyQual.Name == "workspace_handle" {
-			continue
-		}
-		for _, qual := range filterQual.Quals {
-			if qual.Value != nil {
-				var value string
-				if keyQual.Name == "created_at" || keyQual.Name == "updated_at" {
-					t := time.Unix(qual.Value.GetTimestampValue().Seconds, int64(qual.Value.GetTimestampValue().Nanos)).UTC()
-					value = t.Format("2006-01-02 15:04:05.00000")
-				} else {
-					value = qual.Value.GetStringValue()
-				}
-				switch qual.Operator {
-				case "=":
-					clauses = append(clauses, fmt.Sprintf(`%s = '%s'`, keyQual.Name, value))
-				case "<>":
-					clauses = append(clauses, fmt.Sprintf(`%s <> '%s'`, keyQual.Name, value))
-				case ">":
-					clauses = append(clauses, fmt.Sprintf(`%s > '%s'`, keyQual.Name, value))
-				case ">=":
-					clauses = append(clauses, fmt.Sprintf(`%s >= '%s'`, keyQual.Name, value))
-				case "<":
-					clauses = append(clauses, fmt.Sprintf(`%s < '%s'`, keyQual.Name, value))
-				case "<=":
-					clauses = append(clauses, fmt.Sprintf(`%s <= '%s'`, keyQual.Name, value))
-				}
-			}
-		}
-	}
-
-	// Frame the filter string by joining the collected quals by "and"
-	filter = strings.Join(clauses, " and ")
-
-	// Check if a query_where qual has been passed and add it to the filter string if yes
-	if d.EqualsQuals["query_where"] != nil {
-		if len(filter) >= 1 {
-			filter = filter + " and " + d.EqualsQuals["query_where"].GetStringValue()
-		} else {
-			filter = d.EqualsQuals["query_where"].GetStringValue()
-		}
-	}
-
-	pagesLeft := true
-	var resp openapi.ListProcessesResponse
-	var listDetails func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error)
-
-	for pagesLeft {
-		if resp.NextToken != nil {
-			listDetails = func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
-				resp, _, err = svc.UserWorkspaceProcesses.List(ctx, userHandle, workspaceHandle).Where(filter).NextToken(*resp.NextToken).Limit(maxResults).Execute()
-				return resp, err
-			}
-		} else {
-			listDetails = func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
-				resp, _, err = svc.UserWorkspaceProcesses.List(ctx, userHandle, workspaceHandle).Where(filter).Limit(maxResults).Execute()
-				return resp, err
-			}
-		}
-
-		response, err := plugin.RetryHydrate(ctx, d, h, listDetails, &plugin.RetryConfig{})
-
-		if err != nil {
-			plugin.Logger(ctx).Error("listUserWorkspaceProcesses", "list", err)
-			return err
-		}
-
-		result := response.(openapi.ListProcessesResponse)
-
-		if result.HasItems() {
-			for _, process := range *result.Items {
-				d.StreamListItem(ctx, process)
-
-				// Context can be cancelled due to manual cancellation or the limit has been hit
-				if d.RowsRemaining(ctx) == 0 {
-					return nil
-				}
-			}
-		}
-		if result.NextToken == nil {
-			pagesLeft = false
-		} else {
-			resp.NextToken = result.NextToken
-		}
-	}
-
-	return nil
-}
-
-func listOrgWorkspaceProcesses(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData, orgHandle, workspaceHandle string, maxResults int32) error {
-	// Create Session
-	svc, err := connect(ctx, d)
-	if err != nil {
-		plugin.Logger(ctx).Error("listOrgWorkspaceProcesses", "connection_error", err)
-		return err
-	}
-
-	var filter string
-	// collect all clauses passed as quals except for "query_where", "identity_id", "identity_handle", "workspace_id", "workspace_handle"
-	var clauses []string
-	for _, keyQual := range d.Table.List.KeyColumns {
-		filterQual := d.Quals[keyQual.Name]
-		if filterQual == nil || keyQual.Name == "query_where" || keyQual.Name == "identity_id" || keyQual.Name == "identity_handle" || keyQual.Name == "workspace_id" || keyQual.Name == "workspace_handle" {
-			continue
-		}
-		for _, qual := range filterQual.Quals {
-			if qual.Value != nil {
-				var value string
-				if keyQual.Name == "created_at" || keyQual.Name == "updated_at" {
-					t := time.Unix(qual.Value.GetTimestampValue().Seconds, int64(qual.Value.GetTimestampValue().Nanos)).UTC()
-					value = t.Format("2006-01-02 15:04:05.00000")
-				} else {
-					value = qual.Value.GetStringValue()
-				}
-				switch qual.Operator {
-				case "=":
-					clauses = append(clauses, fmt.Sprintf(`%s = '%s'`, keyQual.Name, value))
-				case "<>":
-					clauses = append(clauses, fmt.Sprintf(`%s <> '%s'`, keyQual.Name, value))
-				case ">":
-					clauses = append(clauses, fmt.Sprintf(`%s > '%s'`, keyQual.Name, value))
-				case ">=":
-					clauses = append(clauses, fmt.Sprintf(`%s >= '%s'`, keyQual.Name, value))
-				case "<":
-					clauses = append(clauses, fmt.Sprintf(`%s < '%s'`, keyQual.Name, value))
-				case "<=":
-					clauses = append(clauses, fmt.Sprintf(`%s <= '%s'`, keyQual.Name, value))
-				}
-			}
-		}
-	}
-
-	// Frame the filter string by joining the collected quals by "and"
-	filter = strings.Join(clauses, " and ")
-
-	// Check if a query_where qual has been passed and add it to the filter string if yes
-	if d.EqualsQuals["query_where"] != nil {
-		if len(filter) >= 1 {
-			filter = filter + " and " + d.EqualsQuals["query_where"].GetStringValue()
-		} else {
-			filter = d.EqualsQuals["query_where"].GetStringValue()
-		}
-	}
-
-	pagesLeft := true
-	var resp openapi.ListProcessesResponse
-	var listDetails func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error)
-
-	for pagesLeft {
-		if resp.NextToken != nil {
-			listDetails = func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
-				resp, _, err = svc.OrgWorkspaceProcesses.List(ctx, orgHandle, workspaceHandle).Where(filter).NextToken(*resp.NextToken).Limit(maxResults).Execute()
-				return resp, err
-			}
-		} else {
-			listDetails = func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
-				resp, _, err = svc.OrgWorkspaceProcesses.List(ctx, orgHandle, workspaceHandle).Where(filter).Limit(maxResults).Execute()
-				return resp, err
-			}
-		}
-
-		response, err := plugin.RetryHydrate(ctx, d, h, listDetails, &plugin.RetryConfig{})
-
-		if err != nil {
-			plugin.Logger(ctx).Error("listOrgWorkspaceProcesses", "list", err)
-			return err
-		}
-
-		result := response.(openapi.ListProcessesResponse)
-
-		if result.HasItems() {
-			for _, process := range *result.Items {
-				d.StreamListItem(ctx, process)
-
-				// Context can be cancelled due to manual cancellation or the limit has been hit
-				if d.RowsRemaining(ctx) == 0 {
-					return nil
-				}
-			}
-		}
-		if result.NextToken == nil {
-			pagesLeft = false
-		} else {
-			resp.NextToken = result.NextToken
-		}
+		plugin.Logger(ctx).Error("listWorkspaceProcesses", "error", err)
+		return nil, err
 	}
 
-	return nil
+	return nil, nil
 }
 
 func getWorkspaceProcess(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
@@ -478,135 +244,36 @@ func getWorkspaceProcess(ctx context.Context, d *plugin.QueryData, h *plugin.Hyd
 	workspaceHandle := d.EqualsQuals["workspace_handle"].GetStringValue()
 	processId := d.EqualsQuals["id"].GetStringValue()
 
-	// check if identityHandle or workspaceHandle or pipeline id is empty
+	// check if identityHandle or workspaceHandle or process id is empty
 	if identityHandle == "" || workspaceHandle == "" || processId == "" {
 		return nil, nil
 	}
 
-	getUserIdentityCached := plugin.HydrateFunc(getUserIdentity).WithCache()
-	commonData, err := getUserIdentityCached(ctx, d, h)
-	if err != nil {
-		plugin.Logger(ctx).Error("getWorkspaceProcess", "getUserIdentityCached", err)
-		return nil, err
-	}
-
-	user := commonData.(openapi.User)
-	var response interface{}
-	if identityHandle == user.Handle {
-		response, err = getUserWorkspaceProcess(ctx, d, h, identityHandle, workspaceHandle, processId)
-	} else {
-		response, err = getOrgWorkspaceProcess(ctx, d, h, identityHandle, workspaceHandle, processId)
-	}
-
-	if err != nil {
-		plugin.Logger(ctx).Error("getWorkspaceProcess", "error", err)
-		return nil, err
-	}
-
-	return response.(openapi.SpProcess), nil
-}
-
-func getUserWorkspaceProcess(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData, userHandle, workspaceHandle, processId string) (interface{}, error) {
-	// Create Session
-	svc, err := connect(ctx, d)
+	api, err := getIdentityService(ctx, d, h, identityHandle)
 	if err != nil {
-		plugin.Logger(ctx).Error("getUserWorkspaceProcess", "connection_error", err)
+		plugin.Logger(ctx).Error("getWorkspaceProcess", "getIdentityService", err)
 		return nil, err
 	}
 
-	var process openapi.SpProcess
-
 	getDetails := func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
-		process, _, err = svc.UserWorkspaceProcesses.Get(ctx, userHandle, workspaceHandle, processId).Execute()
-		return process, err
+		return api.GetWorkspaceProcess(ctx, workspaceHandle, processId)
 	}
 
 	response, err := plugin.RetryHydrate(ctx, d, h, getDetails, &plugin.RetryConfig{})
 	if err != nil {
-		plugin.Logger(ctx).Error("getUserWorkspaceProcess", "get", err)
-		return nil, err
-	}
-
-	return response, nil
-}
-
-func getOrgWorkspaceProcess(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData, orgHandle, workspaceHandle, processId string) (interface{}, error) {
-	// Create Session
-	svc, err := connect(ctx, d)
-	if err != nil {
-		plugin.Logger(ctx).Error("getOrgWorkspaceProcess", "connection_error", err)
-		return nil, err
-	}
-
-	var process openapi.SpProcess
-
-	getDetails := func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
-		process, _, err = svc.OrgWorkspaceProcesses.Get(ctx, orgHandle, workspaceHandle, processId).Execute()
-		return process, err
-	}
-
-	response, err := plugin.RetryHydrate(ctx, d, h, getDetails, &plugin.RetryConfig{})
-	if err != nil {
-		plugin.Logger(ctx).Error("getOrgWorkspaceProcess", "get", err)
+		plugin.Logger(ctx).Error("getWorkspaceProcess", "error", err)
 		return nil, err
 	}
 
-	return response, nil
+	return response.(openapi.SpProcess), nil
 }
 
 func getIdentityWorkspaceDetailsForWorkspaceProcess(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
-	// Create Session
-	svc, err := connect(ctx, d)
+	item := h.Item.(openapi.SpProcess)
+	details, err := getIdentityWorkspaceDetailsForIds(ctx, d, h, item.GetIdentityId(), item.GetWorkspaceId())
 	if err != nil {
-		plugin.Logger(ctx).Error("getIdentityWorkspaceDetailsForWorkspaceProcess", "connection_error", err)
+		plugin.Logger(ctx).Error("getIdentityWorkspaceDetailsForWorkspaceProcess", "error", err)
 		return nil, err
 	}
-
-	var identityWorkspaceDetails IdentityWorkspaceDetailsForProcess
-	// get workspace details from hydrate data
-	// workspace details reside in the parent item in this case
-	switch w := h.ParentItem.(type) {
-	case openapi.Workspace:
-		plugin.Logger(ctx).Debug("getIdentityWorkspaceDetailsForWorkspaceProcess", "openapi.Workspace")
-		identityId := h.ParentItem.(openapi.Workspace).IdentityId
-		identityWorkspaceDetails.WorkspaceHandle = h.ParentItem.(openapi.Workspace).Handle
-		getDetails := func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
-			if strings.HasPrefix(identityId, "u_") {
-				resp, _, err := svc.Users.Get(ctx, identityId).Execute()
-				identityWorkspaceDetails.IdentityType = "user"
-				identityWorkspaceDetails.IdentityHandle = resp.Handle
-				return nil, err
-			} else {
-				resp, _, err := svc.Orgs.Get(ctx, identityId).Execute()
-				identityWorkspaceDetails.IdentityType = "org"
-				identityWorkspaceDetails.IdentityHandle = resp.Handle
-				return nil, err
-			}
-		}
-		_, _ = plugin.RetryHydrate(ctx, d, h, getDetails, &plugin.RetryConfig{})
-		return identityWorkspaceDetails, nil
-	case *openapi.Workspace:
-		plugin.Logger(ctx).Debug("getIdentityWorkspaceDetailsForWorkspaceProcess", "*openapi.Workspace")
-		identityId := h.ParentItem.(*openapi.Workspace).IdentityId
-		identityWorkspaceDetails.WorkspaceHandle = h.ParentItem.(*openapi.Workspace).Handle
-		getDetails := func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
-			if strings.HasPrefix(identityId, "u_") {
-				resp, _, err := svc.Users.Get(ctx, identityId).Execute()
-				identityWorkspaceDetails.IdentityType = "user"
-				identityWorkspaceDetails.IdentityHandle = resp.Handle
-				return nil, err
-			} else {
-				resp, _, err := svc.Orgs.Get(ctx, identityId).Execute()
-				identityWorkspaceDetails.IdentityType = "org"
-				identityWorkspaceDetails.IdentityHandle = resp.Handle
-				return nil, err
-			}
-		}
-		_, _ = plugin.RetryHydrate(ctx, d, h, getDetails, &plugin.RetryConfig{})
-		plugin.Logger(ctx).Debug("getIdentityWorkspaceDetailsForWorkspaceProcess", "identityWorkspaceDetails", identityWorkspaceDetails)
-		return &identityWorkspaceDetails, nil
-	default:
-		plugin.Logger(ctx).Debug("getIdentityWorkspaceDetailsForWorkspaceProcess", "Unknown Type", w)
-	}
-	return &identityWorkspaceDetails, nil
+	return details, nil
 }
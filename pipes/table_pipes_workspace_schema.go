@@ -0,0 +1,290 @@
+package pipes
+
+import (
+	"context"
+
+	openapi "github.com/turbot/pipes-sdk-go"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+//// TABLE DEFINITION
+
+func tablePipesWorkspaceSchema(_ context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "pipes_workspace_schema",
+		Description: "Workspace schemas are the Postgres schemas of a workspace database, one for each connection, aggregator and datatank in the workspace.",
+		List: &plugin.ListConfig{
+			ParentHydrate: listWorkspaces,
+			Hydrate:       listWorkspaceSchemas,
+			KeyColumns: []*plugin.KeyColumn{
+				{
+					Name:    "identity_handle",
+					Require: plugin.Optional,
+				},
+				{
+					Name:    "identity_id",
+					Require: plugin.Optional,
+				},
+				{
+					Name:    "workspace_handle",
+					Require: plugin.Optional,
+				},
+				{
+					Name:    "workspace_id",
+					Require: plugin.Optional,
+				},
+			},
+		},
+		Get: &plugin.GetConfig{
+			KeyColumns: plugin.AllColumns([]string{"identity_id", "workspace_id", "name"}),
+			Hydrate:    getWorkspaceSchema,
+		},
+		Columns: commonColumns([]*plugin.Column{
+			{
+				Name:        "id",
+				Description: "The unique identifier for the schema.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromCamel(),
+			},
+			{
+				Name:        "name",
+				Description: "The name of the schema.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "type",
+				Description: "The source of the schema. Can be one of 'connection', 'aggregator' or 'datatank'.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "state",
+				Description: "How the schema is available in the workspace. Can be one of 'granted', 'direct' or 'indirect'.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "plugin",
+				Description: "The plugin of the connection or aggregator the schema is for.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Connection.Plugin", "Aggregator.Plugin"),
+			},
+			{
+				Name:        "connection_id",
+				Description: "The unique identifier of the connection, for connection schemas.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromCamel(),
+			},
+			{
+				Name:        "connection_handle",
+				Description: "The handle of the connection, for connection schemas.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Connection.Handle"),
+			},
+			{
+				Name:        "aggregator_id",
+				Description: "The unique identifier of the aggregator, for aggregator schemas.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromCamel(),
+			},
+			{
+				Name:        "aggregator_handle",
+				Description: "The handle of the aggregator, for aggregator schemas.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Aggregator.Handle"),
+			},
+			{
+				Name:        "datatank_id",
+				Description: "The unique identifier of the datatank, for datatank schemas.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromCamel(),
+			},
+			{
+				Name:        "datatank_handle",
+				Description: "The handle of the datatank, for datatank schemas.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Datatank.Handle"),
+			},
+			{
+				Name:        "aggregated_by",
+				Description: "The aggregators that include the schema.",
+				Type:        proto.ColumnType_JSON,
+			},
+			{
+				Name:        "search_path_position",
+				Description: "The position of the schema in the workspace's search path, starting at 1, or null if the schema is not in the search path.",
+				Type:        proto.ColumnType_INT,
+				Hydrate:     getWorkspaceSchemaSearchPathPosition,
+				Transform:   transform.FromValue(),
+			},
+			{
+				Name:        "identity_id",
+				Description: "The unique identifier for the identity which contains the workspace.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromCamel(),
+			},
+			{
+				Name:        "identity_handle",
+				Description: "The handle of the identity which contains the workspace.",
+				Type:        proto.ColumnType_STRING,
+				Hydrate:     getIdentityWorkspaceDetailsForWorkspaceSchema,
+			},
+			{
+				Name:        "identity_type",
+				Description: "The type of identity, which can be 'user' or 'org'.",
+				Type:        proto.ColumnType_STRING,
+				Hydrate:     getIdentityWorkspaceDetailsForWorkspaceSchema,
+			},
+			{
+				Name:        "workspace_id",
+				Description: "The unique identifier for the workspace.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromCamel(),
+			},
+			{
+				Name:        "workspace_handle",
+				Description: "The handle for the workspace.",
+				Type:        proto.ColumnType_STRING,
+				Hydrate:     getIdentityWorkspaceDetailsForWorkspaceSchema,
+			},
+			{
+				Name:        "created_at",
+				Description: "The time when the schema was created.",
+				Type:        proto.ColumnType_TIMESTAMP,
+			},
+			{
+				Name:        "created_by_id",
+				Description: "The unique identifier of the user who created the schema.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromCamel(),
+			},
+			{
+				Name:        "created_by",
+				Description: "Information about the user who created the schema.",
+				Type:        proto.ColumnType_JSON,
+			},
+			{
+				Name:        "updated_at",
+				Description: "The time when the schema was last updated.",
+				Type:        proto.ColumnType_TIMESTAMP,
+			},
+			{
+				Name:        "updated_by_id",
+				Description: "The unique identifier of the user who last updated the schema.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromCamel(),
+			},
+			{
+				Name:        "updated_by",
+				Description: "Information about the user who last updated the schema.",
+				Type:        proto.ColumnType_JSON,
+			},
+			{
+				Name:        "version_id",
+				Description: "The current version ID of the schema.",
+				Type:        proto.ColumnType_INT,
+				Transform:   transform.FromCamel(),
+			},
+		}),
+	}
+}
+
+//// LIST FUNCTION
+
+func listWorkspaceSchemas(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	workspace := parentWorkspace(h.Item)
+	if workspace == nil {
+		plugin.Logger(ctx).Error("listWorkspaceSchemas", "unknown response type for workspace list parent hydrate call", h.Item)
+		return nil, nil
+	}
+	if !workspaceMatchesQuals(d, workspace) {
+		return nil, nil
+	}
+
+	api, err := getIdentityService(ctx, d, h, workspace.IdentityId)
+	if err != nil {
+		plugin.Logger(ctx).Error("listWorkspaceSchemas", "getIdentityService", err)
+		return nil, err
+	}
+
+	err = paginate(ctx, d, api.ListWorkspaceSchemas(workspace.Id))
+	if err != nil {
+		plugin.Logger(ctx).Error("listWorkspaceSchemas", "list", err)
+		return nil, err
+	}
+	return nil, nil
+}
+
+//// HYDRATE FUNCTIONS
+
+func getWorkspaceSchema(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	identityId := d.EqualsQuals["identity_id"].GetStringValue()
+	workspaceId := d.EqualsQuals["workspace_id"].GetStringValue()
+	name := d.EqualsQuals["name"].GetStringValue()
+
+	// check if identity or workspace or schema information is missing
+	if identityId == "" || workspaceId == "" || name == "" {
+		return nil, nil
+	}
+
+	api, err := getIdentityService(ctx, d, h, identityId)
+	if err != nil {
+		plugin.Logger(ctx).Error("getWorkspaceSchema", "getIdentityService", err)
+		return nil, err
+	}
+
+	getDetails := func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+		return api.GetWorkspaceSchema(ctx, workspaceId, name)
+	}
+
+	response, err := plugin.RetryHydrate(ctx, d, h, getDetails, &plugin.RetryConfig{})
+	if err != nil {
+		plugin.Logger(ctx).Error("getWorkspaceSchema", "get", err)
+		return nil, err
+	}
+
+	return response.(openapi.WorkspaceSchema), nil
+}
+
+func getIdentityWorkspaceDetailsForWorkspaceSchema(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	item := h.Item.(openapi.WorkspaceSchema)
+	details, err := getIdentityWorkspaceDetailsForIds(ctx, d, h, item.IdentityId, item.WorkspaceId)
+	if err != nil {
+		plugin.Logger(ctx).Error("getIdentityWorkspaceDetailsForWorkspaceSchema", "error", err)
+		return nil, err
+	}
+	return details, nil
+}
+
+func getWorkspaceSchemaSearchPathPosition(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	item := h.Item.(openapi.WorkspaceSchema)
+
+	// The search path is read from the parent workspace when the row came
+	// from a list call, and looked up for get calls
+	workspace := parentWorkspace(h.ParentItem)
+	if workspace == nil {
+		api, err := getIdentityService(ctx, d, h, item.IdentityId)
+		if err != nil {
+			plugin.Logger(ctx).Error("getWorkspaceSchemaSearchPathPosition", "getIdentityService", err)
+			return nil, err
+		}
+		getDetails := func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+			return api.GetWorkspace(ctx, item.WorkspaceId)
+		}
+		response, err := plugin.RetryHydrate(ctx, d, h, getDetails, &plugin.RetryConfig{})
+		if err != nil {
+			plugin.Logger(ctx).Error("getWorkspaceSchemaSearchPathPosition", "get_workspace", err)
+			return nil, err
+		}
+		w := response.(openapi.Workspace)
+		workspace = &w
+	}
+
+	for i, schema := range workspace.GetSearchPath() {
+		if schema == item.Name {
+			return i + 1, nil
+		}
+	}
+	return nil, nil
+}
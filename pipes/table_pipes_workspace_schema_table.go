@@ -0,0 +1,244 @@
+package pipes
+
+import (
+	"context"
+	"net/http"
+
+	openapi "github.com/turbot/pipes-sdk-go"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+//// TABLE DEFINITION
+
+func tablePipesWorkspaceSchemaTable(_ context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "pipes_workspace_schema_table",
+		Description: "Workspace schema tables are the tables in each Postgres schema of a workspace database.",
+		List: &plugin.ListConfig{
+			ParentHydrate: listWorkspaces,
+			Hydrate:       listWorkspaceSchemaTables,
+			KeyColumns: []*plugin.KeyColumn{
+				{
+					Name:    "identity_handle",
+					Require: plugin.Optional,
+				},
+				{
+					Name:    "identity_id",
+					Require: plugin.Optional,
+				},
+				{
+					Name:    "schema_name",
+					Require: plugin.Optional,
+				},
+				{
+					Name:    "workspace_handle",
+					Require: plugin.Optional,
+				},
+				{
+					Name:    "workspace_id",
+					Require: plugin.Optional,
+				},
+			},
+		},
+		Get: &plugin.GetConfig{
+			KeyColumns: plugin.AllColumns([]string{"identity_id", "workspace_id", "schema_name", "name"}),
+			Hydrate:    getWorkspaceSchemaTable,
+		},
+		Columns: commonColumns([]*plugin.Column{
+			{
+				Name:        "name",
+				Description: "The name of the table.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "description",
+				Description: "The description of the table.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "column_count",
+				Description: "The number of columns in the table.",
+				Type:        proto.ColumnType_INT,
+			},
+			{
+				Name:        "columns",
+				Description: "The columns of the table, with their name, data type and description.",
+				Type:        proto.ColumnType_JSON,
+			},
+			{
+				Name:        "schema_name",
+				Description: "The name of the schema the table is in.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Schema.Name"),
+			},
+			{
+				Name:        "schema_type",
+				Description: "The source of the schema. Can be one of 'connection', 'aggregator' or 'datatank'.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Schema.Type"),
+			},
+			{
+				Name:        "plugin",
+				Description: "The plugin of the connection or aggregator the schema is for.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Schema.Connection.Plugin", "Schema.Aggregator.Plugin"),
+			},
+			{
+				Name:        "identity_id",
+				Description: "The unique identifier for the identity which contains the workspace.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Schema.IdentityId"),
+			},
+			{
+				Name:        "identity_handle",
+				Description: "The handle of the identity which contains the workspace.",
+				Type:        proto.ColumnType_STRING,
+				Hydrate:     getIdentityWorkspaceDetailsForWorkspaceSchemaTable,
+			},
+			{
+				Name:        "identity_type",
+				Description: "The type of identity, which can be 'user' or 'org'.",
+				Type:        proto.ColumnType_STRING,
+				Hydrate:     getIdentityWorkspaceDetailsForWorkspaceSchemaTable,
+			},
+			{
+				Name:        "workspace_id",
+				Description: "The unique identifier for the workspace.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Schema.WorkspaceId"),
+			},
+			{
+				Name:        "workspace_handle",
+				Description: "The handle for the workspace.",
+				Type:        proto.ColumnType_STRING,
+				Hydrate:     getIdentityWorkspaceDetailsForWorkspaceSchemaTable,
+			},
+		}),
+	}
+}
+
+// WorkspaceSchemaTable is a table in a workspace schema. The API returns the
+// table itself, which does not reference its schema.
+type WorkspaceSchemaTable struct {
+	openapi.WorkspaceSchemaTable
+	Schema      openapi.WorkspaceSchema
+	ColumnCount int
+}
+
+func newWorkspaceSchemaTable(table openapi.WorkspaceSchemaTable, schema openapi.WorkspaceSchema) WorkspaceSchemaTable {
+	return WorkspaceSchemaTable{WorkspaceSchemaTable: table, Schema: schema, ColumnCount: len(table.Columns)}
+}
+
+//// LIST FUNCTION
+
+func listWorkspaceSchemaTables(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	workspace := parentWorkspace(h.Item)
+	if workspace == nil {
+		plugin.Logger(ctx).Error("listWorkspaceSchemaTables", "unknown response type for workspace list parent hydrate call", h.Item)
+		return nil, nil
+	}
+	if !workspaceMatchesQuals(d, workspace) {
+		return nil, nil
+	}
+
+	api, err := getIdentityService(ctx, d, h, workspace.IdentityId)
+	if err != nil {
+		plugin.Logger(ctx).Error("listWorkspaceSchemaTables", "getIdentityService", err)
+		return nil, err
+	}
+
+	// Tables are listed per schema, so list the schemas of the workspace
+	// unless the query is for a single schema
+	var schemas []openapi.WorkspaceSchema
+	if schemaName := d.EqualsQualString("schema_name"); schemaName != "" {
+		schema, err := api.GetWorkspaceSchema(ctx, workspace.Id, schemaName)
+		if err != nil {
+			if errorStatusCode(err) == http.StatusNotFound {
+				return nil, nil
+			}
+			plugin.Logger(ctx).Error("listWorkspaceSchemaTables", "get_schema", err)
+			return nil, err
+		}
+		schemas = append(schemas, schema)
+	} else {
+		schemas, err = listAll(ctx, d, api.ListWorkspaceSchemas(workspace.Id))
+		if err != nil {
+			plugin.Logger(ctx).Error("listWorkspaceSchemaTables", "list_schemas", err)
+			return nil, err
+		}
+	}
+
+	for _, schema := range schemas {
+		err = paginate(ctx, d, func(ctx context.Context, nextToken *string, limit int32) ([]WorkspaceSchemaTable, *string, error) {
+			tables, pageToken, err := api.ListWorkspaceSchemaTables(workspace.Id, schema.Name)(ctx, nextToken, limit)
+			items := make([]WorkspaceSchemaTable, 0, len(tables))
+			for _, table := range tables {
+				items = append(items, newWorkspaceSchemaTable(table, schema))
+			}
+			return items, pageToken, err
+		})
+		if err != nil {
+			plugin.Logger(ctx).Error("listWorkspaceSchemaTables", "list", err, "schema", schema.Name)
+			return nil, err
+		}
+
+		// Context can be cancelled due to manual cancellation or the limit has been hit
+		if d.RowsRemaining(ctx) == 0 {
+			return nil, nil
+		}
+	}
+	return nil, nil
+}
+
+//// HYDRATE FUNCTIONS
+
+func getWorkspaceSchemaTable(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	identityId := d.EqualsQuals["identity_id"].GetStringValue()
+	workspaceId := d.EqualsQuals["workspace_id"].GetStringValue()
+	schemaName := d.EqualsQuals["schema_name"].GetStringValue()
+	name := d.EqualsQuals["name"].GetStringValue()
+
+	// check if identity or workspace or schema or table information is missing
+	if identityId == "" || workspaceId == "" || schemaName == "" || name == "" {
+		return nil, nil
+	}
+
+	api, err := getIdentityService(ctx, d, h, identityId)
+	if err != nil {
+		plugin.Logger(ctx).Error("getWorkspaceSchemaTable", "getIdentityService", err)
+		return nil, err
+	}
+
+	getDetails := func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+		schema, err := api.GetWorkspaceSchema(ctx, workspaceId, schemaName)
+		if err != nil {
+			return nil, err
+		}
+		table, err := api.GetWorkspaceSchemaTable(ctx, workspaceId, schemaName, name)
+		if err != nil {
+			return nil, err
+		}
+		return newWorkspaceSchemaTable(table, schema), nil
+	}
+
+	response, err := plugin.RetryHydrate(ctx, d, h, getDetails, &plugin.RetryConfig{})
+	if err != nil {
+		plugin.Logger(ctx).Error("getWorkspaceSchemaTable", "get", err)
+		return nil, err
+	}
+
+	return response.(WorkspaceSchemaTable), nil
+}
+
+func getIdentityWorkspaceDetailsForWorkspaceSchemaTable(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	item := h.Item.(WorkspaceSchemaTable)
+	details, err := getIdentityWorkspaceDetailsForIds(ctx, d, h, item.Schema.IdentityId, item.Schema.WorkspaceId)
+	if err != nil {
+		plugin.Logger(ctx).Error("getIdentityWorkspaceDetailsForWorkspaceSchemaTable", "error", err)
+		return nil, err
+	}
+	return details, nil
+}
@@ -2,9 +2,6 @@ package pipes
 
 import (
 	"context"
-	"fmt"
-	"strings"
-	"time"
 
 	openapi "github.com/turbot/pipes-sdk-go"
 
@@ -13,21 +10,16 @@ import (
 	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
 )
 
-type IdentityWorkspaceDetails struct {
-	IdentityHandle  string `json:"identity_handle"`
-	IdentityType    string `json:"identity_type"`
-	WorkspaceHandle string `json:"workspace_handle"`
-}
-
 type SnapshotData struct {
 	Data openapi.WorkspaceSnapshotData
 }
 
-var getIdentityWorkspaceDetailsCached = plugin.HydrateFunc(getIdentityWorkspaceDetails).WithCache()
-var getSnapshotDataCached = plugin.HydrateFunc(getSnapshotData).WithCache()
+var getSnapshotDataCached = plugin.HydrateFunc(getSnapshotData).WithCache(getSnapshotDataCacheKey)
 
-func getIdentityWorkspaceDetailsWrapper(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
-	return getIdentityWorkspaceDetailsCached(ctx, d, h)
+// getSnapshotDataCacheKey keys the downloaded snapshot data by snapshot, so
+// rows never share each other's data.
+func getSnapshotDataCacheKey(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	return "GetSnapshotData-" + h.Item.(openapi.WorkspaceSnapshot).Id, nil
 }
 
 func getSnapshotDataWrapper(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
@@ -51,9 +43,25 @@ func tablePipesWorkspaceSnapshot(_ context.Context) *plugin.Table {
 			},
 		},
 		List: &plugin.ListConfig{
-			ParentHydrate: listWorkspaces,
+			ParentHydrate: listQualWorkspaces,
 			Hydrate:       listWorkspaceSnapshots,
 			KeyColumns: []*plugin.KeyColumn{
+				{
+					Name:    "identity_handle",
+					Require: plugin.Optional,
+				},
+				{
+					Name:    "identity_id",
+					Require: plugin.Optional,
+				},
+				{
+					Name:    "workspace_handle",
+					Require: plugin.Optional,
+				},
+				{
+					Name:    "workspace_id",
+					Require: plugin.Optional,
+				},
 				{
 					Name:      "created_at",
 					Require:   plugin.Optional,
@@ -62,12 +70,12 @@ func tablePipesWorkspaceSnapshot(_ context.Context) *plugin.Table {
 				{
 					Name:      "dashboard_name",
 					Require:   plugin.Optional,
-					Operators: []string{"=", "<>"},
+					Operators: []string{"=", "<>", "~~", "!~~", "~~*", "!~~*"},
 				},
 				{
 					Name:      "dashboard_title",
 					Require:   plugin.Optional,
-					Operators: []string{"=", "<>"},
+					Operators: []string{"=", "<>", "~~", "!~~", "~~*", "!~~*"},
 				},
 				{
 					Name:      "id",
@@ -107,13 +115,13 @@ func tablePipesWorkspaceSnapshot(_ context.Context) *plugin.Table {
 				Name:        "identity_handle",
 				Description: "The handle of the identity.",
 				Type:        proto.ColumnType_STRING,
-				Hydrate:     getIdentityWorkspaceDetailsWrapper,
+				Hydrate:     getIdentityWorkspaceDetails,
 			},
 			{
 				Name:        "identity_type",
 				Description: "The type of identity, can be org/user.",
 				Type:        proto.ColumnType_STRING,
-				Hydrate:     getIdentityWorkspaceDetailsWrapper,
+				Hydrate:     getIdentityWorkspaceDetails,
 			},
 			{
 				Name:        "workspace_id",
@@ -125,7 +133,7 @@ func tablePipesWorkspaceSnapshot(_ context.Context) *plugin.Table {
 				Name:        "workspace_handle",
 				Description: "The handle of the workspace.",
 				Type:        proto.ColumnType_STRING,
-				Hydrate:     getIdentityWorkspaceDetailsWrapper,
+				Hydrate:     getIdentityWorkspaceDetails,
 			},
 			{
 				Name:        "state",
@@ -225,246 +233,31 @@ func tablePipesWorkspaceSnapshot(_ context.Context) *plugin.Table {
 //// LIST FUNCTION
 
 func listWorkspaceSnapshots(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
-	var workspace *openapi.Workspace
-	switch w := h.Item.(type) {
-	case openapi.Workspace:
-		wo := h.Item.(openapi.Workspace)
-		workspace = &wo
-	case *openapi.Workspace:
-		workspace = h.Item.(*openapi.Workspace)
-	default:
-		plugin.Logger(ctx).Error("listWorkspaceSnapshots", "unknown response type for workspace list parent hydrate call", w)
+	workspace := parentWorkspace(h.Item)
+	if workspace == nil {
+		plugin.Logger(ctx).Error("listWorkspaceSnapshots", "unknown response type for workspace list parent hydrate call", h.Item)
+		return nil, nil
 	}
-
-	// If the requested number of items is less than the paging max limit
-	// set the limit to that instead
-	maxResults := int32(100)
-	limit := d.QueryContext.Limit
-	if d.QueryContext.Limit != nil {
-		if *limit < int64(maxResults) {
-			if *limit < 1 {
-				maxResults = int32(1)
-			} else {
-				maxResults = int32(*limit)
-			}
-		}
+	if !workspaceMatchesQuals(d, workspace) {
+		return nil, nil
 	}
 
-	var err error
-	if strings.HasPrefix(workspace.IdentityId, "u_") {
-		err = listUserWorkspaceSnapshots(ctx, d, h, workspace.IdentityId, workspace.Handle, maxResults)
-	} else {
-		err = listOrgWorkspaceSnapshots(ctx, d, h, workspace.IdentityId, workspace.Handle, maxResults)
-	}
+	// Build the where filter from the quals, with all values escaped
+	filter := buildListFilter(d)
 
+	api, err := getIdentityService(ctx, d, h, workspace.IdentityId)
 	if err != nil {
-		plugin.Logger(ctx).Error("listWorkspaceSnapshots", "error", err)
+		plugin.Logger(ctx).Error("listWorkspaceSnapshots", "getIdentityService", err)
 		return nil, err
 	}
 
-	return nil, nil
-}
-
-func listUserWorkspaceSnapshots(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData, userHandle string, workspaceHandle string, maxResults int32) error {
-	// Create Session
-	svc, err := connect(ctx, d)
+	err = paginate(ctx, d, api.ListWorkspaceSnapshots(workspace.Handle, filter))
 	if err != nil {
-		plugin.Logger(ctx).Error("listUserWorkspaceSnapshots", "connection_error", err)
-		return err
-	}
-
-	var filter string
-	// collect all clauses passed as quals except for "query_where"
-	var clauses []string
-	for _, keyQual := range d.Table.List.KeyColumns {
-		filterQual := d.Quals[keyQual.Name]
-		if filterQual == nil || keyQual.Name == "query_where" {
-			continue
-		}
-		for _, qual := range filterQual.Quals {
-			if qual.Value != nil {
-				var value string
-				if keyQual.Name == "created_at" {
-					t := time.Unix(qual.Value.GetTimestampValue().Seconds, int64(qual.Value.GetTimestampValue().Nanos)).UTC()
-					value = t.Format("2006-01-02 15:04:05.00000")
-				} else {
-					value = qual.Value.GetStringValue()
-				}
-				switch qual.Operator {
-				case "=":
-					clauses = append(clauses, fmt.Sprintf(`%s = '%s'`, keyQual.Name, value))
-				case "<>":
-					clauses = append(clauses, fmt.Sprintf(`%s <> '%s'`, keyQual.Name, value))
-				case ">":
-					clauses = append(clauses, fmt.Sprintf(`%s > '%s'`, keyQual.Name, value))
-				case ">=":
-					clauses = append(clauses, fmt.Sprintf(`%s >= '%s'`, keyQual.Name, value))
-				case "<":
-					clauses = append(clauses, fmt.Sprintf(`%s < '%s'`, keyQual.Name, value))
-				case "<=":
-					clauses = append(clauses, fmt.Sprintf(`%s <= '%s'`, keyQual.Name, value))
-				}
-			}
-		}
-	}
-
-	// Frame the filter string by joining the collected quals by "and"
-	filter = strings.Join(clauses, " and ")
-
-	// Check if a query_where qual has been passed and add it to the filter string if yes
-	if d.EqualsQuals["query_where"] != nil {
-		if len(filter) >= 1 {
-			filter = filter + " and " + d.EqualsQuals["query_where"].GetStringValue()
-		} else {
-			filter = d.EqualsQuals["query_where"].GetStringValue()
-		}
-	}
-
-	pagesLeft := true
-	var resp openapi.ListWorkspaceSnapshotsResponse
-	var listDetails func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error)
-
-	for pagesLeft {
-		if resp.NextToken != nil {
-			listDetails = func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
-				resp, _, err = svc.UserWorkspaceSnapshots.List(ctx, userHandle, workspaceHandle).Where(filter).NextToken(*resp.NextToken).Limit(maxResults).Execute()
-				return resp, err
-			}
-		} else {
-			listDetails = func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
-				resp, _, err = svc.UserWorkspaceSnapshots.List(ctx, userHandle, workspaceHandle).Where(filter).Limit(maxResults).Execute()
-				return resp, err
-			}
-		}
-
-		response, err := plugin.RetryHydrate(ctx, d, h, listDetails, &plugin.RetryConfig{})
-
-		if err != nil {
-			plugin.Logger(ctx).Error("listUserWorkspaceSnapshots", "list", err)
-			return err
-		}
-
-		result := response.(openapi.ListWorkspaceSnapshotsResponse)
-
-		if result.HasItems() {
-			for _, snapshot := range *result.Items {
-				d.StreamListItem(ctx, snapshot)
-
-				// Context can be cancelled due to manual cancellation or the limit has been hit
-				if d.RowsRemaining(ctx) == 0 {
-					return nil
-				}
-			}
-		}
-		if result.NextToken == nil {
-			pagesLeft = false
-		} else {
-			resp.NextToken = result.NextToken
-		}
-	}
-
-	return nil
-}
-
-func listOrgWorkspaceSnapshots(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData, orgHandle string, workspaceHandle string, maxResults int32) error {
-	// Create Session
-	svc, err := connect(ctx, d)
-	if err != nil {
-		plugin.Logger(ctx).Error("listOrgWorkspaceSnapshots", "connection_error", err)
-		return err
-	}
-
-	var filter string
-	// collect all clauses passed as quals except for "query_where"
-	var clauses []string
-	for _, keyQual := range d.Table.List.KeyColumns {
-		filterQual := d.Quals[keyQual.Name]
-		if filterQual == nil || keyQual.Name == "query_where" {
-			continue
-		}
-		for _, qual := range filterQual.Quals {
-			if qual.Value != nil {
-				var value string
-				if keyQual.Name == "created_at" {
-					t := time.Unix(qual.Value.GetTimestampValue().Seconds, int64(qual.Value.GetTimestampValue().Nanos)).UTC()
-					value = t.Format("2006-01-02 15:04:05.00000")
-				} else {
-					value = qual.Value.GetStringValue()
-				}
-				switch qual.Operator {
-				case "=":
-					clauses = append(clauses, fmt.Sprintf(`%s = '%s'`, keyQual.Name, value))
-				case "<>":
-					clauses = append(clauses, fmt.Sprintf(`%s <> '%s'`, keyQual.Name, value))
-				case ">":
-					clauses = append(clauses, fmt.Sprintf(`%s > '%s'`, keyQual.Name, value))
-				case ">=":
-					clauses = append(clauses, fmt.Sprintf(`%s >= '%s'`, keyQual.Name, value))
-				case "<":
-					clauses = append(clauses, fmt.Sprintf(`%s < '%s'`, keyQual.Name, value))
-				case "<=":
-					clauses = append(clauses, fmt.Sprintf(`%s <= '%s'`, keyQual.Name, value))
-				}
-			}
-		}
-	}
-
-	// Frame the filter string by joining the collected quals by "and"
-	filter = strings.Join(clauses, " and ")
-
-	// Check if a query_where qual has been passed and add it to the filter string if yes
-	if d.EqualsQuals["query_where"] != nil {
-		if len(filter) >= 1 {
-			filter = filter + " and " + d.EqualsQuals["query_where"].GetStringValue()
-		} else {
-			filter = d.EqualsQuals["query_where"].GetStringValue()
-		}
-	}
-
-	pagesLeft := true
-	var resp openapi.ListWorkspaceSnapshotsResponse
-	var listDetails func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error)
-
-	for pagesLeft {
-		if resp.NextToken != nil {
-			listDetails = func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
-				resp, _, err = svc.OrgWorkspaceSnapshots.List(ctx, orgHandle, workspaceHandle).Where(filter).NextToken(*resp.NextToken).Limit(maxResults).Execute()
-				return resp, err
-			}
-		} else {
-			listDetails = func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
-				resp, _, err = svc.OrgWorkspaceSnapshots.List(ctx, orgHandle, workspaceHandle).Where(filter).Limit(maxResults).Execute()
-				return resp, err
-			}
-		}
-
-		response, err := plugin.RetryHydrate(ctx, d, h, listDetails, &plugin.RetryConfig{})
-
-		if err != nil {
-			plugin.Logger(ctx).Error("listOrgWorkspaceSnapshots", "list", err)
-			return err
-		}
-
-		result := response.(openapi.ListWorkspaceSnapshotsResponse)
-
-		if result.HasItems() {
-			for _, snapshot := range *result.Items {
-				d.StreamListItem(ctx, snapshot)
-
-				// Context can be cancelled due to manual cancellation or the limit has been hit
-				if d.RowsRemaining(ctx) == 0 {
-					return nil
-				}
-			}
-		}
-		if result.NextToken == nil {
-			pagesLeft = false
-		} else {
-			resp.NextToken = result.NextToken
-		}
+		plugin.Logger(ctx).Error("listWorkspaceSnapshots", "error", err)
+		return nil, err
 	}
 
-	return nil
+	return nil, nil
 }
 
 func getWorkspaceSnapshot(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
@@ -477,172 +270,58 @@ func getWorkspaceSnapshot(ctx context.Context, d *plugin.QueryData, h *plugin.Hy
 		return nil, nil
 	}
 
-	getUserIdentityCached := plugin.HydrateFunc(getUserIdentity).WithCache()
-	commonData, err := getUserIdentityCached(ctx, d, h)
-	if err != nil {
-		plugin.Logger(ctx).Error("getWorkspaceSnapshot", "getUserIdentityCached", err)
-		return nil, err
-	}
-
-	user := commonData.(openapi.User)
-	var response interface{}
-	if identityHandle == user.Handle {
-		response, err = getUserWorkspaceSnapshot(ctx, d, h, identityHandle, workspaceHandle, snapshotId)
-	} else {
-		response, err = getOrgWorkspaceSnapshot(ctx, d, h, identityHandle, workspaceHandle, snapshotId)
-	}
-
-	if err != nil {
-		plugin.Logger(ctx).Error("getWorkspaceSnapshot", "error", err)
-		return nil, err
-	}
-
-	return response.(openapi.WorkspaceSnapshot), nil
-}
-
-func getUserWorkspaceSnapshot(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData, userHandle, workspaceHandle, snapshotId string) (interface{}, error) {
-	// Create Session
-	svc, err := connect(ctx, d)
+	api, err := getIdentityService(ctx, d, h, identityHandle)
 	if err != nil {
-		plugin.Logger(ctx).Error("getUserWorkspaceSnapshot", "connection_error", err)
+		plugin.Logger(ctx).Error("getWorkspaceSnapshot", "getIdentityService", err)
 		return nil, err
 	}
 
-	var snapshot openapi.WorkspaceSnapshot
-
 	getDetails := func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
-		snapshot, _, err = svc.UserWorkspaceSnapshots.Get(ctx, userHandle, workspaceHandle, snapshotId).Execute()
-		return snapshot, err
+		return api.GetWorkspaceSnapshot(ctx, workspaceHandle, snapshotId)
 	}
 
 	response, err := plugin.RetryHydrate(ctx, d, h, getDetails, &plugin.RetryConfig{})
 	if err != nil {
-		plugin.Logger(ctx).Error("getUserWorkspaceSnapshot", "get", err)
-		return nil, err
-	}
-
-	return response, nil
-}
-
-func getOrgWorkspaceSnapshot(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData, orgHandle, workspaceHandle, snapshotId string) (interface{}, error) {
-	// Create Session
-	svc, err := connect(ctx, d)
-	if err != nil {
-		plugin.Logger(ctx).Error("getOrgWorkspaceSnapshot", "connection_error", err)
-		return nil, err
-	}
-
-	var snapshot openapi.WorkspaceSnapshot
-
-	getDetails := func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
-		snapshot, _, err = svc.OrgWorkspaceSnapshots.Get(ctx, orgHandle, workspaceHandle, snapshotId).Execute()
-		return snapshot, err
-	}
-
-	response, err := plugin.RetryHydrate(ctx, d, h, getDetails, &plugin.RetryConfig{})
-	if err != nil {
-		plugin.Logger(ctx).Error("getOrgWorkspaceSnapshot", "get", err)
+		plugin.Logger(ctx).Error("getWorkspaceSnapshot", "error", err)
 		return nil, err
 	}
 
-	return response, nil
+	return response.(openapi.WorkspaceSnapshot), nil
 }
 
 func getIdentityWorkspaceDetails(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
-	// Create Session
-	svc, err := connect(ctx, d)
+	item := h.Item.(openapi.WorkspaceSnapshot)
+	details, err := getIdentityWorkspaceDetailsForIds(ctx, d, h, item.GetIdentityId(), item.GetWorkspaceId())
 	if err != nil {
-		plugin.Logger(ctx).Error("getIdentityWorkspaceDetails", "connection_error", err)
+		plugin.Logger(ctx).Error("getIdentityWorkspaceDetails", "error", err)
 		return nil, err
 	}
-
-	var identityWorkspaceDetails IdentityWorkspaceDetails
-	// get workspace details from hydrate data
-	// workspace details reside in the parent item in this case
-	switch w := h.ParentItem.(type) {
-	case openapi.Workspace:
-		identityId := h.ParentItem.(openapi.Workspace).IdentityId
-		identityWorkspaceDetails.WorkspaceHandle = h.ParentItem.(openapi.Workspace).Handle
-		getDetails := func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
-			if strings.HasPrefix(identityId, "u_") {
-				resp, _, err := svc.Users.Get(ctx, identityId).Execute()
-				identityWorkspaceDetails.IdentityType = "user"
-				identityWorkspaceDetails.IdentityHandle = resp.Handle
-				return nil, err
-			} else {
-				resp, _, err := svc.Orgs.Get(ctx, identityId).Execute()
-				identityWorkspaceDetails.IdentityType = "org"
-				identityWorkspaceDetails.IdentityHandle = resp.Handle
-				return nil, err
-			}
-		}
-		_, _ = plugin.RetryHydrate(ctx, d, h, getDetails, &plugin.RetryConfig{})
-		return identityWorkspaceDetails, nil
-	default:
-		plugin.Logger(ctx).Debug("getIdentityWorkspaceDetails", "Unknown Type", w)
-	}
-
-	identityId := h.Item.(openapi.WorkspaceSnapshot).IdentityId
-	workspaceId := h.Item.(openapi.WorkspaceSnapshot).WorkspaceId
-	getIdentityDetails := func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
-		if strings.HasPrefix(identityId, "u_") {
-			resp, _, err := svc.Users.Get(ctx, identityId).Execute()
-			identityWorkspaceDetails.IdentityType = "user"
-			identityWorkspaceDetails.IdentityHandle = resp.Handle
-			return nil, err
-		} else {
-			resp, _, err := svc.Orgs.Get(ctx, identityId).Execute()
-			identityWorkspaceDetails.IdentityType = "org"
-			identityWorkspaceDetails.IdentityHandle = resp.Handle
-			return nil, err
-		}
-	}
-	_, _ = plugin.RetryHydrate(ctx, d, h, getIdentityDetails, &plugin.RetryConfig{})
-
-	getWorkspaceDetails := func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
-		if strings.HasPrefix(identityId, "u_") {
-			resp, _, err := svc.UserWorkspaces.Get(ctx, identityId, workspaceId).Execute()
-			identityWorkspaceDetails.WorkspaceHandle = resp.Handle
-			return nil, err
-		} else {
-			resp, _, err := svc.OrgWorkspaces.Get(ctx, identityId, workspaceId).Execute()
-			identityWorkspaceDetails.WorkspaceHandle = resp.Handle
-			return nil, err
-		}
-	}
-	_, _ = plugin.RetryHydrate(ctx, d, h, getWorkspaceDetails, &plugin.RetryConfig{})
-
-	return identityWorkspaceDetails, nil
+	return details, nil
 }
 
 func getSnapshotData(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
-	// Create Session
-	svc, err := connect(ctx, d)
+	workspaceSnapshot := h.Item.(openapi.WorkspaceSnapshot)
+
+	api, err := getIdentityService(ctx, d, h, workspaceSnapshot.IdentityId)
 	if err != nil {
-		plugin.Logger(ctx).Error("getSnapshotData", "connection_error", err)
+		plugin.Logger(ctx).Error("getSnapshotData", "getIdentityService", err)
 		return nil, err
 	}
 
 	var snapshotData SnapshotData
-	workspaceSnapshot := h.Item.(openapi.WorkspaceSnapshot)
-	var response openapi.WorkspaceSnapshotData
 	getSnapshotData := func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
-		if strings.HasPrefix(workspaceSnapshot.IdentityId, "u_") {
-			response, _, err = svc.UserWorkspaceSnapshots.Download(ctx, workspaceSnapshot.IdentityId, workspaceSnapshot.WorkspaceId, workspaceSnapshot.Id, "json").Execute()
-			if err != nil {
-				return nil, err
-			}
-
-		} else {
-			response, _, err = svc.OrgWorkspaceSnapshots.Download(ctx, workspaceSnapshot.IdentityId, workspaceSnapshot.WorkspaceId, workspaceSnapshot.Id, "json").Execute()
-			if err != nil {
-				return nil, err
-			}
+		response, err := getSnapshotContent(ctx, d, api, workspaceSnapshot.WorkspaceId, workspaceSnapshot.Id)
+		if err != nil {
+			return nil, err
 		}
 		snapshotData.Data = response
 		return nil, nil
 	}
 	_, err = plugin.RetryHydrate(ctx, d, h, getSnapshotData, &plugin.RetryConfig{})
+	if err != nil {
+		plugin.Logger(ctx).Error("getSnapshotData", "error", err)
+		return nil, err
+	}
 
 	return snapshotData, nil
 }
@@ -0,0 +1,193 @@
+package pipes
+
+import (
+	"context"
+	"net/http"
+
+	openapi "github.com/turbot/pipes-sdk-go"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+//// TABLE DEFINITION
+
+func tablePipesWorkspaceSnapshotControlResult(_ context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "pipes_workspace_snapshot_control_result",
+		Description: "Results of the controls run for workspace snapshots of benchmarks, one row per resource checked by a control.",
+		HydrateConfig: []plugin.HydrateConfig{
+			{
+				Func:           getIdentityWorkspaceDetailsForSnapshotContent,
+				MaxConcurrency: 5,
+			},
+		},
+		List: &plugin.ListConfig{
+			ParentHydrate: listWorkspaces,
+			Hydrate:       listWorkspaceSnapshotControlResults,
+			KeyColumns:    snapshotContentKeyColumns(),
+		},
+		Columns: commonColumns([]*plugin.Column{
+			{
+				Name:        "control_name",
+				Description: "The name of the control, e.g. 'aws_compliance.control.cis_v300_1_4'.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Control.Name"),
+			},
+			{
+				Name:        "control_title",
+				Description: "The title of the control.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Control.Title"),
+			},
+			{
+				Name:        "parent_name",
+				Description: "The name of the benchmark the control was run for.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Control.ParentName"),
+			},
+			{
+				Name:        "severity",
+				Description: "The severity of the control.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Control.Severity"),
+			},
+			{
+				Name:        "status",
+				Description: "The status of the result, i.e. 'ok', 'alarm', 'info', 'skip' or 'error'.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "reason",
+				Description: "The reason for the status of the result.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "resource",
+				Description: "The resource the result is for, e.g. its ARN.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "dimensions",
+				Description: "The dimensions of the result, e.g. the account and region of the resource.",
+				Type:        proto.ColumnType_JSON,
+			},
+			{
+				Name:        "control_tags",
+				Description: "The tags of the control.",
+				Type:        proto.ColumnType_JSON,
+				Transform:   transform.FromField("Control.Tags"),
+			},
+			{
+				Name:        "snapshot_id",
+				Description: "The unique identifier for the snapshot which contains the result.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Snapshot.Id"),
+			},
+			{
+				Name:        "dashboard_name",
+				Description: "The mod-prefixed name of the benchmark the snapshot belongs to.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Snapshot.DashboardName"),
+			},
+			{
+				Name:        "snapshot_created_at",
+				Description: "The time when the snapshot was created.",
+				Type:        proto.ColumnType_TIMESTAMP,
+				Transform:   transform.FromField("Snapshot.CreatedAt"),
+			},
+			{
+				Name:        "identity_id",
+				Description: "The unique identifier for the identity which contains the workspace.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Snapshot.IdentityId"),
+			},
+			{
+				Name:        "identity_handle",
+				Description: "The handle of the identity which contains the workspace.",
+				Type:        proto.ColumnType_STRING,
+				Hydrate:     getIdentityWorkspaceDetailsForSnapshotContent,
+			},
+			{
+				Name:        "identity_type",
+				Description: "The type of identity, which can be 'user' or 'org'.",
+				Type:        proto.ColumnType_STRING,
+				Hydrate:     getIdentityWorkspaceDetailsForSnapshotContent,
+			},
+			{
+				Name:        "workspace_id",
+				Description: "The unique identifier for the workspace.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Snapshot.WorkspaceId"),
+			},
+			{
+				Name:        "workspace_handle",
+				Description: "The handle for the workspace.",
+				Type:        proto.ColumnType_STRING,
+				Hydrate:     getIdentityWorkspaceDetailsForSnapshotContent,
+			},
+		}),
+	}
+}
+
+// WorkspaceSnapshotControlResult is a control result of a snapshot, along
+// with the snapshot.
+type WorkspaceSnapshotControlResult struct {
+	SnapshotControlResult
+	Snapshot openapi.WorkspaceSnapshot
+}
+
+//// LIST FUNCTION
+
+func listWorkspaceSnapshotControlResults(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	workspace := parentWorkspace(h.Item)
+	if workspace == nil {
+		plugin.Logger(ctx).Error("listWorkspaceSnapshotControlResults", "unknown response type for workspace list parent hydrate call", h.Item)
+		return nil, nil
+	}
+	if !workspaceMatchesQuals(d, workspace) {
+		return nil, nil
+	}
+
+	api, err := getIdentityService(ctx, d, h, workspace.IdentityId)
+	if err != nil {
+		plugin.Logger(ctx).Error("listWorkspaceSnapshotControlResults", "getIdentityService", err)
+		return nil, err
+	}
+
+	snapshots, err := listSnapshotsForContent(ctx, d, api, workspace)
+	if err != nil {
+		plugin.Logger(ctx).Error("listWorkspaceSnapshotControlResults", "list_snapshots", err)
+		return nil, err
+	}
+
+	for _, snapshot := range snapshots {
+		data, err := getSnapshotContent(ctx, d, api, workspace.Handle, snapshot.Id)
+		if err != nil {
+			// The snapshot may have been deleted since it was listed
+			if errorStatusCode(err) == http.StatusNotFound {
+				continue
+			}
+			plugin.Logger(ctx).Error("listWorkspaceSnapshotControlResults", "download", err, "snapshot", snapshot.Id)
+			return nil, err
+		}
+		panels, err := snapshotPanels(data)
+		if err != nil {
+			plugin.Logger(ctx).Error("listWorkspaceSnapshotControlResults", "parse", err, "snapshot", snapshot.Id)
+			return nil, err
+		}
+
+		for _, panel := range panels {
+			for _, result := range panel.controlResults() {
+				d.StreamListItem(ctx, WorkspaceSnapshotControlResult{SnapshotControlResult: result, Snapshot: snapshot})
+
+				// Context can be cancelled due to manual cancellation or the limit has been hit
+				if d.RowsRemaining(ctx) == 0 {
+					return nil, nil
+				}
+			}
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,172 @@
+package pipes
+
+import (
+	"context"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+//// TABLE DEFINITION
+
+func tablePipesWorkspaceSnapshotDiff(_ context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "pipes_workspace_snapshot_diff",
+		Description: "Differences between two workspace snapshots, i.e. the panels and control results that were added, removed or changed.",
+		List: &plugin.ListConfig{
+			Hydrate: listWorkspaceSnapshotDiff,
+			KeyColumns: []*plugin.KeyColumn{
+				{
+					Name:    "identity_handle",
+					Require: plugin.Required,
+				},
+				{
+					Name:    "workspace_handle",
+					Require: plugin.Required,
+				},
+				{
+					Name:    "from_snapshot_id",
+					Require: plugin.Required,
+				},
+				{
+					Name:    "to_snapshot_id",
+					Require: plugin.Required,
+				},
+			},
+		},
+		Columns: commonColumns([]*plugin.Column{
+			{
+				Name:        "item_type",
+				Description: "The type of item that differs, i.e. 'panel' or 'control_result'.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromCamel(),
+			},
+			{
+				Name:        "change",
+				Description: "How the item differs, i.e. 'added', 'removed' or 'changed'.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "panel_name",
+				Description: "The name of the panel, or of the control for a control result.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromCamel(),
+			},
+			{
+				Name:        "panel_type",
+				Description: "The type of the panel, e.g. 'benchmark', 'control', 'chart' or 'table'.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromCamel(),
+			},
+			{
+				Name:        "title",
+				Description: "The title of the panel, or of the control for a control result.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "resource",
+				Description: "The resource of a control result.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "from_status",
+				Description: "The status of the item in the from snapshot, e.g. 'ok'. Null if the item was added.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromCamel(),
+			},
+			{
+				Name:        "to_status",
+				Description: "The status of the item in the to snapshot, e.g. 'alarm'. Null if the item was removed.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromCamel(),
+			},
+			{
+				Name:        "from_reason",
+				Description: "The reason for the status of a control result in the from snapshot.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromCamel(),
+			},
+			{
+				Name:        "to_reason",
+				Description: "The reason for the status of a control result in the to snapshot.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromCamel(),
+			},
+			{
+				Name:        "dimensions",
+				Description: "The dimensions of a control result, e.g. the account and region of the resource.",
+				Type:        proto.ColumnType_JSON,
+			},
+			{
+				Name:        "changed_properties",
+				Description: "The properties of a changed panel which differ, e.g. 'status', 'summary' or 'data'.",
+				Type:        proto.ColumnType_JSON,
+				Transform:   transform.FromCamel(),
+			},
+			{
+				Name:        "from_snapshot_id",
+				Description: "The unique identifier for the snapshot to compare from, e.g. last week's snapshot.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromQual("from_snapshot_id"),
+			},
+			{
+				Name:        "to_snapshot_id",
+				Description: "The unique identifier for the snapshot to compare to, e.g. this week's snapshot.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromQual("to_snapshot_id"),
+			},
+			{
+				Name:        "identity_handle",
+				Description: "The handle of the identity which contains the workspace.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromQual("identity_handle"),
+			},
+			{
+				Name:        "workspace_handle",
+				Description: "The handle of the workspace which contains the snapshots.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromQual("workspace_handle"),
+			},
+		}),
+	}
+}
+
+//// LIST FUNCTION
+
+func listWorkspaceSnapshotDiff(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	identityHandle := d.EqualsQualString("identity_handle")
+	workspaceHandle := d.EqualsQualString("workspace_handle")
+	fromSnapshotId := d.EqualsQualString("from_snapshot_id")
+	toSnapshotId := d.EqualsQualString("to_snapshot_id")
+
+	api, err := getIdentityService(ctx, d, h, identityHandle)
+	if err != nil {
+		plugin.Logger(ctx).Error("listWorkspaceSnapshotDiff", "getIdentityService", err)
+		return nil, err
+	}
+
+	var panels [2][]*SnapshotPanel
+	for i, snapshotId := range []string{fromSnapshotId, toSnapshotId} {
+		data, err := getSnapshotContent(ctx, d, api, workspaceHandle, snapshotId)
+		if err != nil {
+			plugin.Logger(ctx).Error("listWorkspaceSnapshotDiff", "download", err, "snapshot", snapshotId)
+			return nil, err
+		}
+		panels[i], err = snapshotPanels(data)
+		if err != nil {
+			plugin.Logger(ctx).Error("listWorkspaceSnapshotDiff", "parse", err, "snapshot", snapshotId)
+			return nil, err
+		}
+	}
+
+	for _, diff := range diffSnapshotPanels(panels[0], panels[1]) {
+		d.StreamListItem(ctx, diff)
+
+		// Context can be cancelled due to manual cancellation or the limit has been hit
+		if d.RowsRemaining(ctx) == 0 {
+			return nil, nil
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,199 @@
+package pipes
+
+import (
+	"context"
+	"net/http"
+
+	openapi "github.com/turbot/pipes-sdk-go"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+// defaultSnapshotDownloadFormat is the format snapshots are downloaded in if
+// there is no format qual.
+const defaultSnapshotDownloadFormat = "json"
+
+//// TABLE DEFINITION
+
+func tablePipesWorkspaceSnapshotDownload(_ context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "pipes_workspace_snapshot_download",
+		Description: "Workspace snapshots downloaded as files, e.g. as HTML or CSV, optionally written to the snapshot_export_dir configured for the connection.",
+		HydrateConfig: []plugin.HydrateConfig{
+			{
+				Func:           getIdentityWorkspaceDetailsForSnapshotContent,
+				MaxConcurrency: 5,
+			},
+		},
+		List: &plugin.ListConfig{
+			ParentHydrate: listQualWorkspaces,
+			Hydrate:       listWorkspaceSnapshotDownloads,
+			KeyColumns: append(snapshotContentKeyColumns(), &plugin.KeyColumn{
+				Name:    "format",
+				Require: plugin.Optional,
+			}),
+		},
+		Columns: commonColumns([]*plugin.Column{
+			{
+				Name:        "snapshot_id",
+				Description: "The unique identifier for the snapshot.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Snapshot.Id"),
+			},
+			{
+				Name:        "format",
+				Description: "The format the snapshot was downloaded in, i.e. 'json', 'html', 'md', 'csv' or 'asff'. Defaults to 'json'.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "content",
+				Description: "The content of the downloaded snapshot file.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "size",
+				Description: "The size of the downloaded snapshot file in bytes.",
+				Type:        proto.ColumnType_INT,
+			},
+			{
+				Name:        "export_path",
+				Description: "The path the snapshot was written to, if snapshot_export_dir is set in the connection config.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromCamel(),
+			},
+			{
+				Name:        "dashboard_name",
+				Description: "The mod-prefixed name of the dashboard the snapshot belongs to.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Snapshot.DashboardName"),
+			},
+			{
+				Name:        "dashboard_title",
+				Description: "The title of the dashboard the snapshot belongs to.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Snapshot.DashboardTitle"),
+			},
+			{
+				Name:        "snapshot_created_at",
+				Description: "The time when the snapshot was created.",
+				Type:        proto.ColumnType_TIMESTAMP,
+				Transform:   transform.FromField("Snapshot.CreatedAt"),
+			},
+			{
+				Name:        "identity_id",
+				Description: "The unique identifier for the identity which contains the workspace.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Snapshot.IdentityId"),
+			},
+			{
+				Name:        "identity_handle",
+				Description: "The handle of the identity which contains the workspace.",
+				Type:        proto.ColumnType_STRING,
+				Hydrate:     getIdentityWorkspaceDetailsForSnapshotContent,
+			},
+			{
+				Name:        "identity_type",
+				Description: "The type of identity, which can be 'user' or 'org'.",
+				Type:        proto.ColumnType_STRING,
+				Hydrate:     getIdentityWorkspaceDetailsForSnapshotContent,
+			},
+			{
+				Name:        "workspace_id",
+				Description: "The unique identifier for the workspace.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Snapshot.WorkspaceId"),
+			},
+			{
+				Name:        "workspace_handle",
+				Description: "The handle for the workspace.",
+				Type:        proto.ColumnType_STRING,
+				Hydrate:     getIdentityWorkspaceDetailsForSnapshotContent,
+			},
+		}),
+	}
+}
+
+// WorkspaceSnapshotDownload is a snapshot downloaded in a format, along with
+// the snapshot.
+type WorkspaceSnapshotDownload struct {
+	Snapshot   openapi.WorkspaceSnapshot
+	Format     string
+	Content    string
+	Size       int
+	ExportPath *string
+}
+
+//// LIST FUNCTION
+
+func listWorkspaceSnapshotDownloads(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	workspace := parentWorkspace(h.Item)
+	if workspace == nil {
+		plugin.Logger(ctx).Error("listWorkspaceSnapshotDownloads", "unknown response type for workspace list parent hydrate call", h.Item)
+		return nil, nil
+	}
+	if !workspaceMatchesQuals(d, workspace) {
+		return nil, nil
+	}
+
+	format := defaultSnapshotDownloadFormat
+	if d.EqualsQualString("format") != "" {
+		format = d.EqualsQualString("format")
+	}
+	if err := validateSnapshotDownloadFormat(format); err != nil {
+		return nil, err
+	}
+
+	var exportDir string
+	if config := GetConfig(d.Connection); config.SnapshotExportDir != nil && *config.SnapshotExportDir != "" {
+		dir, err := expandHomeDir(*config.SnapshotExportDir)
+		if err != nil {
+			plugin.Logger(ctx).Error("listWorkspaceSnapshotDownloads", "snapshot_export_dir", err)
+			return nil, err
+		}
+		exportDir = dir
+	}
+
+	api, err := getIdentityService(ctx, d, h, workspace.IdentityId)
+	if err != nil {
+		plugin.Logger(ctx).Error("listWorkspaceSnapshotDownloads", "getIdentityService", err)
+		return nil, err
+	}
+
+	snapshots, err := listSnapshotsForContent(ctx, d, api, workspace)
+	if err != nil {
+		plugin.Logger(ctx).Error("listWorkspaceSnapshotDownloads", "list_snapshots", err)
+		return nil, err
+	}
+
+	for _, snapshot := range snapshots {
+		content, err := api.DownloadWorkspaceSnapshotFile(ctx, workspace.Handle, snapshot.Id, format)
+		if err != nil {
+			// The snapshot may have been deleted since it was listed
+			if errorStatusCode(err) == http.StatusNotFound {
+				continue
+			}
+			plugin.Logger(ctx).Error("listWorkspaceSnapshotDownloads", "download", err, "snapshot", snapshot.Id, "format", format)
+			return nil, err
+		}
+
+		download := WorkspaceSnapshotDownload{Snapshot: snapshot, Format: format, Content: string(content), Size: len(content)}
+		if exportDir != "" {
+			path := snapshotExportPath(exportDir, api.Identity().Handle, workspace.Handle, snapshot.Id, format)
+			if err := writeSnapshotFile(path, content); err != nil {
+				plugin.Logger(ctx).Error("listWorkspaceSnapshotDownloads", "export", err, "path", path)
+				return nil, err
+			}
+			download.ExportPath = &path
+		}
+
+		d.StreamListItem(ctx, download)
+
+		// Context can be cancelled due to manual cancellation or the limit has been hit
+		if d.RowsRemaining(ctx) == 0 {
+			return nil, nil
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,208 @@
+package pipes
+
+import (
+	"context"
+	"net/http"
+
+	openapi "github.com/turbot/pipes-sdk-go"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+//// TABLE DEFINITION
+
+func tablePipesWorkspaceSnapshotPanel(_ context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "pipes_workspace_snapshot_panel",
+		Description: "Panels of workspace snapshots, e.g. the benchmarks, controls, charts and tables of the dashboard run captured by the snapshot.",
+		HydrateConfig: []plugin.HydrateConfig{
+			{
+				Func:           getIdentityWorkspaceDetailsForSnapshotContent,
+				MaxConcurrency: 5,
+			},
+		},
+		List: &plugin.ListConfig{
+			ParentHydrate: listWorkspaces,
+			Hydrate:       listWorkspaceSnapshotPanels,
+			KeyColumns:    snapshotContentKeyColumns(),
+		},
+		Columns: commonColumns([]*plugin.Column{
+			{
+				Name:        "name",
+				Description: "The name of the panel, e.g. 'aws_compliance.control.cis_v300_1_4'.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "panel_type",
+				Description: "The type of the panel, e.g. 'benchmark', 'control', 'chart' or 'table'.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromCamel(),
+			},
+			{
+				Name:        "display_type",
+				Description: "The display type of the panel, e.g. 'bar' for a chart.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromCamel(),
+			},
+			{
+				Name:        "title",
+				Description: "The title of the panel.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "description",
+				Description: "The description of the panel.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "parent_name",
+				Description: "The name of the panel this panel is nested in, e.g. the benchmark of a control.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromCamel(),
+			},
+			{
+				Name:        "status",
+				Description: "The status of the panel run, e.g. 'complete' or 'error'.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "error",
+				Description: "The error the panel run failed with, if any.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "severity",
+				Description: "The severity of a control panel.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "summary",
+				Description: "The summary of the results of a control or benchmark panel, i.e. the number of results with each status.",
+				Type:        proto.ColumnType_JSON,
+			},
+			{
+				Name:        "tags",
+				Description: "The tags of the panel.",
+				Type:        proto.ColumnType_JSON,
+			},
+			{
+				Name:        "properties",
+				Description: "The properties of the panel, which depend on the panel type.",
+				Type:        proto.ColumnType_JSON,
+			},
+			{
+				Name:        "data",
+				Description: "The result of the panel's query, as the columns and rows it returned.",
+				Type:        proto.ColumnType_JSON,
+			},
+			{
+				Name:        "snapshot_id",
+				Description: "The unique identifier for the snapshot which contains the panel.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Snapshot.Id"),
+			},
+			{
+				Name:        "dashboard_name",
+				Description: "The mod-prefixed name of the dashboard the snapshot belongs to.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Snapshot.DashboardName"),
+			},
+			{
+				Name:        "snapshot_created_at",
+				Description: "The time when the snapshot was created.",
+				Type:        proto.ColumnType_TIMESTAMP,
+				Transform:   transform.FromField("Snapshot.CreatedAt"),
+			},
+			{
+				Name:        "identity_id",
+				Description: "The unique identifier for the identity which contains the workspace.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Snapshot.IdentityId"),
+			},
+			{
+				Name:        "identity_handle",
+				Description: "The handle of the identity which contains the workspace.",
+				Type:        proto.ColumnType_STRING,
+				Hydrate:     getIdentityWorkspaceDetailsForSnapshotContent,
+			},
+			{
+				Name:        "identity_type",
+				Description: "The type of identity, which can be 'user' or 'org'.",
+				Type:        proto.ColumnType_STRING,
+				Hydrate:     getIdentityWorkspaceDetailsForSnapshotContent,
+			},
+			{
+				Name:        "workspace_id",
+				Description: "The unique identifier for the workspace.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Snapshot.WorkspaceId"),
+			},
+			{
+				Name:        "workspace_handle",
+				Description: "The handle for the workspace.",
+				Type:        proto.ColumnType_STRING,
+				Hydrate:     getIdentityWorkspaceDetailsForSnapshotContent,
+			},
+		}),
+	}
+}
+
+// WorkspaceSnapshotPanel is a panel of a snapshot, along with the snapshot.
+type WorkspaceSnapshotPanel struct {
+	SnapshotPanel
+	Snapshot openapi.WorkspaceSnapshot
+}
+
+//// LIST FUNCTION
+
+func listWorkspaceSnapshotPanels(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	workspace := parentWorkspace(h.Item)
+	if workspace == nil {
+		plugin.Logger(ctx).Error("listWorkspaceSnapshotPanels", "unknown response type for workspace list parent hydrate call", h.Item)
+		return nil, nil
+	}
+	if !workspaceMatchesQuals(d, workspace) {
+		return nil, nil
+	}
+
+	api, err := getIdentityService(ctx, d, h, workspace.IdentityId)
+	if err != nil {
+		plugin.Logger(ctx).Error("listWorkspaceSnapshotPanels", "getIdentityService", err)
+		return nil, err
+	}
+
+	snapshots, err := listSnapshotsForContent(ctx, d, api, workspace)
+	if err != nil {
+		plugin.Logger(ctx).Error("listWorkspaceSnapshotPanels", "list_snapshots", err)
+		return nil, err
+	}
+
+	for _, snapshot := range snapshots {
+		data, err := getSnapshotContent(ctx, d, api, workspace.Handle, snapshot.Id)
+		if err != nil {
+			// The snapshot may have been deleted since it was listed
+			if errorStatusCode(err) == http.StatusNotFound {
+				continue
+			}
+			plugin.Logger(ctx).Error("listWorkspaceSnapshotPanels", "download", err, "snapshot", snapshot.Id)
+			return nil, err
+		}
+		panels, err := snapshotPanels(data)
+		if err != nil {
+			plugin.Logger(ctx).Error("listWorkspaceSnapshotPanels", "parse", err, "snapshot", snapshot.Id)
+			return nil, err
+		}
+
+		for _, panel := range panels {
+			d.StreamListItem(ctx, WorkspaceSnapshotPanel{SnapshotPanel: *panel, Snapshot: snapshot})
+
+			// Context can be cancelled due to manual cancellation or the limit has been hit
+			if d.RowsRemaining(ctx) == 0 {
+				return nil, nil
+			}
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,139 @@
+package pipes
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// defaultHostname is the Turbot Pipes host used when no host is configured.
+const defaultHostname = "pipes.turbot.com"
+
+// loginTokenExtensions are the extensions of the token files saved by
+// `steampipe login` and `powerpipe login`, newest first. Older Steampipe
+// versions saved tokens with the .sptt extension.
+var loginTokenExtensions = []string{".tptt", ".sptt"}
+
+// resolveToken returns the API token for the connection. The token is taken
+// from, in order of precedence:
+//
+//   - the `token` config argument
+//   - the file named by the `token_file` config argument
+//   - the STEAMPIPE_CLOUD_TOKEN or PIPES_TOKEN environment variables
+//   - the token saved for the host by `steampipe login` or `powerpipe login`
+//
+// An empty token is returned if none of these are set.
+func resolveToken(config pipesConfig, hostname string) (string, error) {
+	if config.Token != nil {
+		return *config.Token, nil
+	}
+
+	if config.TokenFile != nil {
+		token, err := readTokenFile(*config.TokenFile)
+		if err != nil {
+			return "", fmt.Errorf("failed to read token_file: %w", err)
+		}
+		if token == "" {
+			return "", fmt.Errorf("token_file %s is empty", *config.TokenFile)
+		}
+		return token, nil
+	}
+
+	// If `STEAMPIPE_CLOUD_TOKEN` is not set - we try to get the token from `PIPES_TOKEN`
+	if token := os.Getenv("STEAMPIPE_CLOUD_TOKEN"); token != "" {
+		return token, nil
+	}
+	if token := os.Getenv("PIPES_TOKEN"); token != "" {
+		return token, nil
+	}
+
+	return loginToken(hostname)
+}
+
+// tokenFileModTime returns the modification time of token_file, or the zero
+// time if it is not set or can't be read. A change means the API client must
+// be recreated to pick up the new token.
+func tokenFileModTime(config pipesConfig) time.Time {
+	if config.TokenFile == nil {
+		return time.Time{}
+	}
+	path, err := expandHomeDir(*config.TokenFile)
+	if err != nil {
+		return time.Time{}
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
+
+// loginToken returns the token saved for the host by `steampipe login` or
+// `powerpipe login`, or an empty string if neither has logged in to it. The
+// CLIs save the token in <install dir>/internal/<host>.tptt.
+func loginToken(hostname string) (string, error) {
+	for _, installDir := range loginInstallDirs() {
+		for _, extension := range loginTokenExtensions {
+			token, err := readTokenFile(filepath.Join(installDir, "internal", hostname+extension))
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			if err != nil {
+				return "", err
+			}
+			if token != "" {
+				return token, nil
+			}
+		}
+	}
+	return "", nil
+}
+
+// loginInstallDirs returns the Steampipe and Powerpipe install directories,
+// honoring STEAMPIPE_INSTALL_DIR and POWERPIPE_INSTALL_DIR.
+func loginInstallDirs() []string {
+	var dirs []string
+	for _, cli := range []struct{ envVar, defaultDir string }{
+		{"STEAMPIPE_INSTALL_DIR", ".steampipe"},
+		{"POWERPIPE_INSTALL_DIR", ".powerpipe"},
+	} {
+		if dir := os.Getenv(cli.envVar); dir != "" {
+			dirs = append(dirs, dir)
+			continue
+		}
+		if home, err := os.UserHomeDir(); err == nil {
+			dirs = append(dirs, filepath.Join(home, cli.defaultDir))
+		}
+	}
+	return dirs
+}
+
+// readTokenFile returns the trimmed contents of a token file. A leading ~ in
+// the path is expanded to the user's home directory.
+func readTokenFile(path string) (string, error) {
+	path, err := expandHomeDir(path)
+	if err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// expandHomeDir expands a leading ~ in the path to the user's home directory.
+func expandHomeDir(path string) (string, error) {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		path = filepath.Join(home, path[1:])
+	}
+	return path, nil
+}
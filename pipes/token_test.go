@@ -0,0 +1,144 @@
+package pipes
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestResolveToken(t *testing.T) {
+	steampipeDir := t.TempDir()
+	powerpipeDir := t.TempDir()
+	writeFile(t, filepath.Join(steampipeDir, "internal", "pipes.turbot.com.tptt"), "tpt_steampipe_login\n")
+	writeFile(t, filepath.Join(steampipeDir, "internal", "pipes.acme.com.sptt"), "spt_legacy_login")
+	writeFile(t, filepath.Join(powerpipeDir, "internal", "pipes.example.com:8443.tptt"), "tpt_powerpipe_login")
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	writeFile(t, tokenFile, "  tpt_token_file \n")
+	emptyTokenFile := filepath.Join(t.TempDir(), "empty")
+	writeFile(t, emptyTokenFile, "\n")
+	missingTokenFile := filepath.Join(t.TempDir(), "missing")
+
+	configToken := "tpt_config"
+
+	tests := []struct {
+		name     string
+		config   pipesConfig
+		env      map[string]string
+		hostname string
+		want     string
+		wantErr  bool
+	}{
+		{
+			name:     "config token",
+			config:   pipesConfig{Token: &configToken, TokenFile: &tokenFile},
+			env:      map[string]string{"PIPES_TOKEN": "tpt_env"},
+			hostname: "pipes.turbot.com",
+			want:     "tpt_config",
+		},
+		{
+			name:     "token file",
+			config:   pipesConfig{TokenFile: &tokenFile},
+			env:      map[string]string{"PIPES_TOKEN": "tpt_env"},
+			hostname: "pipes.turbot.com",
+			want:     "tpt_token_file",
+		},
+		{
+			name:     "missing token file",
+			config:   pipesConfig{TokenFile: &missingTokenFile},
+			hostname: "pipes.turbot.com",
+			wantErr:  true,
+		},
+		{
+			name:     "empty token file",
+			config:   pipesConfig{TokenFile: &emptyTokenFile},
+			hostname: "pipes.turbot.com",
+			wantErr:  true,
+		},
+		{
+			name:     "steampipe env var",
+			env:      map[string]string{"STEAMPIPE_CLOUD_TOKEN": "tpt_steampipe_env", "PIPES_TOKEN": "tpt_env"},
+			hostname: "pipes.turbot.com",
+			want:     "tpt_steampipe_env",
+		},
+		{
+			name:     "pipes env var",
+			env:      map[string]string{"PIPES_TOKEN": "tpt_env"},
+			hostname: "pipes.turbot.com",
+			want:     "tpt_env",
+		},
+		{
+			name:     "steampipe login",
+			hostname: "pipes.turbot.com",
+			want:     "tpt_steampipe_login",
+		},
+		{
+			name:     "legacy steampipe login",
+			hostname: "pipes.acme.com",
+			want:     "spt_legacy_login",
+		},
+		{
+			name:     "powerpipe login",
+			hostname: "pipes.example.com:8443",
+			want:     "tpt_powerpipe_login",
+		},
+		{
+			name:     "not logged in to host",
+			hostname: "pipes.other.com",
+			want:     "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("STEAMPIPE_INSTALL_DIR", steampipeDir)
+			t.Setenv("POWERPIPE_INSTALL_DIR", powerpipeDir)
+			t.Setenv("STEAMPIPE_CLOUD_TOKEN", "")
+			t.Setenv("PIPES_TOKEN", "")
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			got, err := resolveToken(tt.config, tt.hostname)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("token = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTokenFileModTime(t *testing.T) {
+	if modTime := tokenFileModTime(pipesConfig{}); !modTime.IsZero() {
+		t.Errorf("mod time without token_file = %v, want zero", modTime)
+	}
+	missingTokenFile := filepath.Join(t.TempDir(), "missing")
+	if modTime := tokenFileModTime(pipesConfig{TokenFile: &missingTokenFile}); !modTime.IsZero() {
+		t.Errorf("mod time of missing token_file = %v, want zero", modTime)
+	}
+
+	// Rotating the token changes the mod time, so the API client is recreated
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	writeFile(t, tokenFile, "tpt_old")
+	config := pipesConfig{TokenFile: &tokenFile}
+	before := tokenFileModTime(config)
+	rotatedAt := before.Add(time.Minute)
+	writeFile(t, tokenFile, "tpt_new")
+	if err := os.Chtimes(tokenFile, rotatedAt, rotatedAt); err != nil {
+		t.Fatal(err)
+	}
+	if after := tokenFileModTime(config); !after.Equal(rotatedAt) {
+		t.Errorf("mod time after rotation = %v, want %v", after, rotatedAt)
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,157 @@
+package pipes
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+)
+
+func TestUsage(t *testing.T) {
+	api := newFakePipesAPI(t, "pipes_api.json")
+	p := newTestPlugin(t, api)
+
+	rows := p.MustQuery(testQuery{
+		Table:   "pipes_usage",
+		Columns: []string{"metric", "value", "identity_id", "identity_handle", "workspace_id"},
+	})
+
+	// the usage of the connection user and each of their orgs
+	want := []string{"db_execution_ms", "snapshot_storage_bytes", "user_count"}
+	if got := rowStrings(rows, "metric"); !reflect.DeepEqual(got, want) {
+		t.Fatalf("metrics = %v, want %v", got, want)
+	}
+	for _, row := range rows {
+		metric := row.Columns["metric"].GetStringValue()
+		wantIdentity := map[string]string{"user_count": "o_acme/acme"}[metric]
+		if wantIdentity == "" {
+			wantIdentity = "u_alice/alice"
+		}
+		// records without an identity are attributed to the identity queried
+		if got := row.Columns["identity_id"].GetStringValue() + "/" + row.Columns["identity_handle"].GetStringValue(); got != wantIdentity {
+			t.Errorf("%s identity_id/identity_handle = %s, want %s", metric, got, wantIdentity)
+		}
+		if metric == "db_execution_ms" && row.Columns["value"].GetIntValue() != 60000 {
+			t.Errorf("db_execution_ms value = %d, want 60000", row.Columns["value"].GetIntValue())
+		}
+	}
+}
+
+func TestUsageFilter(t *testing.T) {
+	api := newFakePipesAPI(t, "pipes_api.json")
+	p := newTestPlugin(t, api)
+
+	start := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	p.MustQuery(testQuery{
+		Table:   "pipes_usage",
+		Columns: []string{"metric"},
+		Quals: []*proto.Qual{
+			qual("identity_handle", "=", stringQualValue("acme")),
+			qual("usage_date", ">=", timestampQualValue(start)),
+			qual("usage_date", "<", timestampQualValue(start.AddDate(0, 1, 0))),
+			qual("dimension", "=", stringQualValue("compute")),
+		},
+	})
+
+	var got []string
+	for _, r := range api.Requests("GET /org/acme/usage") {
+		got = append(got, r.Query.Get("where"))
+	}
+	want := []string{`dimension = 'compute' and usage_date >= '2024-02-01 00:00:00.00000' and usage_date < '2024-03-01 00:00:00.00000'`}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("where = %q, want %q", got, want)
+	}
+	if got := len(api.Requests("GET /user/alice/usage")); got != 0 {
+		t.Errorf("user usage requests = %d, want 0", got)
+	}
+}
+
+func TestUsageWorkspace(t *testing.T) {
+	api := newFakePipesAPI(t, "pipes_api.json")
+	p := newTestPlugin(t, api)
+
+	rows := p.MustQuery(testQuery{
+		Table:   "pipes_usage",
+		Columns: []string{"metric", "value_weighted", "identity_handle", "workspace_id", "workspace_handle"},
+		Quals:   []*proto.Qual{qual("workspace_handle", "=", stringQualValue("prod"))},
+	})
+
+	// the workspace is only found in the org, the user's not found error is skipped
+	if len(rows) != 1 {
+		t.Fatalf("rows = %d, want 1", len(rows))
+	}
+	row := rows[0].Columns
+	if got := row["identity_handle"].GetStringValue() + "/" + row["workspace_id"].GetStringValue() + "/" + row["workspace_handle"].GetStringValue(); got != "acme/w_prod/prod" {
+		t.Errorf("identity_handle/workspace_id/workspace_handle = %s, want acme/w_prod/prod", got)
+	}
+	if got := row["value_weighted"].GetIntValue(); got != 240000 {
+		t.Errorf("value_weighted = %d, want 240000", got)
+	}
+	if got := len(api.Requests("GET /org/acme/usage")); got != 0 {
+		t.Errorf("org usage requests = %d, want 0", got)
+	}
+}
+
+func TestUsageForbiddenOrg(t *testing.T) {
+	api := newFakePipesAPI(t, "pipes_api.json")
+	api.Fail("GET /org/acme/usage", http.StatusForbidden)
+	p := newTestPlugin(t, api)
+
+	rows, err := p.Query(testQuery{
+		Table:   "pipes_usage",
+		Columns: []string{"metric", "identity_handle"},
+	})
+	if err != nil {
+		t.Fatalf("error = %v, want nil", err)
+	}
+
+	// the org the connection user can't read is skipped
+	want := []string{"db_execution_ms", "snapshot_storage_bytes"}
+	if got := rowStrings(rows, "metric"); !reflect.DeepEqual(got, want) {
+		t.Errorf("metrics = %v, want %v", got, want)
+	}
+	if got := rowStrings(rows, "identity_handle"); !reflect.DeepEqual(got, []string{"alice", "alice"}) {
+		t.Errorf("identity_handle = %v, want [alice alice]", got)
+	}
+
+	// an org asked for by the quals must be readable
+	api.Fail("GET /org/acme/usage", http.StatusForbidden)
+	_, err = p.Query(testQuery{
+		Table:   "pipes_usage",
+		Columns: []string{"metric"},
+		Quals:   []*proto.Qual{qual("identity_handle", "=", stringQualValue("acme"))},
+	})
+	if err == nil {
+		t.Error("error = nil, want forbidden error")
+	}
+}
+
+func TestBillingSubscription(t *testing.T) {
+	api := newFakePipesAPI(t, "pipes_api.json")
+	p := newTestPlugin(t, api)
+
+	rows := p.MustQuery(testQuery{
+		Table:   "pipes_billing_subscription",
+		Columns: []string{"id", "status", "identity_handle", "current_period_end", "cancel_at", "cancel_at_period_end", "items"},
+	})
+
+	if got := rowStrings(rows, "id"); !reflect.DeepEqual(got, []string{"sub_1"}) {
+		t.Fatalf("ids = %v, want [sub_1]", got)
+	}
+	row := rows[0]
+	if got := row.Columns["identity_handle"].GetStringValue(); got != "acme" {
+		t.Errorf("identity_handle = %q, want acme", got)
+	}
+	if got := row.Columns["current_period_end"].GetTimestampValue().AsTime().Format("2006-01-02"); got != "2024-03-01" {
+		t.Errorf("current_period_end = %s, want 2024-03-01", got)
+	}
+	// unset times are null rather than the zero time
+	if got := row.Columns["cancel_at"].GetTimestampValue(); got != nil {
+		t.Errorf("cancel_at = %v, want null", got)
+	}
+	if got := row.Columns["cancel_at_period_end"].GetBoolValue(); got {
+		t.Errorf("cancel_at_period_end = %v, want false", got)
+	}
+}
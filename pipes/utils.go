@@ -11,7 +11,7 @@ import (
 )
 
 func commonColumns(c []*plugin.Column) []*plugin.Column {
-	return append([]*plugin.Column{
+	return connectionColumns(append([]*plugin.Column{
 		{
 			Name:        "user_id",
 			Hydrate:     getUserIdentity,
@@ -19,7 +19,44 @@ func commonColumns(c []*plugin.Column) []*plugin.Column {
 			Description: "The unique identifier for the user.",
 			Transform:   transform.FromField("Id"),
 		},
-	}, c...)
+	}, c...))
+}
+
+// connectionColumns adds the columns that identify the Pipes host and tenant
+// of the connection, so rows from connections to different hosts can be told
+// apart in an aggregator. Tables with their own user_id column use this
+// instead of commonColumns.
+func connectionColumns(c []*plugin.Column) []*plugin.Column {
+	return append(c, []*plugin.Column{
+		{
+			Name:        "pipes_host",
+			Hydrate:     getPipesHost,
+			Type:        proto.ColumnType_STRING,
+			Description: "The Turbot Pipes host queried by the connection, e.g. pipes.turbot.com.",
+			Transform:   transform.FromValue(),
+		},
+		{
+			Name:        "pipes_tenant_id",
+			Hydrate:     getUserIdentity,
+			Type:        proto.ColumnType_STRING,
+			Description: "The unique identifier of the tenant the connection user belongs to on the Turbot Pipes host.",
+			Transform:   transform.FromField("TenantId"),
+		},
+	}...)
+}
+
+// getPipesHost returns the hostname of the Pipes API queried by the
+// connection. It is also the hydrate for the pipes_host connection key column,
+// so aggregators can limit a query to the connections for one host without
+// calling the API.
+func getPipesHost(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (any, error) {
+	hostname, err := resolveHostname(GetConfig(d.Connection))
+	if err != nil {
+		plugin.Logger(ctx).Error("getPipesHost", "host_error", err)
+		return nil, err
+	}
+
+	return hostname, nil
 }
 
 func getUserIdForConnection(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (any, error) {
@@ -60,7 +97,7 @@ func getUserIdentityUncached(ctx context.Context, d *plugin.QueryData, h *plugin
 
 	getDetails := func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
 		resp, _, err = svc.Actors.Get(ctx).Execute()
-		return resp, err
+		return resp, classifyError(err)
 	}
 
 	response, err := plugin.RetryHydrate(ctx, d, h, getDetails, &plugin.RetryConfig{})